@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// accessible switches every command to plain, linear output: no ANSI colors,
+// no box drawing or spinners, and no redrawing of earlier lines. Anything
+// that updates in place must print each update as a new line instead.
+var accessible bool
+
+// noColor is --no-color. A non-empty NO_COLOR environment variable does the
+// same, see https://no-color.org.
+var noColor bool
+
+// useColor reports whether ANSI colors may be written to f.
+func useColor(f *os.File) bool {
+	return !accessible && !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(f)
+}
+
+// paint wraps s in an SGR code. Codes are always two digits, so colored
+// cells in a tabwriter column carry the same number of invisible bytes and
+// stay aligned; pad a header cell with paint(h, "39", color) to match.
+func paint(s, code string, color bool) string {
+	if !color {
+		return s
+	}
+	return "\033[" + code + "m" + s + "\033[0m"
+}
+
+// dim renders secondary details such as the source and latency faintly.
+func dim(s string, color bool) string {
+	return paint(s, "02", color)
+}
+
+// paintHeader pads the given cells of a tabwriter header row with a no-op
+// color, so they line up with the painted cells below them.
+func paintHeader(header string, color bool, columns ...int) string {
+	if !color {
+		return header
+	}
+	cells := strings.Split(header, "\t")
+	for _, i := range columns {
+		if i < len(cells) {
+			cells[i] = paint(cells[i], "39", color)
+		}
+	}
+	return strings.Join(cells, "\t")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// AggregateResult adds a confidence grade to the combined provider prices.
+type AggregateResult struct {
+	pricefeed.Aggregation
+	Confidence string `json:"confidence"`
+}
+
+func aggregatePrices(results []pricefeed.Result, maxDeviation float64, volumeWeighted bool) AggregateResult {
+	agg := AggregateResult{Aggregation: pricefeed.Aggregate(results, maxDeviation, volumeWeighted)}
+	if agg.Median > 0 {
+		agg.Confidence = confidence(len(agg.Sources)+len(agg.Excluded), agg.Spread)
+	}
+	return agg
+}
+
+// confidence grades how much the sources agree: a single source or a spread
+// above the divergence threshold is low, half the threshold or two sources
+// is medium, anything tighter is high.
+func confidence(sources int, spreadPct float64) string {
+	switch {
+	case sources < 2 || spreadPct > divergenceThreshold:
+		return "low"
+	case sources == 2 || spreadPct > divergenceThreshold/2:
+		return "medium"
+	default:
+		return "high"
+	}
+}
+
+func warnOnDivergence(agg AggregateResult) {
+	if agg.Spread > divergenceThreshold {
+		fmt.Fprint(os.Stderr, tr("DivergenceWarning", "Warning: sources disagree by %.2f%% (threshold %.2f%%), confidence is %s\n", agg.Spread, divergenceThreshold, agg.Confidence))
+	}
+}
+
+func printAggregateDetails(agg AggregateResult, currency string) {
+	fmt.Print(tr("AggregateSummary", "  Median: %s, Spread: %.2f%%, Confidence: %s\n", formatPrice(agg.Median, currency), agg.Spread, agg.Confidence))
+	for _, r := range agg.Sources {
+		if r.Volume > 0 {
+			fmt.Print(tr("SourceWithVolume", "  %s: %s (Volume: %.0f, Duration: %s%s)\n", r.Source, formatPrice(r.Price, currency), r.Volume, r.Duration, ageSuffix(r)))
+		} else {
+			fmt.Print(tr("SourceLine", "  %s: %s (Duration: %s%s)\n", r.Source, formatPrice(r.Price, currency), r.Duration, ageSuffix(r)))
+		}
+	}
+	for _, r := range agg.Excluded {
+		fmt.Print(tr("ExcludedSource", "  %s: %s excluded (%.2f%% from median)\n", r.Source, formatPrice(r.Price, currency), pricefeed.Deviation(r.Price, agg.Median)))
+	}
+	for _, r := range agg.Stale {
+		fmt.Print(tr("RejectedStale", "  %s: %s rejected as stale (Age: %s)\n", r.Source, formatPrice(r.Price, currency), r.Age()))
+	}
+}
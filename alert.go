@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	alertAbove    float64
+	alertBelow    float64
+	alertInterval time.Duration
+	alertCurrency string
+	alertNotify   bool
+	alertMoves    string
+	alertWindow   time.Duration
+
+	alertSend         []string
+	alertWebhook      string
+	alertSlackWebhook string
+	alertTelegramChat string
+)
+
+type AlertEvent struct {
+	Coin      string    `json:"coin"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+	Condition string    `json:"condition"`
+	Threshold float64   `json:"threshold"`
+	Provider  string    `json:"provider"`
+	Time      time.Time `json:"time"`
+
+	// Change and Window are set for --moves alerts, where Threshold is
+	// the price the move is measured from.
+	Change float64 `json:"change_pct,omitempty"`
+	Window string  `json:"window,omitempty"`
+}
+
+func (e AlertEvent) message() string {
+	if e.Window != "" {
+		return tr("AlertMoveMessage", "%s is %s, %s %.2f%% from %s within %s", e.Coin, formatPrice(e.Price, e.Currency), e.condition(), math.Abs(e.Change), formatPrice(e.Threshold, e.Currency), e.Window)
+	}
+	return tr("AlertMessage", "%s is %s, %s %s", e.Coin, formatPrice(e.Price, e.Currency), e.condition(), formatPrice(e.Threshold, e.Currency))
+}
+
+// summary is the alert in a few words, for notification subjects.
+func (e AlertEvent) summary() string {
+	if e.Window != "" {
+		return tr("AlertMoveSummary", "%s %s %.2f%% in %s", e.Coin, e.condition(), math.Abs(e.Change), e.Window)
+	}
+	return fmt.Sprintf("%s %s %s", e.Coin, e.condition(), formatPrice(e.Threshold, e.Currency))
+}
+
+// condition is Condition in the selected language; the JSON keeps the
+// English word for scripts.
+func (e AlertEvent) condition() string {
+	switch e.Condition {
+	case "above":
+		return tr("AlertAbove", "above")
+	case "below":
+		return tr("AlertBelow", "below")
+	case "up":
+		return tr("AlertUp", "up")
+	case "down":
+		return tr("AlertDown", "down")
+	}
+	return e.Condition
+}
+
+// parseMoves reads --moves as a percentage, with or without the % sign.
+func parseMoves(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil || v <= 0 {
+		return 0, errors.New(tr("AlertInvalidMoves", "invalid --moves %q: use a positive percentage, e.g. 5%%", s))
+	}
+	return v, nil
+}
+
+// moveWindow holds the prices checked within the last --window.
+type moveWindow []priceBaseline
+
+// add drops the prices older than --window and reports whether price has
+// moved by moves percent or more from the lowest or highest left, with the
+// direction, the price it moved from and the change, then records it.
+func (w *moveWindow) add(price float64, now time.Time, moves float64) (string, float64, float64, bool) {
+	kept := (*w)[:0]
+	for _, s := range *w {
+		if now.Sub(s.time) <= alertWindow {
+			kept = append(kept, s)
+		}
+	}
+	low, high := price, price
+	for _, s := range kept {
+		low, high = math.Min(low, s.price), math.Max(high, s.price)
+	}
+	*w = append(kept, priceBaseline{price, now})
+	if up := percentChange(low, price); up >= moves {
+		return "up", low, up, true
+	}
+	if down := percentChange(high, price); -down >= moves {
+		return "down", high, down, true
+	}
+	return "", 0, 0, false
+}
+
+// checkThresholds returns the condition the price has crossed, if any.
+func checkThresholds(price float64, above, below bool) (string, float64, bool) {
+	switch {
+	case above && price >= alertAbove:
+		return "above", alertAbove, true
+	case below && price <= alertBelow:
+		return "below", alertBelow, true
+	}
+	return "", 0, false
+}
+
+// alertNotifiers returns the notifiers named by --send plus those given
+// directly with --webhook, --slack-webhook and --telegram-chat.
+func alertNotifiers() ([]notifier, error) {
+	var ns []notifier
+	if len(alertSend) > 0 {
+		selected, err := selectNotifiers(alertSend)
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, selected...)
+	}
+	if alertWebhook != "" {
+		ns = append(ns, webhookNotifier(alertWebhook))
+	}
+	if alertSlackWebhook != "" {
+		ns = append(ns, slackNotifier(alertSlackWebhook))
+	}
+	if alertTelegramChat != "" {
+		token := config.GetString("notifiers.telegram.bot_token")
+		if token == "" {
+			token = providerKey("telegram")
+		}
+		if token == "" {
+			return nil, errors.New(tr("AlertTelegramToken", "--telegram-chat needs a bot token: set notifiers.telegram.bot_token or run %q", "crypto-cli keys set telegram"))
+		}
+		ns = append(ns, telegramNotifier(token, alertTelegramChat))
+	}
+	return ns, nil
+}
+
+// reportAlert delivers the alert. The desktop notification also shows the
+// move since the first check, start.
+func reportAlert(e AlertEvent, ns []notifier, start priceBaseline) error {
+	defer alarm(true)
+	if alertNotify {
+		body := e.message()
+		if start.price > 0 {
+			body += tr("AlertSinceStart", " (%+.2f%% since %s)", percentChange(start.price, e.Price), start.time.Format("15:04"))
+		}
+		if err := desktopNotify("crypto-cli alert: "+e.Coin, body); err != nil {
+			fmt.Fprint(os.Stderr, tr("DesktopNotifyWarning", "Warning: desktop notification failed: %v\n", err))
+		}
+	}
+	subject := "crypto-cli alert: " + e.summary()
+	if err := notifyAll(ns, message{Subject: subject, Body: e.message(), Event: e}); err != nil {
+		fmt.Fprint(os.Stderr, tr("AlertDeliveryWarning", "Warning: could not deliver the alert: %v\n", err))
+	}
+	if outputFormat == "json" {
+		return printJSON(e)
+	}
+	fmt.Println(e.message())
+	return nil
+}
+
+var alertCmd = &cobra.Command{
+	Use:   "alert <coin>",
+	Short: "Wait until a coin's price crosses a threshold or moves sharply",
+	Long: `Poll a coin's price until it rises to --above or falls to --below, or with
+--moves until it has moved by that percentage, up or down, within the last
+--window of checks. Then print a message and exit with status 4, so scripts
+can tell a triggered alert from an error.`,
+	Example: `  crypto-cli alert bitcoin --above 70000 --below 60000 --interval 30s
+  crypto-cli alert btc --moves 5% --window 1h --interval 1m
+  crypto-cli alert eth --below 3000 --notify
+  crypto-cli alert eth --below 3000 --bell --sound ~/sounds/alarm.wav
+  crypto-cli alert btc --above 100000 --webhook https://example.com/hook --send slack`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		above, below := cmd.Flags().Changed("above"), cmd.Flags().Changed("below")
+		var moves float64
+		if alertMoves != "" {
+			var err error
+			if moves, err = parseMoves(alertMoves); err != nil {
+				return err
+			}
+			if alertWindow <= alertInterval {
+				return errors.New(tr("AlertWindowTooShort", "--window must be longer than --interval"))
+			}
+		}
+		if !above && !below && moves == 0 {
+			return errors.New(tr("AlertNoCondition", "set --above, --below, --moves or a combination"))
+		}
+		if above && below && alertBelow >= alertAbove {
+			return errors.New(tr("AlertBelowNotLess", "--below must be less than --above"))
+		}
+		if alertInterval < time.Second {
+			return errors.New(tr("IntervalTooShort", "--interval must be at least %s", time.Second))
+		}
+		if err := checkSound(); err != nil {
+			return err
+		}
+		ns, err := alertNotifiers()
+		if err != nil {
+			return err
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		allowPrompt = false
+		noCache = true
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		var start priceBaseline
+		var window moveWindow
+		for {
+			q := quoteCoin(ctx, coin, alertCurrency)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if q.err != nil {
+				log.Print(tr("RetryError", "Error: %v (retrying in %s)", q.err, alertInterval))
+			} else if condition, threshold, ok := checkThresholds(q.Price, above, below); ok {
+				if err := reportAlert(AlertEvent{coin, q.Price, alertCurrency, condition, threshold, q.Source, time.Now().UTC(), 0, ""}, ns, start); err != nil {
+					return err
+				}
+				return exitSilently(exitAlertTriggered)
+			} else if condition, from, change, ok := window.add(q.Price, time.Now(), moves); moves > 0 && ok {
+				if err := reportAlert(AlertEvent{coin, q.Price, alertCurrency, condition, from, q.Source, time.Now().UTC(), change, alertWindow.String()}, ns, start); err != nil {
+					return err
+				}
+				return exitSilently(exitAlertTriggered)
+			} else if verbose {
+				fmt.Fprintf(os.Stderr, "%s %s %s\n", time.Now().Format("15:04:05"), coin, formatPrice(q.Price, alertCurrency))
+			}
+			if q.err == nil && start.price == 0 {
+				start = priceBaseline{q.Price, time.Now()}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(alertInterval):
+			}
+		}
+	},
+}
+
+func init() {
+	alertCmd.Flags().Float64Var(&alertAbove, "above", 0, "trigger when the price rises to this value or higher")
+	alertCmd.Flags().Float64Var(&alertBelow, "below", 0, "trigger when the price falls to this value or lower")
+	alertCmd.Flags().StringVar(&alertMoves, "moves", "", "trigger when the price moves by this percentage, up or down, within --window, e.g. 5%")
+	alertCmd.Flags().DurationVar(&alertWindow, "window", time.Hour, "time span --moves is measured over")
+	alertCmd.Flags().DurationVar(&alertInterval, "interval", 30*time.Second, "time between price checks")
+	alertCmd.Flags().StringVarP(&alertCurrency, "vs-currency", "c", "usd", "currency the thresholds are in")
+	alertCmd.Flags().BoolVar(&alertNotify, "notify", false, "also show a desktop notification when the alert triggers (notify-send on Linux, osascript on macOS, a toast on Windows)")
+	alertCmd.Flags().BoolVar(&bell, "bell", false, "ring the terminal bell when the alert triggers")
+	alertCmd.Flags().StringVar(&soundFile, "sound", "", "play this sound file when the alert triggers (afplay on macOS, paplay, pw-play, aplay or ffplay on Linux, WAV on Windows)")
+	alertCmd.Flags().StringSliceVar(&alertSend, "send", nil, "deliver the alert through these configured notifiers: slack, telegram, email, webhook")
+	alertCmd.Flags().StringVar(&alertWebhook, "webhook", "", "POST the alert as JSON to this URL")
+	alertCmd.Flags().StringVar(&alertSlackWebhook, "slack-webhook", "", "post the alert to this Slack incoming webhook")
+	alertCmd.Flags().StringVar(&alertTelegramChat, "telegram-chat", "", "send the alert to this Telegram chat ID, using the configured bot token")
+	alertCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "print every price checked")
+	alertCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.AddCommand(alertCmd)
+}
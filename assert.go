@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	assertMin      float64
+	assertMax      float64
+	assertCurrency string
+)
+
+// AssertResult is one coin's price checked against the bounds. Min and Max
+// are left out when not given.
+type AssertResult struct {
+	Coin     string   `json:"coin"`
+	Price    float64  `json:"price,omitempty"`
+	Currency string   `json:"currency"`
+	Source   string   `json:"source,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Pass     bool     `json:"pass"`
+	Reason   string   `json:"reason"`
+}
+
+func checkBounds(q CoinQuote, min, max *float64) AssertResult {
+	r := AssertResult{Coin: q.Coin, Price: q.Price, Currency: q.Currency, Source: q.Source, Min: min, Max: max}
+	price := formatPrice(q.Price, q.Currency)
+	switch {
+	case q.err != nil:
+		r.Reason = q.err.Error()
+	case min != nil && q.Price < *min:
+		r.Reason = fmt.Sprintf("%s is below the minimum of %s", price, formatPrice(*min, q.Currency))
+	case max != nil && q.Price > *max:
+		r.Reason = fmt.Sprintf("%s is above the maximum of %s", price, formatPrice(*max, q.Currency))
+	case min != nil && max != nil:
+		r.Pass, r.Reason = true, fmt.Sprintf("%s is between %s and %s", price, formatPrice(*min, q.Currency), formatPrice(*max, q.Currency))
+	case min != nil:
+		r.Pass, r.Reason = true, fmt.Sprintf("%s is at least %s", price, formatPrice(*min, q.Currency))
+	default:
+		r.Pass, r.Reason = true, fmt.Sprintf("%s is at most %s", price, formatPrice(*max, q.Currency))
+	}
+	return r
+}
+
+var assertCmd = &cobra.Command{
+	Use:   "assert <coin...>",
+	Short: "Exit non-zero unless prices are within bounds, for CI and scripts",
+	Long: `Check that each coin's price is within --min and --max (inclusive) and
+exit 0 if it is. A price out of bounds exits with 4, like a triggered
+alert; a price that cannot be fetched exits with the usual code for why.
+Each check is printed as one line, or as JSON with -o json; --quiet
+prints nothing and leaves the result to the exit code.`,
+	Example: `  crypto-cli assert bitcoin --min 50000 --max 80000
+  crypto-cli assert eth --min 3000 -c eur -q && ./rebalance.sh
+  crypto-cli assert bitcoin ethereum --max 100000 -o json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var min, max *float64
+		if cmd.Flags().Changed("min") {
+			min = &assertMin
+		}
+		if cmd.Flags().Changed("max") {
+			max = &assertMax
+		}
+		switch {
+		case min == nil && max == nil:
+			return fmt.Errorf("set --min, --max or both")
+		case min != nil && max != nil && *min > *max:
+			return fmt.Errorf("--min %g is above --max %g", *min, *max)
+		}
+		coins, err := expandWatchlists(args)
+		if err != nil {
+			return err
+		}
+		allowPrompt = false
+
+		quotes := quoteCoins(cmd.Context(), coins, []string{strings.ToLower(assertCurrency)})
+		results := make([]AssertResult, len(quotes))
+		failed := false
+		for i, q := range quotes {
+			results[i] = checkBounds(q, min, max)
+			failed = failed || !results[i].Pass
+		}
+
+		switch {
+		case quiet:
+		case outputFormat == "json" && len(results) == 1:
+			printJSON(results[0])
+		case outputFormat == "json":
+			printJSON(results)
+		default:
+			color := useColor(os.Stdout)
+			for _, r := range results {
+				status := paint("ok  ", "32", color)
+				if !r.Pass {
+					status = paint("FAIL", "31", color)
+				}
+				fmt.Printf("%s %s: %s\n", status, r.Coin, r.Reason)
+			}
+		}
+
+		if err := batchError(quotes); err != nil {
+			return exitSilently(exitCode(err))
+		}
+		if failed {
+			return exitSilently(exitAlertTriggered)
+		}
+		return nil
+	},
+}
+
+func init() {
+	assertCmd.Flags().Float64Var(&assertMin, "min", 0, "fail when the price is below this")
+	assertCmd.Flags().Float64Var(&assertMax, "max", 0, "fail when the price is above this")
+	assertCmd.Flags().StringVarP(&assertCurrency, "vs-currency", "c", "usd", "currency the bounds are in")
+	assertCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "print nothing; the exit code is the result")
+	rootCmd.AddCommand(assertCmd)
+}
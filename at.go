@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	priceAtFlag string
+	priceAt     time.Time
+)
+
+const atTimeLayout = "2006-01-02 15:04 MST"
+
+// atLayouts are the forms --at accepts besides RFC 3339. Times without a
+// zone are UTC; a date alone is midnight UTC.
+var atLayouts = []string{
+	time.DateOnly,
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04 -07:00",
+}
+
+func parseAt(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, zone := range []string{" UTC", " GMT", "Z"} {
+		if trimmed, ok := strings.CutSuffix(s, zone); ok && !strings.HasSuffix(trimmed, " ") {
+			s = trimmed
+			break
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range atLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: use YYYY-MM-DD, 'YYYY-MM-DD HH:MM [UTC]' or RFC 3339", s)
+}
+
+// checkPriceAt parses --at and rejects the flags that only make sense for
+// live prices.
+func checkPriceAt() error {
+	if priceAtFlag == "" {
+		return nil
+	}
+	t, err := parseAt(priceAtFlag)
+	if err != nil {
+		return err
+	}
+	switch {
+	case t.After(time.Now()):
+		return fmt.Errorf("--at %s is in the future", t.Format(atTimeLayout))
+	case repeatEvery > 0:
+		return fmt.Errorf("--at cannot be combined with --every")
+	case len(changePeriods) > 0:
+		return fmt.Errorf("--at cannot be combined with --change")
+	case outputFormat == "statusbar":
+		return fmt.Errorf("--at cannot be combined with --output statusbar")
+	}
+	priceAt = t
+	return nil
+}
+
+// quoteCoinAt prices the coin at CoinGecko's data point nearest to at. A
+// narrow range gets the finest points, five-minutely within a day, so the
+// wider range is only asked for when the narrow one has none, as happens
+// for older dates on the free API.
+func quoteCoinAt(ctx context.Context, crypto, currency string, at time.Time) CoinQuote {
+	q := CoinQuote{Coin: crypto, Currency: currency, Source: "CoinGecko"}
+	start := time.Now()
+	for _, window := range []time.Duration{time.Hour, 48 * time.Hour} {
+		points, err := fetchHistory(ctx, crypto, currency, at.Add(-window), at.Add(window))
+		if err != nil {
+			q.err = withExitCode(exitAllProvidersFailed, err)
+			return q
+		}
+		if len(points) == 0 {
+			continue
+		}
+		nearest := points[0]
+		for _, p := range points[1:] {
+			if p.Time.Sub(at).Abs() < nearest.Time.Sub(at).Abs() {
+				nearest = p
+			}
+		}
+		q.Price, q.Timestamp, q.Duration = nearest.Price, nearest.Time, time.Since(start)
+		return q
+	}
+	q.err = withExitCode(exitAllProvidersFailed, errors.New(tr("NoPriceHistoryNear", "no price history for %s near %s", crypto, at.Format(atTimeLayout))))
+	return q
+}
+
+func printQuoteAt(q CoinQuote) {
+	color := useColor(os.Stdout)
+	fmt.Print(tr("HistoricalPriceLine", "The price of %s at %s was %s (Source: %s, data point at %s)\n",
+		q.Coin, priceAt.Format(atTimeLayout), formatPrice(q.Price, q.Currency), dim(q.Source, color), dim(q.Timestamp.UTC().Format(atTimeLayout), color)))
+	printHolding(q)
+}
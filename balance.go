@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	mempoolAddressAPI   = "/api/address/%s"
+	ethplorerBalanceAPI = "/getAddressInfo/%s?apiKey=%s&showETHTotals=false"
+
+	satoshisPerBitcoin = 1e8
+)
+
+var (
+	balanceChain    string
+	balanceFile     string
+	balanceCurrency string
+)
+
+var (
+	ethereumAddressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	bitcoinAddressPattern  = regexp.MustCompile(`^(bc1[02-9ac-hj-np-z]{11,87}|[13][1-9A-HJ-NP-Za-km-z]{25,34})$`)
+)
+
+// balanceChains maps each chain to the coin its balances are in.
+var balanceChains = map[string]string{
+	"bitcoin":  "bitcoin",
+	"ethereum": "ethereum",
+}
+
+// AddressBalance is the native coin balance of one address. Pending is
+// the net amount of unconfirmed transactions, which Balance leaves out.
+type AddressBalance struct {
+	Address string  `json:"address"`
+	Label   string  `json:"label,omitempty"`
+	Chain   string  `json:"chain"`
+	Symbol  string  `json:"symbol"`
+	Balance float64 `json:"balance"`
+	Pending float64 `json:"pending,omitempty"`
+	Price   float64 `json:"price,omitempty"`
+	Value   float64 `json:"value,omitempty"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// BalanceReport sums the balances of a set of addresses per chain and in
+// total.
+type BalanceReport struct {
+	Currency  string             `json:"currency"`
+	Addresses []AddressBalance   `json:"addresses"`
+	Chains    map[string]float64 `json:"chains"`
+	Total     float64            `json:"total"`
+}
+
+// addressChain works out which chain an address is on from its format.
+func addressChain(address string) (string, error) {
+	switch {
+	case ethereumAddressPattern.MatchString(address), strings.HasSuffix(strings.ToLower(address), ".eth"):
+		return "ethereum", nil
+	case bitcoinAddressPattern.MatchString(address):
+		return "bitcoin", nil
+	}
+	return "", fmt.Errorf("cannot tell which chain %q is on: use --chain", address)
+}
+
+// readAddressList reads one address per line, ignoring blank lines and #
+// comments. Anything after the address is its label.
+func readAddressList(r io.Reader) ([]AddressBalance, error) {
+	var addresses []AddressBalance
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		address, label, _ := strings.Cut(strings.TrimSpace(line), " ")
+		address = strings.TrimSuffix(strings.TrimSpace(address), ",")
+		if address == "" {
+			continue
+		}
+		addresses = append(addresses, AddressBalance{Address: address, Label: strings.Trim(strings.TrimSpace(label), `",`)})
+	}
+	return addresses, scanner.Err()
+}
+
+func readAddressFile(path string) ([]AddressBalance, error) {
+	if path == "-" {
+		return readAddressList(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readAddressList(f)
+}
+
+// fetchBitcoinBalance reads an address's confirmed and unconfirmed
+// balance from mempool.space.
+func fetchBitcoinBalance(ctx context.Context, address string) (balance, pending float64, err error) {
+	type stats struct {
+		Funded int64 `json:"funded_txo_sum"`
+		Spent  int64 `json:"spent_txo_sum"`
+	}
+	var resp struct {
+		Chain   stats `json:"chain_stats"`
+		Mempool stats `json:"mempool_stats"`
+	}
+	if err := getJSON(ctx, providerURL("mempool")+fmt.Sprintf(mempoolAddressAPI, address), "mempool", &resp); err != nil {
+		return 0, 0, err
+	}
+	return float64(resp.Chain.Funded-resp.Chain.Spent) / satoshisPerBitcoin, float64(resp.Mempool.Funded-resp.Mempool.Spent) / satoshisPerBitcoin, nil
+}
+
+func fetchEthereumBalance(ctx context.Context, address string) (float64, error) {
+	var resp struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		ETH struct {
+			Balance float64 `json:"balance"`
+		} `json:"ETH"`
+	}
+	if err := getJSON(ctx, providerURL("ethplorer")+fmt.Sprintf(ethplorerBalanceAPI, address, ethplorerKey()), "ethplorer", &resp); err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		return 0, fmt.Errorf("ethplorer: %s", resp.Error.Message)
+	}
+	return resp.ETH.Balance, nil
+}
+
+func fetchBalance(ctx context.Context, b *AddressBalance) error {
+	switch b.Chain {
+	case "bitcoin":
+		balance, pending, err := fetchBitcoinBalance(ctx, b.Address)
+		b.Balance, b.Pending = balance, pending
+		return err
+	case "ethereum":
+		if strings.HasSuffix(strings.ToLower(b.Address), ".eth") {
+			address, err := resolveENS(ctx, b.Address)
+			if err != nil {
+				return err
+			}
+			if b.Label == "" {
+				b.Label = b.Address
+			}
+			b.Address = address
+		}
+		balance, err := fetchEthereumBalance(ctx, b.Address)
+		b.Balance = balance
+		return err
+	}
+	return fmt.Errorf("unsupported chain %q", b.Chain)
+}
+
+// fetchBalances looks up every address, at most --concurrency at once,
+// and values them at one quote per chain.
+func fetchBalances(ctx context.Context, addresses []AddressBalance, currency string) BalanceReport {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i := range addresses {
+		wg.Add(1)
+		go func(b *AddressBalance) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := fetchBalance(ctx, b); err != nil {
+				b.Error = err.Error()
+			}
+		}(&addresses[i])
+	}
+	wg.Wait()
+
+	report := BalanceReport{Currency: currency, Addresses: addresses, Chains: make(map[string]float64)}
+	quotes := make(map[string]CoinQuote)
+	for i := range addresses {
+		b := &addresses[i]
+		if b.Error != "" {
+			continue
+		}
+		coin := balanceChains[b.Chain]
+		q, ok := quotes[coin]
+		if !ok {
+			q = quoteCoin(ctx, coin, currency)
+			quotes[coin] = q
+		}
+		if q.err != nil {
+			b.Error = q.err.Error()
+			continue
+		}
+		b.Price, b.Value = q.Price, b.Balance*q.Price
+		report.Chains[b.Chain] += b.Value
+		report.Total += b.Value
+	}
+	return report
+}
+
+func printBalanceReport(report BalanceReport) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tCHAIN\tBALANCE\tVALUE")
+	for _, b := range report.Addresses {
+		name := b.Address
+		if b.Label != "" {
+			name = b.Label + " (" + shortAddress(b.Address) + ")"
+		}
+		if b.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\terror: %s\n", name, b.Chain, b.Error)
+			continue
+		}
+		balance := fmt.Sprintf("%s %s", localizeNumber(b.Balance, -1), b.Symbol)
+		if b.Pending != 0 {
+			balance += fmt.Sprintf(" (%+g pending)", b.Pending)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, b.Chain, balance, formatPrice(b.Value, report.Currency))
+	}
+	if len(report.Addresses) > 1 {
+		if len(report.Chains) > 1 {
+			chains := make([]string, 0, len(report.Chains))
+			for chain := range report.Chains {
+				chains = append(chains, chain)
+			}
+			sort.Strings(chains)
+			for _, chain := range chains {
+				fmt.Fprintf(w, "Total %s\t\t\t%s\n", chain, formatPrice(report.Chains[chain], report.Currency))
+			}
+		}
+		fmt.Fprintf(w, "Total\t\t\t%s\n", formatPrice(report.Total, report.Currency))
+	}
+	return w.Flush()
+}
+
+// shortAddress abbreviates an address to its first and last characters.
+func shortAddress(address string) string {
+	if len(address) <= 14 {
+		return address
+	}
+	return address[:8] + "…" + address[len(address)-4:]
+}
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance <address...>",
+	Short: "Look up and value the balances of Bitcoin and Ethereum addresses",
+	Long: `Look up the native coin balance of Bitcoin and Ethereum addresses on public
+block explorers (mempool.space and Ethplorer) and value it at the live
+price. Several addresses, e.g. a whole cold-storage setup, are summed per
+chain and in total; --file reads them one per line, with an optional label
+after each address.
+
+The chain is worked out from the address format unless --chain is given.
+Only the native coin is counted; see the value command for ERC-20 tokens.`,
+	Example: `  crypto-cli balance bc1qxy2kgdygjrsqtzq2n0yrf2493p83kkfjhx0wlh
+  crypto-cli balance 0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045 --chain ethereum
+  crypto-cli balance --file cold-storage.txt -c eur`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var addresses []AddressBalance
+		for _, arg := range args {
+			addresses = append(addresses, AddressBalance{Address: arg})
+		}
+		if balanceFile != "" {
+			list, err := readAddressFile(balanceFile)
+			if err != nil {
+				return err
+			}
+			addresses = append(addresses, list...)
+		}
+		if len(addresses) == 0 {
+			return fmt.Errorf("no addresses given: pass them as arguments or with --file")
+		}
+		chain := strings.ToLower(balanceChain)
+		if _, ok := balanceChains[chain]; !ok && chain != "auto" {
+			return fmt.Errorf("invalid --chain %q: must be auto, bitcoin or ethereum", balanceChain)
+		}
+		for i := range addresses {
+			b := &addresses[i]
+			b.Chain = chain
+			if chain == "auto" {
+				detected, err := addressChain(b.Address)
+				if err != nil {
+					return err
+				}
+				b.Chain = detected
+			}
+			b.Symbol = strings.ToUpper(coinSymbol(balanceChains[b.Chain]))
+		}
+		if offline {
+			return errOffline
+		}
+
+		currency := strings.ToLower(balanceCurrency)
+		report := fetchBalances(cmd.Context(), addresses, currency)
+		if outputFormat == "json" {
+			if err := printJSON(report); err != nil {
+				return err
+			}
+		} else if err := printBalanceReport(report); err != nil {
+			return err
+		}
+		failed := 0
+		for _, b := range report.Addresses {
+			if b.Error != "" {
+				failed++
+			}
+		}
+		if failed == len(report.Addresses) {
+			return exitSilently(exitAllProvidersFailed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	balanceCmd.Flags().StringVar(&balanceChain, "chain", "auto", "chain the addresses are on: auto, bitcoin or ethereum")
+	balanceCmd.Flags().StringVarP(&balanceFile, "file", "f", "", "read addresses from a file (- for stdin), one per line with an optional label after it")
+	balanceCmd.Flags().StringVarP(&balanceCurrency, "vs-currency", "c", "usd", "currency to value the balances in")
+	balanceCmd.RegisterFlagCompletionFunc("chain", cobra.FixedCompletions([]string{"auto", "bitcoin", "ethereum"}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(balanceCmd)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// batchSize is the most coins asked of a batch provider in one request,
+// keeping the URL well within what CoinGecko accepts.
+const batchSize = 100
+
+// concurrency bounds how many coins quoteCoins prices at once, each of
+// them fanning out to every provider that needs a request per coin.
+var concurrency int
+
+type prefetchKey struct{}
+
+// prefetched holds the answers of batch requests made before the per-coin
+// fetches, keyed like the quote cache.
+type prefetched struct {
+	quotes map[string]pricefeed.Quote
+	errs   map[string]error
+}
+
+// prefetchProvider answers from the batch results in ctx when they cover
+// the coin, and otherwise asks the provider.
+type prefetchProvider struct {
+	pricefeed.Provider
+	name string
+}
+
+func (p prefetchProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	if pf, ok := ctx.Value(prefetchKey{}).(*prefetched); ok {
+		key := cacheKey(p.name, coin, currency)
+		if q, ok := pf.quotes[key]; ok {
+			return q, nil
+		}
+		if err, ok := pf.errs[key]; ok {
+			return pricefeed.Quote{}, err
+		}
+	}
+	return p.Provider.Fetch(ctx, coin, currency)
+}
+
+// prefetchBatches prices the coins with one request per batchSize coins to
+// each enabled provider that supports batching, and returns ctx carrying
+// the results for prefetchProvider. Coins with a fresh cached quote or an
+// ID pinned in the coin map are left out.
+func prefetchBatches(ctx context.Context, coins, currencies []string) context.Context {
+	if mockMode || offline || len(coins)*len(currencies) < 2 {
+		return ctx
+	}
+	pf := &prefetched{quotes: make(map[string]pricefeed.Quote), errs: make(map[string]error)}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, p := range activeProviders() {
+		batcher, ok := feedProvider(p).(pricefeed.BatchProvider)
+		if !ok {
+			continue
+		}
+		var pending []string
+		for _, coin := range coins {
+			if _, pinned := pinnedID(p.name, coin); !pinned && !cachedFresh(p.name, coin, currencies) {
+				pending = append(pending, coin)
+			}
+		}
+		for start := 0; start < len(pending); start += batchSize {
+			batch := pending[start:min(start+batchSize, len(pending))]
+			wg.Add(1)
+			go func(name string, batch []string) {
+				defer wg.Done()
+				begin := time.Now()
+				quotes, err := batcher.FetchBatch(ctx, batch, currencies)
+				if err != nil && metricsEnabled {
+					metrics.observe(name, "", "", 0, time.Since(begin), err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				for _, coin := range batch {
+					for _, currency := range currencies {
+						key := cacheKey(name, coin, currency)
+						if err != nil {
+							pf.errs[key] = err
+						} else {
+							pf.errs[key] = fmt.Errorf("%w: no %s price for %s", pricefeed.ErrNotFound, currency, coin)
+						}
+					}
+				}
+				for _, q := range quotes {
+					key := cacheKey(name, q.Coin, q.Currency)
+					delete(pf.errs, key)
+					pf.quotes[key] = q
+					if metricsEnabled {
+						metrics.observe(name, q.Coin, q.Currency, q.Price, time.Since(begin), nil)
+					}
+				}
+			}(p.name, batch)
+		}
+	}
+	wg.Wait()
+	return context.WithValue(ctx, prefetchKey{}, pf)
+}
+
+// cachedFresh reports whether the quote cache will answer for the coin in
+// every currency without asking the provider.
+func cachedFresh(provider, coin string, currencies []string) bool {
+	if noCache || cacheTTL <= 0 {
+		return false
+	}
+	for _, currency := range currencies {
+		e, ok := quoteStore.get(cacheKey(provider, coin, currency))
+		if !ok || time.Since(e.Fetched) >= cacheTTL {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	benchmarkCoin   string
+	benchmarkRounds int
+	benchmarkSave   bool
+)
+
+// runBenchmark queries every enabled provider runs times for coin, prints
+// the results fastest and most reliable first and, with --save, writes that
+// order to the config as the priority.
+func runBenchmark(ctx context.Context, coin string, runs int) error {
+	if runs < 1 {
+		return fmt.Errorf("the number of rounds must be at least 1")
+	}
+	if offline {
+		return errOffline
+	}
+	coin, err := resolveCoin(ctx, coin, exactID)
+	if err != nil {
+		return err
+	}
+	results := benchmarkProviders(ctx, coin, runs)
+	// Providers that never answered are left out of the suggested order.
+	var order []string
+	for _, b := range results {
+		if b.Successes > 0 {
+			order = append(order, b.name)
+		}
+	}
+
+	if outputFormat == "json" {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tSUCCESS\tERRORS\tP50\tP95\tP99\tDEVIATION\tLAST ERROR")
+		for _, b := range results {
+			if b.Successes == 0 {
+				fmt.Fprintf(w, "%s\t0/%d\t%d\t-\t-\t-\t-\t%s\n", b.Provider, b.Runs, b.Errors, b.LastError)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%d/%d\t%d\t%s\t%s\t%s\t%.2f%%\t%s\n", b.Provider, b.Successes, b.Runs, b.Errors,
+				b.P50.Round(time.Millisecond), b.P95.Round(time.Millisecond), b.P99.Round(time.Millisecond), b.Deviation, b.LastError)
+		}
+		w.Flush()
+		if len(order) > 0 && !benchmarkSave {
+			fmt.Printf("\nSuggested priority: --priority %s (or rerun with --save)\n", strings.Join(order, ","))
+		}
+	}
+
+	if !benchmarkSave {
+		return nil
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("no provider answered; the priority was not saved")
+	}
+	path, key, err := savePriority(order)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved %s: [%s] to %s\n", key, strings.Join(order, ", "), path)
+	return nil
+}
+
+// savePriority writes order to the config file as default.priority, in
+// the active profile if there is one.
+func savePriority(order []string) (path, key string, err error) {
+	path = configPath()
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType("yaml")
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", "", fmt.Errorf("reading config %s: %w", path, err)
+	}
+	key = "default.priority"
+	if profileName != "" {
+		key = "profiles." + profileName + "." + key
+	}
+	file.Set(key, order)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", "", err
+	}
+	return path, key, file.WriteConfigAs(path)
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure each provider's latency and error rate and suggest a priority order",
+	Long: `Query every enabled provider --rounds times for --coin and report its
+success count, errors, p50/p95/p99 latency and how far its price is from
+the median of the others. Providers are listed most reliable first, then
+fastest; --save writes that order to the config file as default.priority
+(in the active --profile, if any).`,
+	Example: `  crypto-cli bench
+  crypto-cli bench --coin ethereum --rounds 20 --save`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBenchmark(cmd.Context(), benchmarkCoin, benchmarkRounds)
+	},
+}
+
+func init() {
+	benchCmd.Flags().StringVar(&benchmarkCoin, "coin", "bitcoin", "coin to request from each provider")
+	benchCmd.Flags().IntVar(&benchmarkRounds, "rounds", 10, "number of requests per provider")
+	benchCmd.Flags().BoolVar(&benchmarkSave, "save", false, "write the suggested priority order to the config file")
+	rootCmd.AddCommand(benchCmd)
+}
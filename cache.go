@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheTTL time.Duration
+	noCache  bool
+	offline  bool
+)
+
+var errOffline = errors.New("network access is disabled by --offline")
+
+type cachedQuote struct {
+	Price     float64   `json:"price"`
+	Volume    float64   `json:"volume,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitempty"`
+	Fetched   time.Time `json:"fetched"`
+}
+
+// quoteCache holds the last quote from each provider for each coin and
+// currency, keyed "provider/coin/currency" and stored as JSON in the cache
+// dir.
+type quoteCache struct {
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]cachedQuote
+}
+
+var quoteStore = &quoteCache{}
+
+func quoteCachePath() string {
+	return filepath.Join(cacheDir(), "quotes.json")
+}
+
+func cacheKey(provider, coin, currency string) string {
+	return strings.ToLower(provider) + "/" + coin + "/" + currency
+}
+
+func (c *quoteCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = make(map[string]cachedQuote)
+	if data, err := os.ReadFile(quoteCachePath()); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+}
+
+func (c *quoteCache) get(key string) (cachedQuote, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+// put records the quote and rewrites the cache file. Failing to write the
+// cache never fails the request.
+func (c *quoteCache) put(key string, e cachedQuote) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	c.entries[key] = e
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return
+	}
+	path := quoteCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err == nil {
+		os.Rename(tmp, path)
+	}
+}
+
+// cachingProvider answers from the quote cache while its entry is younger
+// than --cache-ttl, or at any age under --offline, and caches every fresh
+// quote. A cached quote without an upstream timestamp is dated by when it
+// was fetched, so its age shows.
+type cachingProvider struct {
+	pricefeed.Provider
+	name string
+}
+
+func (p cachingProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	key := cacheKey(p.name, coin, currency)
+	e, ok := quoteStore.get(key)
+	if ok && (offline || time.Since(e.Fetched) < cacheTTL) {
+		return e.quote(coin, currency, p.Name()), nil
+	}
+	if offline {
+		return pricefeed.Quote{}, fmt.Errorf("no cached %s price for %s", currency, coin)
+	}
+	q, err := p.Provider.Fetch(ctx, coin, currency)
+	if err == nil {
+		quoteStore.put(key, cachedQuote{Price: q.Price, Volume: q.Volume, Timestamp: q.Timestamp, Fetched: time.Now()})
+	}
+	return q, err
+}
+
+func (e cachedQuote) quote(coin, currency, source string) pricefeed.Quote {
+	timestamp := e.Timestamp
+	if timestamp.IsZero() {
+		timestamp = e.Fetched
+	}
+	return pricefeed.Quote{Coin: coin, Currency: currency, Price: e.Price, Source: source, Volume: e.Volume, Timestamp: timestamp}
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the on-disk cache of quotes and the coin list",
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the cached quotes and coin list",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, path := range []string{quoteCachePath(), registryPath()} {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		fmt.Printf("Cleared the cache in %s\n", cacheDir())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", time.Minute, "Reuse provider quotes cached on disk for this long (0 disables)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Always fetch fresh quotes, ignoring and not updating the cache")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Never use the network: show the last cached quotes, however old")
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var changePeriods []string
+
+// changePeriodNames are the periods CoinGecko reports percentage changes
+// for, in display order.
+var changePeriodNames = []string{"1h", "24h", "7d", "14d", "30d", "200d", "1y"}
+
+const coingeckoChangesAPI = "/coins/markets?vs_currency=%s&ids=%s&price_change_percentage=%s"
+
+// checkChangePeriods validates --change and puts the periods in display
+// order.
+func checkChangePeriods() error {
+	requested := make(map[string]bool)
+	for _, p := range changePeriods {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if !containsFold(changePeriodNames, p) {
+			return fmt.Errorf("unknown --change period %q (expected %s)", p, strings.Join(changePeriodNames, ", "))
+		}
+		requested[p] = true
+	}
+	changePeriods = changePeriods[:0]
+	for _, p := range changePeriodNames {
+		if requested[p] {
+			changePeriods = append(changePeriods, p)
+		}
+	}
+	return nil
+}
+
+// fetchChanges returns the percentage change of each coin over each period,
+// keyed by coin ID and then period.
+func fetchChanges(ctx context.Context, ids []string, currency string, periods []string) (map[string]map[string]float64, error) {
+	var markets []map[string]any
+	url := providerURL("coingecko") + fmt.Sprintf(coingeckoChangesAPI, currency, strings.Join(ids, ","), strings.Join(periods, ","))
+	if err := getJSON(ctx, url, "coingecko", &markets); err != nil {
+		return nil, err
+	}
+	changes := make(map[string]map[string]float64)
+	for _, m := range markets {
+		id, _ := m["id"].(string)
+		for _, p := range periods {
+			if v, ok := m["price_change_percentage_"+p+"_in_currency"].(float64); ok {
+				if changes[id] == nil {
+					changes[id] = make(map[string]float64)
+				}
+				changes[id][p] = v
+			}
+		}
+	}
+	return changes, nil
+}
+
+// attachChanges fills in the --change periods of every successful quote.
+// Changes are extra context, so a failed lookup only warns.
+func attachChanges(ctx context.Context, quotes []CoinQuote) {
+	if len(changePeriods) == 0 || mockMode || offline {
+		return
+	}
+	byCurrency := make(map[string][]string)
+	for _, q := range quotes {
+		if q.err == nil && !containsFold(byCurrency[q.Currency], q.Coin) {
+			byCurrency[q.Currency] = append(byCurrency[q.Currency], q.Coin)
+		}
+	}
+	for currency, ids := range byCurrency {
+		changes, err := fetchChanges(ctx, ids, currency, changePeriods)
+		if err != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Warning: price changes unavailable: %v\n", err)
+			}
+			continue
+		}
+		for i := range quotes {
+			if quotes[i].err == nil && quotes[i].Currency == currency {
+				quotes[i].Changes = changes[quotes[i].Coin]
+			}
+		}
+	}
+}
+
+// formatChanges renders the quote's changes as "1h +0.12% 24h -1.40%".
+func formatChanges(q CoinQuote, color bool) string {
+	var parts []string
+	for _, p := range changePeriods {
+		if v, ok := q.Changes[p]; ok {
+			parts = append(parts, p+" "+colorChange(fmt.Sprintf("%+.2f%%", v), v, color))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const candleHeight = 12
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+type candle struct {
+	open, high, low, close float64
+}
+
+func terminalWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 20 {
+		return w
+	}
+	return 80
+}
+
+// candles groups the points into at most n candles of consecutive points.
+func candles(points []PricePoint, n int) []candle {
+	n = min(n, len(points))
+	out := make([]candle, 0, n)
+	for i := 0; i < n; i++ {
+		bucket := points[i*len(points)/n : (i+1)*len(points)/n]
+		c := candle{open: bucket[0].Price, close: bucket[len(bucket)-1].Price, high: bucket[0].Price, low: bucket[0].Price}
+		for _, p := range bucket[1:] {
+			c.high = math.Max(c.high, p.Price)
+			c.low = math.Min(c.low, p.Price)
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func priceRange(cs []candle) (float64, float64) {
+	low, high := cs[0].low, cs[0].high
+	for _, c := range cs[1:] {
+		low = math.Min(low, c.low)
+		high = math.Max(high, c.high)
+	}
+	return low, high
+}
+
+// sparkline renders one block per candle close, scaled between the lowest
+// and highest close.
+func sparkline(cs []candle) string {
+	low, high := cs[0].close, cs[0].close
+	for _, c := range cs {
+		low = math.Min(low, c.close)
+		high = math.Max(high, c.close)
+	}
+	var b strings.Builder
+	for _, c := range cs {
+		i := len(sparkBlocks) - 1
+		if high > low {
+			i = int((c.close - low) / (high - low) * float64(len(sparkBlocks)-1))
+		}
+		b.WriteRune(sparkBlocks[i])
+	}
+	return b.String()
+}
+
+func colored(s string, up, color bool) string {
+	switch {
+	case !color:
+		return s
+	case up:
+		return "\033[32m" + s + "\033[0m"
+	}
+	return "\033[31m" + s + "\033[0m"
+}
+
+// printChart draws the history as a sparkline or a candlestick chart sized
+// to the terminal. With --accessible it describes the trend in words
+// instead.
+func printChart(coin, currency, style string, points []PricePoint) error {
+	first, last := points[0], points[len(points)-1]
+	change := percentChange(first.Price, last.Price)
+	if accessible {
+		low, high := priceRange(candles(points, len(points)))
+		fmt.Printf("%s from %s to %s: %s to %s (%+.2f%%), low %s, high %s\n", coin,
+			first.Time.Format(time.DateOnly), last.Time.Format(time.DateOnly),
+			formatPrice(first.Price, currency), formatPrice(last.Price, currency), change,
+			formatPrice(low, currency), formatPrice(high, currency))
+		return nil
+	}
+	color := useColor(os.Stdout)
+	width := terminalWidth()
+
+	switch style {
+	case "spark", "sparkline":
+		start, end := formatPrice(first.Price, currency), formatPrice(last.Price, currency)
+		label := fmt.Sprintf(" %+.2f%%", change)
+		cs := candles(points, width-len(start)-len(end)-len(label)-2)
+		fmt.Printf("%s %s %s%s\n", start, colored(sparkline(cs), change >= 0, color), end, label)
+		return nil
+	case "candle", "candles":
+	default:
+		return fmt.Errorf("unknown chart style %q (expected spark or candle)", style)
+	}
+
+	cs := candles(points, 1)
+	low, high := priceRange(cs)
+	top, bottom := formatPrice(high, currency), formatPrice(low, currency)
+	axis := max(len(top), len(bottom))
+	cs = candles(points, width-axis-3)
+	low, high = priceRange(cs)
+	row := func(v float64) int {
+		if high == low {
+			return candleHeight / 2
+		}
+		return int(math.Round((v - low) / (high - low) * (candleHeight - 1)))
+	}
+
+	for r := candleHeight - 1; r >= 0; r-- {
+		label := ""
+		switch r {
+		case candleHeight - 1:
+			label = formatPrice(high, currency)
+		case 0:
+			label = formatPrice(low, currency)
+		case candleHeight / 2:
+			label = formatPrice((high+low)/2, currency)
+		}
+		var b strings.Builder
+		for _, c := range cs {
+			bodyLow, bodyHigh := row(math.Min(c.open, c.close)), row(math.Max(c.open, c.close))
+			switch {
+			case r >= bodyLow && r <= bodyHigh:
+				b.WriteString(colored("┃", c.close >= c.open, color))
+			case r >= row(c.low) && r <= row(c.high):
+				b.WriteString(colored("│", c.close >= c.open, color))
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		fmt.Printf("%*s ┤ %s\n", axis, label, strings.TrimRight(b.String(), " "))
+	}
+	from, to := first.Time.Format(time.DateOnly), last.Time.Format(time.DateOnly)
+	gap := max(len(cs)-len(from)-len(to), 1)
+	fmt.Printf("%*s   %s%s%s\n", axis, "", from, strings.Repeat(" ", gap), to)
+	fmt.Printf("%*s   %s %s, %+.2f%%\n", axis, "", coin, strings.ToUpper(currency), change)
+	return nil
+}
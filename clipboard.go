@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+var copyToClipboard bool
+
+// clipboardText is what --copy places on the clipboard: the bare price
+// for a single coin, or one "coin price" line per coin, followed by the
+// currency code when it is not USD.
+func clipboardText(quotes []CoinQuote) string {
+	if len(quotes) == 1 {
+		if quotes[0].err != nil {
+			return ""
+		}
+		return formatNumber(quotes[0].Price)
+	}
+	var lines []string
+	for _, q := range quotes {
+		if q.err == nil {
+			line := q.Coin + " " + formatNumber(q.Price)
+			if q.Currency != "usd" {
+				line += " " + strings.ToUpper(q.Currency)
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatNumber(price float64) string {
+	return strconv.FormatFloat(price, 'f', priceDecimals(price), 64)
+}
+
+func copyQuotes(quotes []CoinQuote) {
+	text := clipboardText(quotes)
+	if text == "" {
+		return
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		fmt.Fprint(os.Stderr, tr("ClipboardWarning", "Warning: could not copy to clipboard: %v\n", err))
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"cli-crypto-price/pricefeed"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// coinMap pins, per coin ID and provider, the ID or symbol asked of the
+// provider, or "off" to leave the provider out for that coin. It is read
+// from coinMapPath on startup and on reload.
+var coinMap map[string]map[string]string
+
+// coinMapOff in the coin map turns a provider off for the coin.
+const coinMapOff = "off"
+
+// coinMapPath is coinmap.yaml in the config dir, shared by all profiles.
+func coinMapPath() string {
+	return filepath.Join(configDir(), "coinmap.yaml")
+}
+
+// loadCoinMap reads the coin map. A missing file means no overrides.
+func loadCoinMap() error {
+	coinMap = nil
+	data, err := os.ReadFile(coinMapPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var raw map[string]map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("reading %s: %w", coinMapPath(), err)
+	}
+	coinMap = make(map[string]map[string]string, len(raw))
+	for coin, pins := range raw {
+		m := make(map[string]string, len(pins))
+		for name, id := range pins {
+			if err := checkProviderNames([]string{name}, coinMapPath()); err != nil {
+				return err
+			}
+			id = strings.TrimSpace(id)
+			if strings.EqualFold(id, "false") || strings.EqualFold(id, coinMapOff) {
+				id = coinMapOff
+			}
+			if id == "" {
+				return fmt.Errorf("%s: empty ID for %s on %s", coinMapPath(), coin, name)
+			}
+			m[strings.ToLower(name)] = id
+		}
+		coinMap[strings.ToLower(coin)] = m
+	}
+	return nil
+}
+
+// pinnedID returns what to ask provider for in place of coin, if the coin
+// map says.
+func pinnedID(provider, coin string) (string, bool) {
+	id, ok := coinMap[coin][provider]
+	return id, ok
+}
+
+// coinProviderOff reports whether the coin map turns provider off for coin.
+func coinProviderOff(provider, coin string) bool {
+	id, ok := pinnedID(provider, coin)
+	return ok && id == coinMapOff
+}
+
+// mappedProvider asks the provider for the coin under the ID or symbol
+// pinned in the coin map, and returns the quote under the coin asked for.
+type mappedProvider struct {
+	pricefeed.Provider
+	name string
+}
+
+func (p mappedProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	id, ok := pinnedID(p.name, coin)
+	if !ok {
+		return p.Provider.Fetch(ctx, coin, currency)
+	}
+	logger.Debug("using pinned ID", "provider", p.name, "coin", coin, "id", id)
+	q, err := p.Provider.Fetch(ctx, id, currency)
+	q.Coin = coin
+	return q, err
+}
+
+var coinsMapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "Show the per-provider IDs pinned in coinmap.yaml",
+	Long: `Some coins go by different IDs or share a symbol across providers. Pin
+the one each provider should be asked for in coinmap.yaml in the config dir,
+keyed by CoinGecko coin ID, or set a provider to off to leave it out for
+that coin. A pinned coin ID is used as is, without symbol lookup:
+
+  pepe:
+    coingecko: pepe
+    binance: PEPE
+    cryptocompare: PEPE
+    kraken: off
+
+The file is reread on SIGHUP by serve, watch and record.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(coinMap) == 0 {
+			fmt.Printf("No coins pinned in %s\n", coinMapPath())
+			return nil
+		}
+		if outputFormat == "json" {
+			return printJSON(coinMap)
+		}
+		coins := make([]string, 0, len(coinMap))
+		for coin := range coinMap {
+			coins = append(coins, coin)
+		}
+		sort.Strings(coins)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COIN\tPROVIDER\tASKS FOR")
+		for _, coin := range coins {
+			names := make([]string, 0, len(coinMap[coin]))
+			for name := range coinMap[coin] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", coin, name, coinMap[coin][name])
+			}
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	coinsCmd.AddCommand(coinsMapCmd)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	coingeckoMarketsPageAPI = "/coins/markets?vs_currency=%s&order=%s&per_page=%d&page=%d"
+
+	// marketsPageSize is the most coins CoinGecko returns per page.
+	marketsPageSize = 250
+)
+
+var (
+	coinsTop      int
+	coinsPage     int
+	coinsSort     string
+	coinsFilter   string
+	coinsCurrency string
+)
+
+// marketOrders maps --sort to CoinGecko's server-side order; price and
+// change are sorted locally within the top coins by market cap.
+var marketOrders = map[string]string{
+	"market_cap": "market_cap_desc",
+	"volume":     "volume_desc",
+	"name":       "id_asc",
+	"price":      "market_cap_desc",
+	"change":     "market_cap_desc",
+}
+
+// fetchMarketPage returns coins [offset, offset+n) in CoinGecko's order,
+// requesting as many pages as needed, with their changes over periods.
+func fetchMarketPage(ctx context.Context, currency, order string, offset, n int, periods ...string) ([]coinMarket, error) {
+	var markets []coinMarket
+	for page := offset/marketsPageSize + 1; len(markets) < offset%marketsPageSize+n; page++ {
+		var batch []coinMarket
+		url := providerURL("coingecko") + fmt.Sprintf(coingeckoMarketsPageAPI, currency, order, marketsPageSize, page)
+		if len(periods) > 0 {
+			url += "&price_change_percentage=" + strings.Join(periods, ",")
+		}
+		if err := getJSON(ctx, url, "coingecko", &batch); err != nil {
+			return nil, err
+		}
+		markets = append(markets, batch...)
+		if len(batch) < marketsPageSize {
+			break
+		}
+	}
+	start := min(offset%marketsPageSize, len(markets))
+	return markets[start:min(start+n, len(markets))], nil
+}
+
+func sortMarkets(markets []coinMarket, by string) {
+	switch by {
+	case "price":
+		sort.SliceStable(markets, func(i, j int) bool { return markets[i].Price > markets[j].Price })
+	case "change":
+		sort.SliceStable(markets, func(i, j int) bool { return markets[i].PriceChange24h > markets[j].PriceChange24h })
+	}
+}
+
+func filterMarkets(markets []coinMarket, query string) []coinMarket {
+	if query == "" {
+		return markets
+	}
+	query = strings.ToLower(query)
+	var matched []coinMarket
+	for _, m := range markets {
+		if strings.Contains(m.ID, query) || strings.Contains(strings.ToLower(m.Symbol), query) || strings.Contains(strings.ToLower(m.Name), query) {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
+
+func printMarketsCSV(markets []coinMarket, currency string) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"rank", "id", "symbol", "name", "price", "currency", "change_24h", "market_cap"})
+	for _, m := range markets {
+		w.Write([]string{
+			strconv.Itoa(m.Rank),
+			m.ID,
+			m.Symbol,
+			m.Name,
+			strconv.FormatFloat(m.Price, 'f', -1, 64),
+			currency,
+			strconv.FormatFloat(m.PriceChange24h, 'f', 2, 64),
+			strconv.FormatFloat(m.MarketCap, 'f', 0, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func printMarketsTable(markets []coinMarket, currency string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	color := useColor(os.Stdout)
+	fmt.Fprintln(w, paintHeader("#\tSYMBOL\tNAME\tPRICE\t24H\tMARKET CAP", color, 4))
+	for _, m := range markets {
+		rank := "-"
+		if m.Rank > 0 {
+			rank = strconv.Itoa(m.Rank)
+		}
+		change := colored(fmt.Sprintf("%+.2f%%", m.PriceChange24h), m.PriceChange24h >= 0, color)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", rank, strings.ToUpper(m.Symbol), m.Name, formatPrice(m.Price, currency), change, formatVolume(m.MarketCap))
+	}
+	return w.Flush()
+}
+
+// marketFields are the columns --columns can pick for coins list.
+func marketFields(markets []coinMarket, currency string) []field {
+	color := useColor(os.Stdout)
+	return []field{
+		{"rank", "#", func(i int) string {
+			if markets[i].Rank <= 0 {
+				return "-"
+			}
+			return strconv.Itoa(markets[i].Rank)
+		}, func(i int) interface{} { return markets[i].Rank }},
+		{"id", "ID", func(i int) string { return markets[i].ID }, func(i int) interface{} { return markets[i].ID }},
+		{"symbol", "SYMBOL", func(i int) string { return strings.ToUpper(markets[i].Symbol) }, func(i int) interface{} { return markets[i].Symbol }},
+		{"name", "NAME", func(i int) string { return markets[i].Name }, func(i int) interface{} { return markets[i].Name }},
+		{"price", "PRICE", func(i int) string { return formatPrice(markets[i].Price, currency) }, func(i int) interface{} { return markets[i].Price }},
+		{"currency", "CURRENCY", func(i int) string { return strings.ToUpper(currency) }, func(i int) interface{} { return currency }},
+		{"change_24h", "24H", func(i int) string {
+			return colored(fmt.Sprintf("%+.2f%%", markets[i].PriceChange24h), markets[i].PriceChange24h >= 0, color)
+		}, func(i int) interface{} { return markets[i].PriceChange24h }},
+		{"market_cap", "MARKET CAP", func(i int) string { return formatVolume(markets[i].MarketCap) }, func(i int) interface{} { return markets[i].MarketCap }},
+		{"volume", "VOLUME", func(i int) string { return formatVolume(markets[i].Volume) }, func(i int) interface{} { return markets[i].Volume }},
+		{"supply", "SUPPLY", func(i int) string { return formatVolume(markets[i].CirculatingSupply) }, func(i int) interface{} { return markets[i].CirculatingSupply }},
+	}
+}
+
+var coinsCmd = &cobra.Command{
+	Use:   "coins",
+	Short: "Browse the coins CoinGecko tracks",
+}
+
+var coinsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the top coins by market cap, volume or name",
+	Long: `List coins from CoinGecko's markets data. --top sets the page size and
+--page selects which page, so --top 100 --page 2 lists coins 101 to 200.
+Sorting by price or change reorders the selected page; --filter keeps the
+coins on it whose ID, symbol or name contains the text.`,
+	Example: `  crypto-cli coins list --top 100 --sort market_cap
+  crypto-cli coins list --top 50 --sort change -c eur
+  crypto-cli coins list --top 250 --filter usd -o csv
+  crypto-cli coins list --columns symbol,price,change24h,volume`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		order, ok := marketOrders[coinsSort]
+		if !ok {
+			return fmt.Errorf("unknown sort %q (expected market_cap, volume, name, price or change)", coinsSort)
+		}
+		if coinsTop < 1 || coinsPage < 1 {
+			return fmt.Errorf("--top and --page must be at least 1")
+		}
+		currency := strings.ToLower(coinsCurrency)
+		markets, err := fetchMarketPage(cmd.Context(), currency, order, (coinsPage-1)*coinsTop, coinsTop)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		sortMarkets(markets, coinsSort)
+		markets = filterMarkets(markets, coinsFilter)
+
+		if len(selectedColumns) > 0 {
+			return printColumns(marketFields(markets, currency), len(markets))
+		}
+		switch outputFormat {
+		case "json":
+			if markets == nil {
+				markets = []coinMarket{}
+			}
+			return printJSON(markets)
+		case "csv":
+			return printMarketsCSV(markets, currency)
+		}
+		return printMarketsTable(markets, currency)
+	},
+}
+
+func init() {
+	coinsListCmd.Flags().IntVar(&coinsTop, "top", 100, "number of coins per page")
+	coinsListCmd.Flags().IntVar(&coinsPage, "page", 1, "page of --top coins to show")
+	coinsListCmd.Flags().StringVar(&coinsSort, "sort", "market_cap", "sort by market_cap, volume, name, price or change")
+	coinsListCmd.Flags().StringVar(&coinsFilter, "filter", "", "only show coins whose ID, symbol or name contains this text")
+	coinsListCmd.Flags().StringVarP(&coinsCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	coinsListCmd.Flags().StringSliceVar(&selectedColumns, "columns", nil, "columns to show, in order: rank, id, symbol, name, price, currency, change_24h, market_cap, volume, supply")
+	coinsCmd.AddCommand(coinsListCmd)
+	rootCmd.AddCommand(coinsCmd)
+}
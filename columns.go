@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// selectedColumns is --columns on the tabular commands.
+var selectedColumns []string
+
+// field is one column a tabular command can show. name is its --columns
+// name, CSV header and JSON key; cell renders row i for the table and
+// value for CSV and JSON.
+type field struct {
+	name   string
+	header string
+	cell   func(i int) string
+	value  func(i int) interface{}
+}
+
+// columnKey matches column names ignoring case, "_" and "-", so
+// change24h selects change_24h.
+func columnKey(name string) string {
+	return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// selectFields returns the fields named by --columns, in that order.
+func selectFields(fields []field, names []string) ([]field, error) {
+	selected := make([]field, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, f := range fields {
+			if columnKey(f.name) == columnKey(name) {
+				selected = append(selected, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			all := make([]string, len(fields))
+			for i, f := range fields {
+				all[i] = f.name
+			}
+			return nil, fmt.Errorf("unknown column %q: use %s", name, strings.Join(all, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// printColumns prints n rows of the fields named by --columns as a table,
+// CSV or JSON objects with the keys in column order.
+func printColumns(fields []field, n int) error {
+	fields, err := selectFields(fields, selectedColumns)
+	if err != nil {
+		return err
+	}
+	switch outputFormat {
+	case "json":
+		rows := make([]orderedRow, n)
+		for i := range rows {
+			rows[i] = orderedRow{fields, i}
+		}
+		return printJSON(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		record := make([]string, len(fields))
+		for j, f := range fields {
+			record[j] = f.name
+		}
+		w.Write(record)
+		for i := 0; i < n; i++ {
+			for j, f := range fields {
+				record[j] = csvValue(f.value(i))
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	cells := make([]string, len(fields))
+	for j, f := range fields {
+		cells[j] = f.header
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+	for i := 0; i < n; i++ {
+		for j, f := range fields {
+			cells[j] = f.cell(i)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
+
+// orderedRow marshals one row as a JSON object with its keys in column
+// order rather than sorted.
+type orderedRow struct {
+	fields []field
+	i      int
+}
+
+func (r orderedRow) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for j, f := range r.fields {
+		if j > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value(r.i))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
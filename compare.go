@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+var (
+	compareBaseline float64
+	compareDateFlag string
+	compareDate     time.Time
+)
+
+// Comparison is a quote's difference from the --compare baseline. Date is
+// set when the baseline is the historical price on --compare-date.
+type Comparison struct {
+	Baseline float64    `json:"baseline"`
+	Date     *time.Time `json:"date,omitempty"`
+	Diff     float64    `json:"diff"`
+	DiffPct  float64    `json:"diff_pct"`
+}
+
+func comparing() bool {
+	return compareBaseline > 0 || !compareDate.IsZero()
+}
+
+// checkCompare validates --compare and --compare-date.
+func checkCompare() error {
+	switch {
+	case compareBaseline < 0:
+		return errors.New(tr("CompareNotPositive", "--compare must be a positive price, got %g", compareBaseline))
+	case compareBaseline > 0 && compareDateFlag != "":
+		return errors.New(tr("CompareWithDate", "--compare and --compare-date cannot be combined"))
+	case compareDateFlag == "":
+		return nil
+	case !priceAt.IsZero():
+		return errors.New(tr("CompareDateWithAt", "--compare-date cannot be combined with --at"))
+	}
+	t, err := parseAt(compareDateFlag)
+	if err != nil {
+		return errors.New(tr("CompareInvalidDate", "invalid --compare-date %q: use YYYY-MM-DD or RFC 3339", compareDateFlag))
+	}
+	if t.After(time.Now()) {
+		return errors.New(tr("CompareDateInFuture", "--compare-date %s is in the future", t.Format(time.DateOnly)))
+	}
+	compareDate = t
+	return nil
+}
+
+func newComparison(price, baseline float64) *Comparison {
+	return &Comparison{Baseline: baseline, Diff: price - baseline, DiffPct: (price - baseline) / baseline * 100}
+}
+
+// attachComparisons sets each successful quote's difference from the
+// baseline. Historical baselines are looked up once per coin and currency;
+// like --change, a failed lookup only warns.
+func attachComparisons(ctx context.Context, quotes []CoinQuote) {
+	if compareBaseline > 0 {
+		for i := range quotes {
+			if quotes[i].err == nil {
+				quotes[i].Compare = newComparison(quotes[i].Price, compareBaseline)
+			}
+		}
+		return
+	}
+	if compareDate.IsZero() || offline {
+		return
+	}
+	baselines := make(map[string]CoinQuote)
+	for i := range quotes {
+		q := &quotes[i]
+		if q.err != nil {
+			continue
+		}
+		key := q.Coin + "/" + q.Currency
+		past, ok := baselines[key]
+		if !ok {
+			past = quoteCoinAt(ctx, q.Coin, q.Currency, compareDate)
+			baselines[key] = past
+			if past.err != nil && !quiet {
+				fmt.Fprint(os.Stderr, tr("CompareNoPastPrice", "Warning: no %s price for %s on %s: %v\n", q.Currency, q.Coin, compareDate.Format(time.DateOnly), past.err))
+			}
+		}
+		if past.err != nil || past.Price <= 0 {
+			continue
+		}
+		q.Compare = newComparison(q.Price, past.Price)
+		date := compareDate
+		q.Compare.Date = &date
+	}
+}
+
+// compareLabel names the baseline in table headers: the date for
+// --compare-date, otherwise the price.
+func compareLabel() string {
+	if !compareDate.IsZero() {
+		return "VS " + compareDate.Format(time.DateOnly)
+	}
+	return "VS " + localizeNumber(compareBaseline, -1)
+}
+
+// formatDiff renders the comparison as "+$7000.00 (+11.67%)", green above
+// the baseline and red below it.
+func formatDiff(c *Comparison, currency string, color bool) string {
+	sign := "+"
+	if c.Diff < 0 {
+		sign = "-"
+	}
+	return colorChange(fmt.Sprintf("%s%s (%+.2f%%)", sign, formatPrice(math.Abs(c.Diff), currency), c.DiffPct), c.Diff, color)
+}
+
+// formatComparison renders the comparison for a price line, e.g.
+// "vs $60000.00: +$7000.00 (+11.67%)".
+func formatComparison(q CoinQuote, color bool) string {
+	if q.Compare == nil {
+		return ""
+	}
+	baseline := formatPrice(q.Compare.Baseline, q.Currency)
+	if q.Compare.Date != nil {
+		baseline += " on " + q.Compare.Date.Format(time.DateOnly)
+	}
+	return "vs " + baseline + ": " + formatDiff(q.Compare, q.Currency, color)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const maxCompletions = 100
+
+// completeCoins suggests coins for shell completion: the user's default
+// coins, aliases and watchlists first, then IDs and symbols from the cached
+// registry. It never touches the network so completion stays instant.
+func completeCoins(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	loadConfig()
+	prefix := strings.ToLower(toComplete)
+	seen := make(map[string]bool)
+	var out []string
+	add := func(value, description string) {
+		if seen[value] || !strings.HasPrefix(strings.ToLower(value), prefix) || len(out) >= maxCompletions {
+			return
+		}
+		seen[value] = true
+		if description != "" {
+			value += "\t" + description
+		}
+		out = append(out, value)
+	}
+
+	for _, coin := range defaultCoins() {
+		add(coin, "default coin")
+	}
+	aliases := config.GetStringMapString("aliases")
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		add(alias, "alias for "+aliases[alias])
+	}
+	lists, _ := allWatchlists()
+	names = names[:0]
+	for name := range lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("@"+name, "watchlist: "+strings.Join(lists[name], ", "))
+	}
+
+	registry, err := readRegistry(registryPath())
+	if err != nil {
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+	for _, c := range registry.coins {
+		add(c.ID, fmt.Sprintf("%s (%s)", c.Name, strings.ToUpper(c.Symbol)))
+	}
+	for _, c := range registry.coins {
+		add(strings.ToLower(c.Symbol), c.Name)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFirstCoin completes only the first argument, for commands that
+// take a single coin or a coin followed by something else.
+func completeFirstCoin(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeCoins(cmd, args, toComplete)
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{rootCmd, dashboardCmd, recordCmd, recordExportCmd, reportCmd, spreadCmd, streamCmd, watchCmd} {
+		cmd.ValidArgsFunction = completeCoins
+	}
+	for _, cmd := range []*cobra.Command{alertCmd, eventsCmd, historyCmd, infoCmd, miningCmd, newsCmd, oiCmd, onchainCmd,
+		portfolioAddCmd, portfolioRemoveCmd, providersBenchmarkCmd, socialCmd, unlocksCmd} {
+		cmd.ValidArgsFunction = completeFirstCoin
+	}
+}
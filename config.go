@@ -0,0 +1,706 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configFile  string
+	config      = viper.New()
+	profileName string
+)
+
+func defaultConfigPath() string {
+	return filepath.Join(configDir(), "config.yaml")
+}
+
+// loadConfig reads the config file, if any, and overlays the selected
+// profile on top of the top-level settings. A missing file is not an error.
+func loadConfig() error {
+	path := configPath()
+	config.SetConfigFile(path)
+	config.SetConfigType("yaml")
+	if err := config.ReadInConfig(); err != nil {
+		if !errors.Is(err, os.ErrNotExist) || configFile != "" {
+			return fmt.Errorf("reading config %s: %w", path, err)
+		}
+	}
+
+	name := profileName
+	if name == "" {
+		name = os.Getenv(envName("profile"))
+	}
+	if name == "" {
+		name = config.GetString("profile")
+	}
+	if name == "" {
+		return nil
+	}
+	if !config.IsSet("profiles." + name) {
+		return fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	profileName = name
+	return config.MergeConfigMap(config.GetStringMap("profiles." + name))
+}
+
+// envName maps a flag or setting name to its environment variable, e.g.
+// "max-age" to CRYPTO_CLI_MAX_AGE.
+func envName(name string) string {
+	return "CRYPTO_CLI_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// settingAliases are the shorter names some flags also go by in
+// CRYPTO_CLI_* variables and the config's "default" section.
+var settingAliases = map[string]string{
+	"currency": "vs-currency",
+}
+
+// settingNames returns the flag's name followed by its aliases.
+func settingNames(flag string) []string {
+	names := []string{flag}
+	for alias, name := range settingAliases {
+		if name == flag {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// applyConfigDefaults fills every flag the user did not pass explicitly,
+// first from its CRYPTO_CLI_* environment variable and then from the
+// config's "default" section, so flags win over env and env over config.
+// A flag's own name wins over its aliases.
+func applyConfigDefaults(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+		names := settingNames(f.Name)
+		for _, name := range names {
+			if value, ok := os.LookupEnv(envName(name)); ok {
+				if err = setFlag(f, strings.Split(value, ",")); err != nil {
+					err = fmt.Errorf("invalid value for %s: %w", envName(name), err)
+				}
+				return
+			}
+		}
+		for _, name := range names {
+			if key := "default." + name; config.IsSet(key) {
+				if err = setFlag(f, config.GetStringSlice(key)); err != nil {
+					err = fmt.Errorf("invalid config value for %s: %w", key, err)
+				}
+				return
+			}
+		}
+	})
+	return err
+}
+
+func setFlag(f *pflag.Flag, values []string) error {
+	values = strings.Split(strings.Join(values, ","), ",")
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		return slice.Replace(values)
+	}
+	return f.Value.Set(strings.Join(values, ","))
+}
+
+type providerSettings struct {
+	Key       string        `mapstructure:"key"`
+	BaseURL   string        `mapstructure:"base_url"`
+	StreamURL string        `mapstructure:"stream_url"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	RateLimit int           `mapstructure:"rate_limit"`
+	Burst     int           `mapstructure:"burst"`
+	Enabled   *bool         `mapstructure:"enabled"`
+
+	Retries      *int          `mapstructure:"retries"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+func (s providerSettings) enabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// settingsFor returns the provider's block from the config's "providers"
+// section; any field left out keeps the built-in behavior.
+func settingsFor(name string) providerSettings {
+	var s providerSettings
+	config.UnmarshalKey("providers."+strings.ToLower(name), &s)
+	return s
+}
+
+func providerURL(name string) string {
+	if base := settingsFor(name).BaseURL; base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	if name == "coingecko" && coingeckoPro() {
+		return pricefeed.CoinGeckoProBaseURL
+	}
+	return providerBaseURLs[name]
+}
+
+func providerKey(name string) string {
+	name = strings.ToLower(name)
+	if key := keyFlags[name]; key != nil && *key != "" {
+		return *key
+	}
+	if key := os.Getenv(envName(name + "_key")); key != "" {
+		return key
+	}
+	if key := keyringKey(name); key != "" {
+		return key
+	}
+	if key := secrets[name]; key != "" {
+		return key
+	}
+	return config.GetString("providers." + name + ".key")
+}
+
+// resolveAlias maps a user-defined alias from the config's "aliases"
+// section to the coin it stands for.
+func resolveAlias(coin string) string {
+	if target := config.GetString("aliases." + strings.ToLower(coin)); target != "" {
+		return target
+	}
+	return coin
+}
+
+// defaultCoins returns the coins priced when none are given on the command
+// line, from CRYPTO_CLI_COINS or the config's default.coins.
+func defaultCoins() []string {
+	var coins []string
+	if value, ok := os.LookupEnv(envName("coins")); ok {
+		coins = strings.Split(value, ",")
+	} else {
+		coins = strings.Split(strings.Join(config.GetStringSlice("default.coins"), ","), ",")
+	}
+	var out []string
+	for _, c := range coins {
+		if c = strings.TrimSpace(c); c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func configPath() string {
+	if configFile != "" {
+		return configFile
+	}
+	if path := os.Getenv(envName("config")); path != "" {
+		return path
+	}
+	path := defaultConfigPath()
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if legacy := legacyConfigPath(); legacy != "" {
+			return legacy
+		}
+	}
+	return path
+}
+
+// legacyConfigPath returns ~/.crypto-cli.yaml if it exists. It is only
+// read when there is no config file in the config directory.
+func legacyConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(home, ".crypto-cli.yaml")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+func configure(cmd *cobra.Command, args []string) error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if err := applyConfigDefaults(cmd.Flags()); err != nil {
+		return err
+	}
+	if err := loadLocales(); err != nil {
+		return err
+	}
+	if err := setupLocale(); err != nil {
+		return err
+	}
+	if err := setupTimezone(); err != nil {
+		return err
+	}
+	if err := setupLogging(cmd); err != nil {
+		return err
+	}
+	if err := checkProxy(); err != nil {
+		return err
+	}
+	if err := checkFixtures(); err != nil {
+		return err
+	}
+	if err := checkCoinGeckoPlan(); err != nil {
+		return err
+	}
+	loadPlugins()
+	if err := loadCoinMap(); err != nil {
+		return err
+	}
+	if err := checkProviderNames(selectedProviders, "--providers"); err != nil {
+		return err
+	}
+	if err := checkProviderNames(excludedProviders, "--exclude-providers"); err != nil {
+		return err
+	}
+	if len(selectedProviders) > 0 {
+		priorityOrder = selectedProviders
+	}
+	var order []string
+	for _, name := range priorityOrder {
+		if !containsFold(excludedProviders, name) {
+			order = append(order, name)
+		}
+	}
+	priorityOrder = order
+	return loadSecrets()
+}
+
+const configTemplate = `# crypto-cli configuration.
+#
+# Settings under "default" provide values for any flag that is not passed on
+# the command line, using the flag name as the key. CRYPTO_CLI_* environment
+# variables override them.
+default:
+  # coins: [bitcoin, ethereum]  # priced when no coin is given
+  # vs-currency: [usd, eur]     # or currency
+  # output: text                # text, json or csv
+  # timeout: 5s
+  # retries: 2
+  # proxy: http://proxy.example.com:8080   # default: HTTPS_PROXY / HTTP_PROXY
+  # user-agent: my-dashboard/1.0
+  # insecure-skip-verify: false  # only for intercepting corporate proxies
+  # cache-ttl: 1m               # 0 disables the quote cache
+  # retry-backoff: 500ms
+  # aggregate: priority         # priority, first, mean, median, vwap or all
+  # priority: [coingecko, coinmarketcap, cryptocompare, binance, kraken, coinbase]
+  # providers: [coingecko, kraken]  # only query these, in this order
+  # exclude-providers: [coinbase]
+  # max-deviation: 5
+  # divergence-threshold: 2
+  # min-sources: 1
+  # max-age: 5m
+  # cmc-api-key: ""             # or set it under providers below
+  # coingecko-plan: pro         # for paid CoinGecko keys; demo keys need nothing
+  # lang: de                    # also reads <config dir>/locales/<lang>.yaml
+  # locale: de-DE               # number and currency format; auto reads LANG
+  # humanize: true              # $67.2k, $1.23T
+  # tz: Europe/Kyiv             # zone times are shown in; default local
+
+# Coin aliases, resolved before symbol lookup. To pin the ID each provider
+# is asked for, see coinmap.yaml ("crypto-cli coins map --help").
+aliases:
+  # btc: bitcoin
+  # doge: dogecoin
+
+# Per-provider settings. Every field is optional.
+providers:
+  # coingecko:
+  #   key: ""
+  #   base_url: https://api.coingecko.com/api/v3
+  #   timeout: 10s
+  #   rate_limit: 30     # requests per minute (CoinGecko defaults to 30, -1 disables)
+  #   burst: 5           # requests allowed at once before rate_limit applies
+  #   retries: 2         # on network errors, 429 and 5xx; Retry-After is honored
+  #   retry_backoff: 500ms
+  #   breaker_threshold: 5   # skip the provider after this many failures in a row
+  #   breaker_cooldown: 1m   # for this long, then try it again
+  #   enabled: true
+  # coinmarketcap:
+  #   key: ""          # required, or pass --cmc-api-key
+  # cryptocompare:
+  #   key: ""
+  # binance, kraken and coinbase need no key.
+  # binance:
+  #   stream_url: wss://stream.binance.com:9443   # used by "crypto-cli stream"
+  # fx:                # exchange rates for currencies a provider does not quote
+  #   base_url: https://open.er-api.com/v6
+
+# Where "report" and alerts are delivered.
+notifiers:
+  # slack:
+  #   webhook_url: https://hooks.slack.com/services/...
+  # telegram:
+  #   bot_token: ""      # or "crypto-cli keys set telegram"
+  #   chat_id: "123456"
+  # webhook:
+  #   url: https://example.com/hooks/crypto   # receives alert events as JSON
+  # email:
+  #   smtp_host: smtp.example.com
+  #   smtp_port: 587
+  #   username: ""
+  #   password: ""
+  #   from: crypto-cli@example.com
+  #   to: [me@example.com]
+
+# Run "crypto-cli config encrypt" to move the keys above into an
+# age-encrypted "secrets" section, unlocked with a passphrase or key file.
+
+# Holdings for "crypto-cli portfolio", kept in their own file.
+# portfolio:
+#   file: ~/.config/crypto-cli/portfolio.yaml
+
+# Fixed prices returned by --mock; other coins get seeded synthetic prices.
+# mock:
+#   prices:
+#     bitcoin: 65000
+
+# Watchlists used as @name, besides those saved with "crypto-cli watchlist".
+# watchlists:
+#   l1: [bitcoin, ethereum, solana]
+
+# Named profiles overlay the settings above. Select one with --profile, the
+# CRYPTO_CLI_PROFILE environment variable or "profile" below.
+# profile: work
+profiles:
+  # work:
+  #   default:
+  #     aggregate: mean
+  # trading:
+  #   default:
+  #     providers: [binance, kraken]
+  #     vs-currency: [usdt]
+  #   providers:
+  #     coinmarketcap:
+  #       key: ""
+  #   watchlists:
+  #     l1: [bitcoin, solana]
+`
+
+var configForce bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a commented config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configPath()
+		if _, err := os.Stat(path); err == nil && !configForce {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(configTemplate), 0o600); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\n", path)
+		return nil
+	},
+}
+
+// providerSettingKeys are the fields of a providers.<name> block; see
+// providerSettings.
+var providerSettingKeys = []string{"key", "base_url", "stream_url", "timeout", "rate_limit", "burst", "enabled",
+	"retries", "retry_backoff", "breaker_threshold", "breaker_cooldown"}
+
+// notifierSettingKeys are the fields of each notifiers.<name> block.
+var notifierSettingKeys = map[string][]string{
+	"slack":    {"webhook_url"},
+	"telegram": {"bot_token", "chat_id"},
+	"webhook":  {"url"},
+	"email":    {"smtp_host", "smtp_port", "username", "password", "from", "to"},
+}
+
+// lookupFlag finds a flag of any command by name, for default.<flag>.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if f := cmd.Flags().Lookup(name); f != nil {
+		return f
+	}
+	if f := cmd.PersistentFlags().Lookup(name); f != nil {
+		return f
+	}
+	for _, sub := range cmd.Commands() {
+		if f := lookupFlag(sub, name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// settingKey checks a dotted key against the settings crypto-cli reads and
+// returns it as it belongs in the file, with flag aliases translated. list
+// reports whether the setting holds a list.
+func settingKey(key string, inProfile bool) (canonical string, list bool, err error) {
+	parts := strings.Split(strings.ToLower(key), ".")
+	unknown := fmt.Errorf("unknown setting %q (\"crypto-cli config init\" writes a file listing them)", key)
+	switch parts[0] {
+	case "default":
+		if len(parts) != 2 {
+			return "", false, unknown
+		}
+		name := parts[1]
+		if flag, ok := settingAliases[name]; ok {
+			name = flag
+		}
+		f := lookupFlag(rootCmd, name)
+		if f == nil {
+			return "", false, fmt.Errorf("unknown setting %q: there is no --%s flag", key, name)
+		}
+		_, list = f.Value.(pflag.SliceValue)
+		return "default." + name, list, nil
+	case "profile":
+		if len(parts) == 1 && !inProfile {
+			return "profile", false, nil
+		}
+	case "profiles":
+		if len(parts) > 2 && !inProfile {
+			rest, list, err := settingKey(strings.Join(parts[2:], "."), true)
+			if err != nil {
+				return "", false, err
+			}
+			return "profiles." + parts[1] + "." + rest, list, nil
+		}
+	case "aliases":
+		if len(parts) == 2 {
+			return key, false, nil
+		}
+	case "watchlists":
+		if len(parts) == 2 {
+			return key, true, nil
+		}
+	case "providers":
+		if len(parts) == 3 && containsFold(providerSettingKeys, parts[2]) {
+			if _, ok := providerBaseURLs[parts[1]]; !ok {
+				if err := checkProviderNames(parts[1:2], key); err != nil {
+					return "", false, err
+				}
+			}
+			return key, false, nil
+		}
+	case "notifiers":
+		if len(parts) == 3 && containsFold(notifierSettingKeys[parts[1]], parts[2]) {
+			return key, key == "notifiers.email.to", nil
+		}
+	case "portfolio":
+		if key == "portfolio.file" {
+			return key, false, nil
+		}
+	case "mock":
+		if len(parts) == 3 && parts[1] == "prices" {
+			return key, false, nil
+		}
+	}
+	return "", false, unknown
+}
+
+// setYAMLValue sets the dotted path in a YAML document to value, adding
+// the mappings on the way as needed. Comments in the document are kept.
+func setYAMLValue(doc *yaml.Node, path []string, value *yaml.Node) error {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		*doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	node := doc.Content[0]
+	for i, name := range path {
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			// A section with everything under it commented out.
+			node.Kind, node.Tag, node.Value = yaml.MappingNode, "!!map", ""
+		}
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a section", strings.Join(path[:i], "."))
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, name) {
+				next = node.Content[j+1]
+				break
+			}
+		}
+		if i == len(path)-1 {
+			if next != nil {
+				value.HeadComment, value.LineComment, value.FootComment = next.HeadComment, next.LineComment, next.FootComment
+				*next = *value
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, value)
+			}
+			return nil
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}, next)
+		}
+		node = next
+	}
+	return nil
+}
+
+// yamlValue is the node written for a value given on the command line:
+// a plain scalar, typed when the file is read, or for lists a flow
+// sequence of the comma-separated items.
+func yamlValue(value string, list bool) *yaml.Node {
+	if !list {
+		var n yaml.Node
+		if yaml.Unmarshal([]byte(value), &n) == nil && len(n.Content) == 1 && n.Content[0].Kind == yaml.ScalarNode {
+			n.Content[0].Line, n.Content[0].Column = 0, 0
+			return n.Content[0]
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			seq.Content = append(seq.Content, yamlValue(item, false))
+		}
+	}
+	return seq
+}
+
+// restoreCommentLayout indents each comment line of out as it was in
+// original, and puts back the blank line before it. The YAML encoder moves
+// a comment to the indentation of the key it precedes, which flattens the
+// commented-out examples in a section.
+func restoreCommentLayout(original, out []byte) []byte {
+	type layout struct {
+		indent      string
+		blankBefore bool
+	}
+	comments := make(map[string][]layout)
+	prev := ""
+	for _, line := range strings.Split(string(original), "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "#") {
+			comments[trimmed] = append(comments[trimmed], layout{line[:strings.Index(line, "#")], prev == ""})
+		}
+		prev = strings.TrimSpace(line)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if l := comments[trimmed]; strings.HasPrefix(trimmed, "#") && len(l) > 0 {
+			if l[0].blankBefore && len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+				lines = append(lines, "")
+			}
+			line = l[0].indent + trimmed
+			comments[trimmed] = l[1:]
+		}
+		lines = append(lines, line)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a value in the config file, e.g. default.aggregate mean",
+	Long: `Set a value in the config file, keeping its comments. Keys are the
+settings "config init" lists: default.<flag> for any flag (default.currency
+for default.vs-currency), profile, profiles.<name>.<key>, aliases.<coin>,
+watchlists.<name>, providers.<name>.<field>, notifiers.<name>.<field>,
+portfolio.file and mock.prices.<coin>. Separate list items with commas.`,
+	Example: `  crypto-cli config set default.currency eur
+  crypto-cli config set default.providers coingecko,kraken
+  crypto-cli config set providers.coingecko.timeout 10s
+  crypto-cli config set profiles.work.default.aggregate mean`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, list, err := settingKey(args[0], false)
+		if err != nil {
+			return err
+		}
+		path := configPath()
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reading config %s: %w", path, err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("reading config %s: %w", path, err)
+		}
+		if err := setYAMLValue(&doc, strings.Split(key, "."), yamlValue(args[1], list)); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		var out bytes.Buffer
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, restoreCommentLayout(data, out.Bytes()), 0o600)
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get [key]",
+	Short: "Print the effective configuration or a single value",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var value interface{} = config.AllSettings()
+		if len(args) == 1 {
+			key := args[0]
+			if canonical, _, err := settingKey(key, false); err == nil {
+				key = canonical
+			}
+			if !config.IsSet(key) {
+				return fmt.Errorf("%s is not set", args[0])
+			}
+			value = config.Get(key)
+		}
+		if s, ok := value.(string); ok {
+			fmt.Println(s)
+			return nil
+		}
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	},
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the profiles defined in the config file",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		names := make([]string, 0)
+		for name := range config.GetStringMap("profiles") {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			marker := " "
+			if name == profileName {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+	},
+}
+
+func init() {
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite an existing config file")
+	configCmd.AddCommand(configInitCmd, configSetCmd, configGetCmd, configProfilesCmd)
+	rootCmd.AddCommand(configCmd)
+}
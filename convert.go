@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+// fiatCurrencies are the currency codes convert treats as fiat rather than
+// as coin symbols.
+var fiatCurrencies = map[string]bool{
+	"usd": true, "eur": true, "gbp": true, "jpy": true, "chf": true, "cad": true,
+	"aud": true, "nzd": true, "cny": true, "hkd": true, "sgd": true, "krw": true,
+	"inr": true, "brl": true, "mxn": true, "try": true, "rub": true, "zar": true,
+	"sek": true, "nok": true, "dkk": true, "pln": true, "czk": true, "uah": true,
+}
+
+type Conversion struct {
+	Amount json.Number `json:"amount"`
+	From   string      `json:"from"`
+	To     string      `json:"to"`
+	Result json.Number `json:"result"`
+	Rate   json.Number `json:"rate"`
+	Quotes []CoinQuote `json:"quotes"`
+}
+
+// convertSide is one end of a conversion: a fiat currency or a coin ID.
+type convertSide struct {
+	code string
+	fiat bool
+}
+
+func parseConvertSide(ctx context.Context, arg string) (convertSide, error) {
+	code := strings.ToLower(arg)
+	if fiatCurrencies[code] {
+		return convertSide{code, true}, nil
+	}
+	id, err := resolveCoin(ctx, code, exactID)
+	return convertSide{id, false}, err
+}
+
+func (s convertSide) label() string {
+	if s.fiat {
+		return strings.ToUpper(s.code)
+	}
+	return strings.ToUpper(coinSymbol(s.code))
+}
+
+// convertRate returns how many units of to one unit of from is worth, with
+// the quotes it was computed from. Coins are priced in the fiat side, or
+// both in USD when converting between two coins.
+func convertRate(ctx context.Context, from, to convertSide) (pricefeed.Decimal, []CoinQuote, error) {
+	var none pricefeed.Decimal
+	quote := func(coin, currency string) (pricefeed.Decimal, CoinQuote, error) {
+		q := quoteCoin(ctx, coin, currency)
+		if q.err != nil {
+			return none, q, q.err
+		}
+		return pricefeed.NewDecimal(q.Price), q, nil
+	}
+
+	switch {
+	case from.fiat && to.fiat:
+		if from.code == to.code {
+			return pricefeed.NewDecimal(1), nil, nil
+		}
+		return none, nil, errors.New(tr("ConvertFiatToFiat", "converting between two fiat currencies is not supported"))
+	case to.fiat:
+		price, q, err := quote(from.code, to.code)
+		return price, []CoinQuote{q}, err
+	case from.fiat:
+		price, q, err := quote(to.code, from.code)
+		if err != nil {
+			return none, nil, err
+		}
+		return pricefeed.NewDecimal(1).Quo(price), []CoinQuote{q}, nil
+	}
+	fromPrice, fromQuote, err := quote(from.code, "usd")
+	if err != nil {
+		return none, nil, err
+	}
+	toPrice, toQuote, err := quote(to.code, "usd")
+	if err != nil {
+		return none, nil, err
+	}
+	return fromPrice.Quo(toPrice), []CoinQuote{fromQuote, toQuote}, nil
+}
+
+// formatAmount prints fiat amounts to the cent and coin amounts to eight
+// decimals without trailing zeros.
+func formatAmount(v pricefeed.Decimal, fiat bool) string {
+	if fiat {
+		return v.StringFixed(2)
+	}
+	return trimZeros(v.StringFixed(8))
+}
+
+// formatRate prints the rate in plain notation to ten significant digits.
+func formatRate(v pricefeed.Decimal) string {
+	f := v.Float64()
+	digits := 9
+	if f > 0 {
+		digits = max(0, 9-int(math.Floor(math.Log10(f))))
+	}
+	return trimZeros(v.StringFixed(digits))
+}
+
+func trimZeros(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <amount> <from> <to>",
+	Short: "Convert an amount between two coins or between a coin and a fiat currency",
+	Example: `  crypto-cli convert 0.5 btc eth
+  crypto-cli convert 1500 usd btc
+  crypto-cli convert 2 eth eur -o json`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		amount, err := pricefeed.ParseDecimal(args[0])
+		if err != nil || amount.Sign() < 0 {
+			return errors.New(tr("InvalidAmount", "invalid amount %q", args[0]))
+		}
+		from, err := parseConvertSide(cmd.Context(), args[1])
+		if err != nil {
+			return err
+		}
+		to, err := parseConvertSide(cmd.Context(), args[2])
+		if err != nil {
+			return err
+		}
+
+		rate, quotes, err := convertRate(cmd.Context(), from, to)
+		if err != nil {
+			return err
+		}
+		result := amount.Mul(rate)
+
+		if outputFormat == "json" {
+			if quotes == nil {
+				quotes = []CoinQuote{}
+			}
+			return printJSON(Conversion{
+				Amount: json.Number(amount.String()),
+				From:   from.code,
+				To:     to.code,
+				Result: json.Number(result.String()),
+				Rate:   json.Number(rate.String()),
+				Quotes: quotes,
+			})
+		}
+		fmt.Printf("%s %s = %s %s\n", formatAmount(amount, from.fiat), from.label(), formatAmount(result, to.fiat), to.label())
+		fmt.Print(tr("ConvertRate", "Rate: 1 %s = %s %s", from.label(), formatRate(rate), to.label()))
+		for i, q := range quotes {
+			sep := " ("
+			if i > 0 {
+				sep = ", "
+			}
+			fmt.Print(sep + tr("ConvertQuote", "%s %s from %s", q.Coin, formatPrice(q.Price, q.Currency), q.Source))
+		}
+		if len(quotes) > 0 {
+			fmt.Print(")")
+		}
+		fmt.Println()
+		return nil
+	},
+}
+
+func init() {
+	convertCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat coin arguments as CoinGecko coin IDs and skip symbol resolution")
+	rootCmd.AddCommand(convertCmd)
+}
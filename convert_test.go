@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"cli-crypto-price/pricefeed"
+)
+
+func TestFormatAmount(t *testing.T) {
+	d := func(s string) pricefeed.Decimal {
+		v, _ := pricefeed.ParseDecimal(s)
+		return v
+	}
+	tests := []struct {
+		amount string
+		fiat   bool
+		want   string
+	}{
+		{"1500", true, "1500.00"},
+		{"0.125", true, "0.13"},
+		{"0.5", false, "0.5"},
+		{"9.571428571428571", false, "9.57142857"},
+		{"0.000000004", false, "0"},
+		{"0.000000005", false, "0.00000001"},
+		{"3", false, "3"},
+	}
+	for _, tt := range tests {
+		if got := formatAmount(d(tt.amount), tt.fiat); got != tt.want {
+			t.Errorf("formatAmount(%s, fiat %v) = %s, want %s", tt.amount, tt.fiat, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		rate pricefeed.Decimal
+		want string
+	}{
+		{pricefeed.NewDecimal(67000).Quo(pricefeed.NewDecimal(3500)), "19.14285714"},
+		{pricefeed.NewDecimal(1).Quo(pricefeed.NewDecimal(67000)), "0.00001492537313"},
+		{pricefeed.NewDecimal(1), "1"},
+		{pricefeed.NewDecimal(1234567.891), "1234567.891"},
+		{pricefeed.Decimal{}, "0"},
+	}
+	for _, tt := range tests {
+		if got := formatRate(tt.rate); got != tt.want {
+			t.Errorf("formatRate(%s) = %s, want %s", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestConvertRateFiat(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+		err      bool
+	}{
+		{"usd", "usd", "1", false},
+		{"EUR", "eur", "1", false},
+		{"usd", "eur", "", true},
+	}
+	for _, tt := range tests {
+		from, err := parseConvertSide(context.Background(), tt.from)
+		if err != nil || !from.fiat {
+			t.Fatalf("parseConvertSide(%q) = %+v, %v", tt.from, from, err)
+		}
+		to, _ := parseConvertSide(context.Background(), tt.to)
+		rate, quotes, err := convertRate(context.Background(), from, to)
+		if (err != nil) != tt.err {
+			t.Errorf("%s to %s: err = %v, want error: %v", tt.from, tt.to, err, tt.err)
+			continue
+		}
+		if !tt.err && (rate.String() != tt.want || quotes != nil) {
+			t.Errorf("%s to %s: rate %s with %d quotes, want %s", tt.from, tt.to, rate, len(quotes), tt.want)
+		}
+	}
+}
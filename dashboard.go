@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	coingeckoDashboardAPI = "/coins/markets?vs_currency=%s&ids=%s&sparkline=true&price_change_percentage=24h"
+
+	// sparklineWidth is how many blocks the 7-day sparkline is drawn with.
+	sparklineWidth = 24
+)
+
+var (
+	dashboardCurrency string
+	dashboardInterval time.Duration
+)
+
+// dashboardSorts is the order the "s" key cycles through.
+var dashboardSorts = []string{"rank", "price", "change", "name"}
+
+type dashboardMarket struct {
+	coinMarket
+	Sparkline struct {
+		Price []float64 `json:"price"`
+	} `json:"sparkline_in_7d"`
+}
+
+func fetchDashboard(ctx context.Context, coins []string, currency string) ([]dashboardMarket, error) {
+	if len(coins) == 0 {
+		return nil, nil
+	}
+	var markets []dashboardMarket
+	url := providerURL("coingecko") + fmt.Sprintf(coingeckoDashboardAPI, currency, strings.Join(coins, ","))
+	err := getJSON(ctx, url, "coingecko", &markets)
+	return markets, err
+}
+
+func sortDashboard(markets []dashboardMarket, by string) {
+	sort.SliceStable(markets, func(i, j int) bool {
+		a, b := markets[i], markets[j]
+		switch by {
+		case "price":
+			return a.Price > b.Price
+		case "change":
+			return a.PriceChange24h > b.PriceChange24h
+		case "name":
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+		if a.Rank == 0 || b.Rank == 0 {
+			return a.Rank != 0
+		}
+		return a.Rank < b.Rank
+	})
+}
+
+func marketSparkline(prices []float64) string {
+	if len(prices) == 0 {
+		return ""
+	}
+	points := make([]PricePoint, len(prices))
+	for i, p := range prices {
+		points[i].Price = p
+	}
+	return sparkline(candles(points, sparklineWidth))
+}
+
+// dashboard is the state of the dashboard between redraws.
+type dashboard struct {
+	coins    []string
+	currency string
+	markets  []dashboardMarket
+	sortBy   int
+	selected int
+	updated  time.Time
+	status   string
+	input    *string // the coin being typed after "a", if any
+}
+
+func (d *dashboard) sortKey() string { return dashboardSorts[d.sortBy] }
+
+func (d *dashboard) refresh(ctx context.Context) {
+	markets, err := fetchDashboard(ctx, d.coins, d.currency)
+	if err != nil {
+		d.status = tr("TableError", "error: %v", err)
+		return
+	}
+	sortDashboard(markets, d.sortKey())
+	d.markets, d.updated, d.status = markets, time.Now(), ""
+	d.selected = min(d.selected, max(len(d.markets)-1, 0))
+}
+
+func (d *dashboard) add(ctx context.Context, arg string) {
+	coin, err := resolveCoin(ctx, strings.TrimSpace(arg), exactID)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	if containsFold(d.coins, coin) {
+		d.status = fmt.Sprintf("%s is already on the dashboard", coin)
+		return
+	}
+	d.coins = append(d.coins, coin)
+	d.refresh(ctx)
+}
+
+func (d *dashboard) remove(coin string) {
+	for i, c := range d.coins {
+		if c == coin {
+			d.coins = append(d.coins[:i], d.coins[i+1:]...)
+			break
+		}
+	}
+	for i, m := range d.markets {
+		if m.ID == coin {
+			d.markets = append(d.markets[:i], d.markets[i+1:]...)
+			break
+		}
+	}
+	d.selected = min(d.selected, max(len(d.markets)-1, 0))
+}
+
+// table renders the coins; sparklines are left out in the --accessible
+// form, and color also with --no-color or NO_COLOR.
+func (d *dashboard) table(color, spark, cursor bool) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	header := "  #\tSYMBOL\tNAME\tPRICE\t24H\tMARKET CAP"
+	if color {
+		// Wrap the header in codes as long as colored's, so tabwriter
+		// pads it like the colored cells below.
+		header = "  #\tSYMBOL\tNAME\tPRICE\t\033[39m24H\033[0m\tMARKET CAP"
+	}
+	if spark {
+		header += "\t7D"
+	}
+	fmt.Fprintln(w, header)
+	for i, m := range d.markets {
+		marker := "  "
+		if cursor && i == d.selected {
+			marker = "> "
+		}
+		rank := "-"
+		if m.Rank > 0 {
+			rank = fmt.Sprint(m.Rank)
+		}
+		change := colored(fmt.Sprintf("%+.2f%%", m.PriceChange24h), m.PriceChange24h >= 0, color)
+		fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s\t%s", marker, rank, strings.ToUpper(m.Symbol), m.Name, formatPrice(m.Price, d.currency), change, formatVolume(m.MarketCap))
+		if spark {
+			prices := m.Sparkline.Price
+			up := len(prices) < 2 || prices[len(prices)-1] >= prices[0]
+			fmt.Fprintf(w, "\t%s", colored(marketSparkline(prices), up, color))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return b.String()
+}
+
+func (d *dashboard) render(width int) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	b.WriteString(strings.ReplaceAll(d.table(useColor(os.Stdout), true, true), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	switch {
+	case d.input != nil:
+		b.WriteString("Add coin: " + *d.input)
+	case d.status != "":
+		b.WriteString(strings.ReplaceAll(d.status, "\n", "\r\n"))
+	default:
+		footer := fmt.Sprintf("Sorted by %s, updated %s, every %s", d.sortKey(), d.updated.Format("15:04:05"), dashboardInterval)
+		b.WriteString("\x1b[2m" + footer + "\x1b[0m")
+	}
+	help := "↑/↓ move  s sort  a add  d remove  r refresh  q quit"
+	if len(help) < width {
+		b.WriteString("\r\n\x1b[2m" + help + "\x1b[0m")
+	}
+	fmt.Print(b.String())
+}
+
+// runDashboard draws the dashboard full screen and reads single keys from
+// the terminal until q, Esc or Ctrl-C.
+func runDashboard(ctx context.Context, d *dashboard) error {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan []byte)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+
+	ticker := time.NewTicker(dashboardInterval)
+	defer ticker.Stop()
+	d.status = "Loading..."
+	d.render(terminalWidth())
+	d.refresh(ctx)
+	for {
+		d.render(terminalWidth())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			d.refresh(ctx)
+		case input, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			if d.input != nil {
+				switch {
+				case input[0] == '\r' || input[0] == '\n':
+					coin := *d.input
+					d.input = nil
+					if coin != "" {
+						d.add(ctx, coin)
+					}
+				case input[0] == 0x1b || input[0] == 3:
+					d.input = nil
+				case input[0] == 127 || input[0] == 8:
+					if s := *d.input; s != "" {
+						*d.input = s[:len(s)-1]
+					}
+				default:
+					for _, b := range input {
+						if b > 32 && b < 127 {
+							*d.input += string(b)
+						}
+					}
+				}
+				continue
+			}
+			d.status = ""
+			switch {
+			case len(input) >= 3 && input[0] == 0x1b && input[1] == '[':
+				switch input[2] {
+				case 'A':
+					d.selected = max(d.selected-1, 0)
+				case 'B':
+					d.selected = min(d.selected+1, max(len(d.markets)-1, 0))
+				}
+			case input[0] == 'q' || input[0] == 0x1b || input[0] == 3:
+				return nil
+			case input[0] == 'k':
+				d.selected = max(d.selected-1, 0)
+			case input[0] == 'j':
+				d.selected = min(d.selected+1, max(len(d.markets)-1, 0))
+			case input[0] == 's':
+				d.sortBy = (d.sortBy + 1) % len(dashboardSorts)
+				sortDashboard(d.markets, d.sortKey())
+			case input[0] == 'r':
+				d.refresh(ctx)
+			case input[0] == 'a':
+				empty := ""
+				d.input = &empty
+			case input[0] == 'd' || input[0] == 'x':
+				if d.selected < len(d.markets) {
+					d.remove(d.markets[d.selected].ID)
+				}
+			}
+		}
+	}
+}
+
+// runDashboardLinear is the --accessible and non-terminal variant: it
+// prints the table on every refresh and reads commands a line at a time.
+func runDashboardLinear(ctx context.Context, d *dashboard) error {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	ticker := time.NewTicker(dashboardInterval)
+	defer ticker.Stop()
+	show := func() {
+		d.refresh(ctx)
+		if d.status != "" {
+			fmt.Println(d.status)
+		}
+		fmt.Print(d.table(false, false, false))
+		fmt.Printf("Sorted by %s, updated %s. Commands: add <coin>, remove <coin>, sort %s, refresh, quit\n\n",
+			d.sortKey(), d.updated.Format("15:04:05"), strings.Join(dashboardSorts, "|"))
+	}
+	show()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			show()
+		case line, ok := <-lines:
+			if !ok {
+				lines = nil
+				continue
+			}
+			command, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch command {
+			case "":
+				continue
+			case "quit", "q", "exit":
+				return nil
+			case "add", "a":
+				d.add(ctx, arg)
+			case "remove", "rm", "d":
+				coin, err := resolveCoin(ctx, strings.TrimSpace(arg), exactID)
+				if err != nil {
+					fmt.Println(err)
+					continue
+				}
+				d.remove(coin)
+			case "sort", "s":
+				for i, s := range dashboardSorts {
+					if s == strings.TrimSpace(arg) {
+						d.sortBy = i
+					}
+				}
+			case "refresh", "r":
+			default:
+				fmt.Printf("unknown command %q\n", command)
+				continue
+			}
+			show()
+		}
+	}
+}
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard [coin...]",
+	Short: "Interactive, auto-refreshing table of coins with 24h change and 7-day sparklines",
+	Long: `Show a full-screen table of coins that refreshes itself, with the 24h change
+colored and a sparkline of the last 7 days. Keys: up/down or j/k move, s
+cycles the sort column, a adds a coin, d removes the selected one, r
+refreshes now and q quits.
+
+With --accessible, or when not on a terminal, the table is printed after
+each refresh instead and commands such as "add sol" are read line by line.`,
+	Example: `  crypto-cli dashboard btc eth sol
+  crypto-cli dashboard -c eur --interval 1m`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if dashboardInterval < minWatchInterval {
+			return errors.New(tr("IntervalTooShort", "--interval must be at least %s", minWatchInterval))
+		}
+		if len(args) == 0 {
+			args = []string{"bitcoin", "ethereum"}
+		}
+		args, err := expandWatchlists(args)
+		if err != nil {
+			return err
+		}
+		d := &dashboard{currency: strings.ToLower(dashboardCurrency)}
+		for _, arg := range args {
+			coin, err := resolveCoin(cmd.Context(), arg, exactID)
+			if err != nil {
+				return err
+			}
+			if !containsFold(d.coins, coin) {
+				d.coins = append(d.coins, coin)
+			}
+		}
+		allowPrompt = false
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if accessible || !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+			return runDashboardLinear(ctx, d)
+		}
+		return runDashboard(ctx, d)
+	},
+}
+
+func init() {
+	dashboardCmd.Flags().StringVarP(&dashboardCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	dashboardCmd.Flags().DurationVarP(&dashboardInterval, "interval", "i", 30*time.Second, "time between refreshes")
+	dashboardCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat arguments as CoinGecko coin IDs and skip symbol resolution")
+	rootCmd.AddCommand(dashboardCmd)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+const priceSchema = `
+CREATE TABLE IF NOT EXISTS prices (
+	time     INTEGER NOT NULL,
+	coin     TEXT    NOT NULL,
+	currency TEXT    NOT NULL,
+	price    REAL    NOT NULL,
+	source   TEXT    NOT NULL DEFAULT '',
+	PRIMARY KEY (coin, currency, time)
+)`
+
+const portfolioSchema = `
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+	time       INTEGER NOT NULL,
+	portfolio  TEXT    NOT NULL,
+	currency   TEXT    NOT NULL,
+	value      REAL    NOT NULL,
+	cost_basis REAL    NOT NULL DEFAULT 0,
+	pnl        REAL    NOT NULL DEFAULT 0,
+	PRIMARY KEY (portfolio, currency, time)
+)`
+
+// openDB opens the SQLite database at path, creating it and the tables
+// it needs if necessary.
+func openDB(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	for _, schema := range []string{priceSchema, portfolioSchema} {
+		if _, err := db.Exec(schema); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dcaAmount   float64
+	dcaEvery    string
+	dcaSince    string
+	dcaUntil    string
+	dcaFee      float64
+	dcaCurrency string
+)
+
+// dcaSteps advances a buy date by the --every interval.
+var dcaSteps = map[string]func(time.Time) time.Time{
+	"day":   func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	"week":  func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	"2week": func(t time.Time) time.Time { return t.AddDate(0, 0, 14) },
+	"month": func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+}
+
+var dcaAliases = map[string]string{
+	"daily":     "day",
+	"weekly":    "week",
+	"biweekly":  "2week",
+	"fortnight": "2week",
+	"monthly":   "month",
+}
+
+type DCABuy struct {
+	Time   time.Time `json:"time"`
+	Price  float64   `json:"price"`
+	Amount float64   `json:"amount"`
+	Coins  float64   `json:"coins"`
+}
+
+type DCAReport struct {
+	Coin      string    `json:"coin"`
+	Currency  string    `json:"currency"`
+	Every     string    `json:"every"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+	Invested  float64   `json:"invested"`
+	Coins     float64   `json:"coins"`
+	AvgCost   float64   `json:"average_cost"`
+	Price     float64   `json:"price"`
+	Value     float64   `json:"value"`
+	Return    float64   `json:"return_pct"`
+	Buys      []DCABuy  `json:"buys"`
+	ValueNote string    `json:"-"`
+}
+
+// simulateDCA buys amount of the coin at the first price at or after each
+// buy date, less the fee percentage.
+func simulateDCA(points []PricePoint, from, to time.Time, step func(time.Time) time.Time) []DCABuy {
+	var buys []DCABuy
+	for t := from; !t.After(to); t = step(t) {
+		i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(t) })
+		if i == len(points) {
+			break
+		}
+		p := points[i]
+		if p.Price <= 0 {
+			continue
+		}
+		buys = append(buys, DCABuy{Time: t, Price: p.Price, Amount: dcaAmount, Coins: dcaAmount * (1 - dcaFee/100) / p.Price})
+	}
+	return buys
+}
+
+func printDCACSV(report DCAReport) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"time", "price", "currency", "amount", "coins", "total_coins", "total_invested"})
+	var coins, invested float64
+	for _, b := range report.Buys {
+		coins += b.Coins
+		invested += b.Amount
+		w.Write([]string{
+			b.Time.Format(time.DateOnly),
+			strconv.FormatFloat(b.Price, 'f', -1, 64),
+			report.Currency,
+			strconv.FormatFloat(b.Amount, 'f', -1, 64),
+			strconv.FormatFloat(b.Coins, 'f', -1, 64),
+			strconv.FormatFloat(coins, 'f', -1, 64),
+			strconv.FormatFloat(invested, 'f', -1, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func printDCAReport(report DCAReport) {
+	color := useColor(os.Stdout)
+	fmt.Print(tr("DCAHeading", "%s buying %s of %s every %s, %s to %s\n", strings.ToUpper(report.Currency), formatPrice(dcaAmount, report.Currency),
+		report.Coin, report.Every, report.From.Format(time.DateOnly), report.To.Format(time.DateOnly)))
+	fmt.Print(tr("DCABuys", "  Buys:          %d\n", len(report.Buys)))
+	fmt.Print(tr("DCAInvested", "  Invested:      %s\n", formatPrice(report.Invested, report.Currency)))
+	fmt.Print(tr("DCAAccumulated", "  Accumulated:   %s\n", strconv.FormatFloat(report.Coins, 'f', -1, 64)))
+	fmt.Print(tr("DCAAverageCost", "  Average cost:  %s\n", formatPrice(report.AvgCost, report.Currency)))
+	fmt.Print(tr("DCACurrentValue", "  Current value: %s at %s%s\n", formatPrice(report.Value, report.Currency), formatPrice(report.Price, report.Currency), report.ValueNote))
+	fmt.Print(tr("DCAReturn", "  Return:        %s (%s)\n",
+		colorChange(fmt.Sprintf("%+.2f%%", report.Return), report.Return, color),
+		colorChange(signedPrice(report.Value-report.Invested, report.Currency), report.Return, color)))
+}
+
+func signedPrice(v float64, currency string) string {
+	if v < 0 {
+		return "-" + formatPrice(-v, currency)
+	}
+	return "+" + formatPrice(v, currency)
+}
+
+var dcaCmd = &cobra.Command{
+	Use:   "dca <coin>",
+	Short: "Work out what a recurring buy of a coin would be worth today",
+	Long: `Simulate dollar-cost averaging: buy --amount of the coin every day, week,
+2week or month from --since to --until at each day's historical price, and
+report the coins accumulated, the total invested, the current value and the
+return. --fee takes a percentage off each buy.`,
+	Example: `  crypto-cli dca bitcoin --amount 100 --every week --since 2023-01-01
+  crypto-cli dca ethereum --amount 50 --every month --since 2024-01-01 -c eur
+  crypto-cli dca solana --amount 10 --every day --since 2025-06-01 -o csv`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstCoin,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		every := strings.ToLower(dcaEvery)
+		if alias, ok := dcaAliases[every]; ok {
+			every = alias
+		}
+		step, ok := dcaSteps[every]
+		if !ok {
+			return errors.New(tr("DCAUnknownEvery", "unknown --every %q (expected day, week, 2week or month)", dcaEvery))
+		}
+		if dcaAmount <= 0 {
+			return errors.New(tr("DCAAmountNotPositive", "--amount must be positive"))
+		}
+		if dcaFee < 0 || dcaFee >= 100 {
+			return errors.New(tr("DCAInvalidFee", "--fee must be a percentage from 0 to under 100"))
+		}
+		from, err := parseDate(dcaSince)
+		if err != nil {
+			return err
+		}
+		to := time.Now().UTC().Truncate(time.Second)
+		if dcaUntil != "" {
+			if to, err = parseDate(dcaUntil); err != nil {
+				return err
+			}
+		}
+		if !from.Before(to) {
+			return errors.New(tr("SinceNotBeforeUntil", "--since must be before --until"))
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		currency := strings.ToLower(dcaCurrency)
+
+		points, err := fetchHistory(cmd.Context(), coin, currency, from, to)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		points = dailyPoints(points)
+		buys := simulateDCA(points, from, to, step)
+		if len(buys) == 0 {
+			return withExitCode(exitAllProvidersFailed, errors.New(tr("NoPriceHistoryBetween", "no price history for %s between %s and %s", coin, from.Format(time.DateOnly), to.Format(time.DateOnly))))
+		}
+
+		report := DCAReport{Coin: coin, Currency: currency, Every: every, From: from, To: to, Buys: buys}
+		for _, b := range buys {
+			report.Invested += b.Amount
+			report.Coins += b.Coins
+		}
+		report.AvgCost = report.Invested / report.Coins
+		// Value at --until uses the history; without it, the live price.
+		report.Price, report.ValueNote = points[len(points)-1].Price, tr("DCAValueOn", " on %s", to.Format(time.DateOnly))
+		if dcaUntil == "" {
+			if q := quoteCoin(cmd.Context(), coin, currency); q.err == nil {
+				report.Price, report.ValueNote = q.Price, ""
+			}
+		}
+		report.Value = report.Coins * report.Price
+		report.Return = percentChange(report.Invested, report.Value)
+
+		switch outputFormat {
+		case "json":
+			return printJSON(report)
+		case "csv":
+			return printDCACSV(report)
+		}
+		printDCAReport(report)
+		return nil
+	},
+}
+
+func init() {
+	dcaCmd.Flags().Float64Var(&dcaAmount, "amount", 100, "amount of --vs-currency spent on each buy")
+	dcaCmd.Flags().StringVar(&dcaEvery, "every", "week", "how often to buy: day, week, 2week or month")
+	dcaCmd.Flags().StringVar(&dcaSince, "since", "", "date of the first buy (YYYY-MM-DD or RFC 3339)")
+	dcaCmd.Flags().StringVar(&dcaUntil, "until", "", "stop buying after this date and value the coins then (default now)")
+	dcaCmd.Flags().Float64Var(&dcaFee, "fee", 0, "percentage of each buy lost to fees")
+	dcaCmd.Flags().StringVarP(&dcaCurrency, "vs-currency", "c", "usd", "currency the buys are made in")
+	dcaCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	dcaCmd.MarkFlagRequired("since")
+	rootCmd.AddCommand(dcaCmd)
+}
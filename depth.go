@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	binanceDepthAPI  = "/api/v3/depth?symbol=%s%s&limit=%d"
+	krakenDepthAPI   = "/0/public/Depth?pair=%s%s&count=%d"
+	coinbaseBookAPI  = "/products/%s-%s/book?level=2"
+	okxBooksAPI      = "/api/v5/market/books?instId=%s-%s&sz=%d"
+	bybitSpotBookAPI = "/v5/market/orderbook?category=spot&symbol=%s%s&limit=%d"
+
+	maxDepthLevels = 100
+)
+
+var (
+	depthExchange string
+	depthLevels   int
+)
+
+// DepthLevel is one price level of an order book. Total is the size of
+// this level and every better one.
+type DepthLevel struct {
+	Price float64 `json:"price"`
+	Size  float64 `json:"size"`
+	Total float64 `json:"total"`
+}
+
+// DepthBook is the top of one exchange's order book for a pair, best
+// prices first.
+type DepthBook struct {
+	Exchange  string       `json:"exchange"`
+	Pair      string       `json:"pair"`
+	Base      string       `json:"base"`
+	Quote     string       `json:"quote"`
+	Bids      []DepthLevel `json:"bids"`
+	Asks      []DepthLevel `json:"asks"`
+	Spread    float64      `json:"spread"`
+	SpreadPct float64      `json:"spread_pct"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// bookRows are order book levels as exchanges send them: arrays starting
+// with the price and size as strings, sometimes followed by an order count
+// or timestamp.
+type bookRows [][]interface{}
+
+type depthExchangeAPI func(ctx context.Context, base, quote string, levels int) (bids, asks bookRows, err error)
+
+var depthExchanges = map[string]depthExchangeAPI{
+	"binance":  fetchBinanceDepth,
+	"kraken":   fetchKrakenDepth,
+	"coinbase": fetchCoinbaseDepth,
+	"okx":      fetchOKXDepth,
+	"bybit":    fetchBybitDepth,
+}
+
+func fetchBinanceDepth(ctx context.Context, base, quote string, levels int) (bookRows, bookRows, error) {
+	var book struct {
+		Bids bookRows `json:"bids"`
+		Asks bookRows `json:"asks"`
+	}
+	err := getPairJSON(ctx, providerURL("binance")+fmt.Sprintf(binanceDepthAPI, base, quote, levels), "binance", &book)
+	return book.Bids, book.Asks, err
+}
+
+func fetchKrakenDepth(ctx context.Context, base, quote string, levels int) (bookRows, bookRows, error) {
+	var resp struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Bids bookRows `json:"bids"`
+			Asks bookRows `json:"asks"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, providerURL("kraken")+fmt.Sprintf(krakenDepthAPI, krakenPairAsset(base), krakenPairAsset(quote), levels), "kraken", &resp); err != nil {
+		return nil, nil, err
+	}
+	if len(resp.Error) > 0 {
+		if strings.Contains(resp.Error[0], "Unknown asset pair") {
+			return nil, nil, errPairNotFound
+		}
+		return nil, nil, fmt.Errorf("kraken: %s", strings.Join(resp.Error, "; "))
+	}
+	for _, book := range resp.Result {
+		return book.Bids, book.Asks, nil
+	}
+	return nil, nil, errPairNotFound
+}
+
+// fetchCoinbaseDepth reads Coinbase's level 2 book, which has no size
+// parameter and always returns the whole aggregated book.
+func fetchCoinbaseDepth(ctx context.Context, base, quote string, _ int) (bookRows, bookRows, error) {
+	var book struct {
+		Bids bookRows `json:"bids"`
+		Asks bookRows `json:"asks"`
+	}
+	err := getPairJSON(ctx, providerURL("coinbase-exchange")+fmt.Sprintf(coinbaseBookAPI, base, quote), "coinbase-exchange", &book)
+	return book.Bids, book.Asks, err
+}
+
+func fetchOKXDepth(ctx context.Context, base, quote string, levels int) (bookRows, bookRows, error) {
+	var resp struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Bids bookRows `json:"bids"`
+			Asks bookRows `json:"asks"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("okx")+fmt.Sprintf(okxBooksAPI, base, quote, levels), "okx", &resp); err != nil {
+		return nil, nil, err
+	}
+	if resp.Code == "51001" || resp.Code == "0" && len(resp.Data) == 0 {
+		return nil, nil, errPairNotFound
+	}
+	if resp.Code != "0" {
+		return nil, nil, fmt.Errorf("okx: %s", resp.Msg)
+	}
+	return resp.Data[0].Bids, resp.Data[0].Asks, nil
+}
+
+func fetchBybitDepth(ctx context.Context, base, quote string, levels int) (bookRows, bookRows, error) {
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			Bids bookRows `json:"b"`
+			Asks bookRows `json:"a"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, providerURL("bybit")+fmt.Sprintf(bybitSpotBookAPI, base, quote, levels), "bybit", &resp); err != nil {
+		return nil, nil, err
+	}
+	if resp.RetCode == 10001 {
+		return nil, nil, errPairNotFound
+	}
+	if resp.RetCode != 0 {
+		return nil, nil, fmt.Errorf("bybit: %s", resp.RetMsg)
+	}
+	return resp.Result.Bids, resp.Result.Asks, nil
+}
+
+// bookLevels converts the first n rows to levels with running totals.
+func bookLevels(rows bookRows, n int) []DepthLevel {
+	levels := make([]DepthLevel, 0, min(len(rows), n))
+	var total float64
+	for _, row := range rows {
+		if len(levels) == n {
+			break
+		}
+		if len(row) < 2 {
+			continue
+		}
+		price, size := parseFloat(fmt.Sprint(row[0])), parseFloat(fmt.Sprint(row[1]))
+		if price <= 0 {
+			continue
+		}
+		// Round away the float error that summing sizes adds.
+		total = math.Round((total+size)*1e10) / 1e10
+		levels = append(levels, DepthLevel{price, size, total})
+	}
+	return levels
+}
+
+func fetchDepth(ctx context.Context, exchange, pair string, levels int) (DepthBook, error) {
+	base, quote, err := parsePair(pair)
+	if err != nil {
+		return DepthBook{}, err
+	}
+	label := pairExchanges[exchange].label
+	bids, asks, err := depthExchanges[exchange](ctx, base, quote, levels)
+	if errors.Is(err, errPairNotFound) || err == nil && len(bids) == 0 && len(asks) == 0 {
+		return DepthBook{}, withExitCode(exitCoinNotFound, fmt.Errorf(pairNotFoundTemplate, label, base, quote))
+	}
+	if err != nil {
+		return DepthBook{}, withExitCode(exitAllProvidersFailed, err)
+	}
+	book := DepthBook{
+		Exchange:  label,
+		Pair:      base + "/" + quote,
+		Base:      base,
+		Quote:     quote,
+		Bids:      bookLevels(bids, levels),
+		Asks:      bookLevels(asks, levels),
+		Timestamp: time.Now(),
+	}
+	if len(book.Bids) > 0 && len(book.Asks) > 0 {
+		bid, ask := book.Bids[0].Price, book.Asks[0].Price
+		book.Spread = ask - bid
+		book.SpreadPct = book.Spread / ((ask + bid) / 2) * 100
+	}
+	return book, nil
+}
+
+// valueDecimals is the most decimal places any of the values needs, so a
+// column of them lines up.
+func valueDecimals(values ...float64) int {
+	decimals := 0
+	for _, v := range values {
+		_, frac, _ := strings.Cut(strconv.FormatFloat(v, 'f', -1, 64), ".")
+		decimals = max(decimals, len(frac))
+	}
+	return min(decimals, 10)
+}
+
+func printDepthBook(book DepthBook) {
+	color := useColor(os.Stdout)
+	fmt.Printf("%s order book on %s\n", book.Pair, book.Exchange)
+
+	var prices, sizes []float64
+	for _, l := range append(append([]DepthLevel{}, book.Bids...), book.Asks...) {
+		prices = append(prices, l.Price)
+		sizes = append(sizes, l.Size, l.Total)
+	}
+	priceDigits, sizeDigits := valueDecimals(prices...), valueDecimals(sizes...)
+	cell := func(levels []DepthLevel, i int, up bool) (price, size, total string) {
+		if i >= len(levels) {
+			return "", "", ""
+		}
+		l := levels[i]
+		return colored(localizeNumber(l.Price, priceDigits), up, color), localizeNumber(l.Size, sizeDigits), localizeNumber(l.Total, sizeDigits)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, paintHeader("  BID TOTAL\tBID SIZE\tBID\t  ASK\tASK SIZE\tASK TOTAL\t", color, 2, 3))
+	for i := 0; i < max(len(book.Bids), len(book.Asks)); i++ {
+		bidPrice, bidSize, bidTotal := cell(book.Bids, i, true)
+		askPrice, askSize, askTotal := cell(book.Asks, i, false)
+		if bidPrice == "" {
+			bidPrice = paint("", "39", color)
+		}
+		if askPrice == "" {
+			askPrice = paint("", "39", color)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t  %s\t%s\t%s\t\n", bidTotal, bidSize, bidPrice, askPrice, askSize, askTotal)
+	}
+	w.Flush()
+	if len(book.Bids) > 0 && len(book.Asks) > 0 {
+		fmt.Printf("Spread: %s %s (%.4f%%)\n", localizeNumber(book.Spread, priceDigits), book.Quote, book.SpreadPct)
+	}
+}
+
+var depthCmd = &cobra.Command{
+	Use:   "depth <BASE/QUOTE>",
+	Short: "Show the top of one exchange's order book for a trading pair",
+	Long: `Show the best bids and asks in one exchange's order book for a trading
+pair, with the size at each price, the cumulative size up to it, and the
+spread between the best bid and ask. Sizes are in the base asset.`,
+	Example: `  crypto-cli depth BTC/USDT --exchange binance --levels 10
+  crypto-cli depth ETH/BTC --exchange kraken -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchange := strings.ToLower(depthExchange)
+		if _, ok := depthExchanges[exchange]; !ok {
+			return fmt.Errorf("invalid --exchange %q: must be %s", depthExchange, pairExchangeNamesHelp)
+		}
+		if depthLevels < 1 || depthLevels > maxDepthLevels {
+			return fmt.Errorf("invalid --levels %d: must be between 1 and %d", depthLevels, maxDepthLevels)
+		}
+		if offline {
+			return errOffline
+		}
+		book, err := fetchDepth(cmd.Context(), exchange, args[0], depthLevels)
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			return printJSON(book)
+		}
+		printDepthBook(book)
+		return nil
+	},
+}
+
+func init() {
+	depthCmd.Flags().StringVarP(&depthExchange, "exchange", "e", "binance", "exchange to read: "+pairExchangeNamesHelp)
+	depthCmd.Flags().IntVarP(&depthLevels, "levels", "n", 10, fmt.Sprintf("price levels to show on each side (1-%d)", maxDepthLevels))
+	depthCmd.RegisterFlagCompletionFunc("exchange", cobra.FixedCompletions(pairExchangeNames(), cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(depthCmd)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// windowsToastScript shows a toast through the WinRT notification API. The
+// title and message come in through the environment to avoid quoting them
+// into the script. The toast is sent under PowerShell's app ID, since
+// Windows only shows toasts from registered apps.
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$x = $t.GetElementsByTagName('text')
+$x.Item(0).AppendChild($t.CreateTextNode($env:NOTIFY_TITLE)) > $null
+$x.Item(1).AppendChild($t.CreateTextNode($env:NOTIFY_MESSAGE)) > $null
+$id = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($id).Show([Windows.UI.Notifications.ToastNotification]::new($t))`
+
+// priceBaseline is the price a notification reports the change from.
+type priceBaseline struct {
+	price float64
+	time  time.Time
+}
+
+// desktopNotify shows a native notification with notify-send on Linux,
+// osascript on macOS and a PowerShell toast on Windows.
+func desktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("notify-send", "--app-name=crypto-cli", title, message)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+		cmd.Env = append(os.Environ(), "NOTIFY_TITLE="+title, "NOTIFY_MESSAGE="+message)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%s: %v: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+		}
+		return fmt.Errorf("%s: %v", cmd.Args[0], err)
+	}
+	return nil
+}
+
+var (
+	bell      bool
+	soundFile string
+	soundWarn sync.Once
+)
+
+// checkSound makes sure --sound names a readable file before the first
+// alarm needs it.
+func checkSound() error {
+	if soundFile == "" {
+		return nil
+	}
+	f, err := os.Open(soundFile)
+	if err != nil {
+		return fmt.Errorf("--sound: %w", err)
+	}
+	return f.Close()
+}
+
+// soundPlayers are tried in order on Linux and the BSDs, with the arguments
+// that make them play the file once without a window.
+var soundPlayers = [][]string{
+	{"paplay"},
+	{"pw-play"},
+	{"aplay", "-q"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+}
+
+// playSound plays the file with afplay on macOS, PowerShell's SoundPlayer
+// (WAV only) on Windows and the first of soundPlayers found elsewhere,
+// returning when it has finished.
+func playSound(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("afplay", path)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", "(New-Object Media.SoundPlayer $env:SOUND_FILE).PlaySync()")
+		cmd.Env = append(os.Environ(), "SOUND_FILE="+path)
+	default:
+		for _, player := range soundPlayers {
+			if _, err := exec.LookPath(player[0]); err == nil {
+				cmd = exec.Command(player[0], append(player[1:], path)...)
+				break
+			}
+		}
+		if cmd == nil {
+			return fmt.Errorf("no sound player found: install paplay, pw-play, aplay or ffplay")
+		}
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%s: %v: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+		}
+		return fmt.Errorf("%s: %v", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// alarm rings the terminal bell for --bell and plays --sound. The sound
+// plays in the background unless wait is set, as before exiting; a player
+// that fails is reported once.
+func alarm(wait bool) {
+	if bell {
+		if isTerminal(os.Stdout) {
+			fmt.Print("\a")
+		} else {
+			fmt.Fprint(os.Stderr, "\a")
+		}
+	}
+	if soundFile == "" {
+		return
+	}
+	play := func() {
+		if err := playSound(soundFile); err != nil {
+			soundWarn.Do(func() {
+				fmt.Fprintf(os.Stderr, "Warning: could not play %s: %v\n", soundFile, err)
+			})
+		}
+	}
+	if wait {
+		play()
+		return
+	}
+	go play()
+}
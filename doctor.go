@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// configureErr keeps the config error for doctor to report instead of
+// failing before any check has run.
+var configureErr error
+
+type doctorCheck struct {
+	failed bool
+	warn   bool
+}
+
+func (c *doctorCheck) ok(format string, args ...interface{}) {
+	fmt.Printf("[ok]   "+format+"\n", args...)
+}
+
+func (c *doctorCheck) warning(fix, format string, args ...interface{}) {
+	c.warn = true
+	fmt.Printf("[warn] "+format+"\n", args...)
+	fmt.Printf("       fix: %s\n", fix)
+}
+
+func (c *doctorCheck) fail(fix, format string, args ...interface{}) {
+	c.failed = true
+	fmt.Printf("[fail] "+format+"\n", args...)
+	fmt.Printf("       fix: %s\n", fix)
+}
+
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+func doctorConfig(c *doctorCheck) {
+	path := configPath()
+	switch _, err := os.Stat(path); {
+	case configureErr != nil:
+		c.fail("correct the file or move it aside and run `crypto-cli config init`", "config: %v", configureErr)
+	case errors.Is(err, os.ErrNotExist):
+		c.warning("run `crypto-cli config init` to create one", "config: %s does not exist, using built-in defaults", path)
+	case err != nil:
+		c.fail("check the file's permissions", "config: %v", err)
+	default:
+		c.ok("config: %s", path)
+	}
+
+	for _, d := range []struct{ name, dir, env string }{
+		{"cache dir", cacheDir(), "XDG_CACHE_HOME"},
+		{"data dir", dataDir(), "XDG_DATA_HOME"},
+	} {
+		if err := checkWritable(d.dir); err != nil {
+			c.fail(fmt.Sprintf("fix the permissions of %s or point %s somewhere writable", d.dir, d.env), "%s: %v", d.name, err)
+		} else {
+			c.ok("%s: %s is writable", d.name, d.dir)
+		}
+	}
+
+	if info, err := os.Stat(registryPath()); err != nil {
+		c.warning("it is downloaded on first use; make sure CoinGecko is reachable", "coin list: not cached yet")
+	} else if age := time.Since(info.ModTime()); age > registryTTL*7 {
+		c.warning("run any price lookup while online to refresh it", "coin list: cache is %s old", age.Round(time.Hour))
+	} else {
+		c.ok("coin list: cached %s ago", age.Round(time.Minute))
+	}
+}
+
+func doctorProvider(ctx context.Context, c *doctorCheck, p provider) {
+	if !settingsFor(p.name).enabled() {
+		c.ok("%s: disabled in config", p.label)
+		return
+	}
+	if _, ok := pluginPaths[p.name]; ok {
+		doctorPlugin(ctx, c, p)
+		return
+	}
+	base, err := url.Parse(providerURL(p.name))
+	if err != nil || base.Host == "" {
+		c.fail(fmt.Sprintf("set a valid providers.%s.base_url", p.name), "%s: invalid base URL %q", p.label, providerURL(p.name))
+		return
+	}
+
+	req := &http.Request{URL: base}
+	if proxy, err := http.ProxyFromEnvironment(req); err != nil {
+		c.fail("correct HTTPS_PROXY / HTTP_PROXY", "%s: invalid proxy setting: %v", p.label, err)
+		return
+	} else if proxy != nil {
+		c.ok("%s: using proxy %s", p.label, proxy.Host)
+	} else if _, err := net.LookupHost(base.Hostname()); err != nil {
+		c.fail("check your network connection and DNS resolver, or set HTTPS_PROXY if you are behind a proxy", "%s: cannot resolve %s: %v", p.label, base.Hostname(), err)
+		return
+	}
+
+	st := checkProvider(ctx, p)
+	switch {
+	case !st.Reachable:
+		c.fail(fmt.Sprintf("check connectivity to %s, your proxy, or providers.%s.base_url", base.Host, p.name), "%s: unreachable: %s", p.label, st.LastError)
+	case st.Auth == "key rejected":
+		c.fail(fmt.Sprintf("replace the key with `crypto-cli keys set %s`", p.name), "%s: API key rejected (%s)", p.label, st.LastError)
+	case st.Auth == "key required":
+		c.fail(fmt.Sprintf("add a key with `crypto-cli keys set %s`, or disable the provider", p.name), "%s: an API key is required (%s)", p.label, st.LastError)
+	case st.LastError == "rate limited":
+		c.warning(fmt.Sprintf("add an API key with `crypto-cli keys set %s` or poll less often", p.name), "%s: rate limited", p.label)
+	case st.LastError != "":
+		c.warning(fmt.Sprintf("try again later or lower %s in --priority", p.name), "%s: reachable but returned %s", p.label, st.LastError)
+	case st.RateLimit != "":
+		c.ok("%s: reachable in %s, %s, rate limit %s", p.label, st.Latency.Round(time.Millisecond), st.Auth, st.RateLimit)
+	default:
+		c.ok("%s: reachable in %s, %s", p.label, st.Latency.Round(time.Millisecond), st.Auth)
+	}
+}
+
+func doctorPlugin(ctx context.Context, c *doctorCheck, p provider) {
+	st := checkProvider(ctx, p)
+	switch {
+	case st.Auth == "key required":
+		c.fail(fmt.Sprintf("add a key with `crypto-cli keys set %s`, or remove the plugin", p.name), "%s (plugin): an API key is required (%s)", p.label, st.LastError)
+	case !st.Reachable || st.LastError != "":
+		c.warning(fmt.Sprintf("run `%s bitcoin usd` to see what it prints", pluginPaths[p.name]), "%s (plugin): %s", p.label, st.LastError)
+	default:
+		c.ok("%s (plugin): answered in %s", p.label, st.Latency.Round(time.Millisecond))
+	}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration, storage and provider connectivity problems",
+	Args:  cobra.NoArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		configureErr = configure(cmd, args)
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var c doctorCheck
+		fmt.Printf("crypto-cli %s\n\n", buildVersion())
+		doctorConfig(&c)
+		fmt.Println()
+		for _, p := range providers {
+			doctorProvider(cmd.Context(), &c, p)
+		}
+
+		fmt.Println()
+		switch {
+		case c.failed:
+			return errors.New("doctor found problems, see the fixes above")
+		case c.warn:
+			fmt.Println("No blocking problems found, but see the warnings above.")
+		default:
+			fmt.Println("Everything looks good.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// Exit codes are part of the scripting contract and are listed in the
+// root command's help; keep them stable.
+const (
+	exitCoinNotFound       = 1
+	exitAllProvidersFailed = 2
+	exitRateLimited        = 3
+	exitAlertTriggered     = 4
+	exitStaleOnly          = 5
+	exitGeneric            = 6
+)
+
+type ProviderError struct {
+	Coin     string              `json:"coin,omitempty"`
+	Provider string              `json:"provider"`
+	Error    string              `json:"error"`
+	Kind     pricefeed.ErrorKind `json:"kind,omitempty"`
+}
+
+type exitError struct {
+	code           int
+	err            error
+	providerErrors []ProviderError
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode ends the command with code, or with exitRateLimited when a
+// provider's failure was a rate limit.
+func withExitCode(code int, err error) error {
+	if code == exitAllProvidersFailed && errors.Is(err, pricefeed.ErrRateLimited) {
+		code = exitRateLimited
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitSilently ends the command with code once it has reported the outcome
+// itself.
+func exitSilently(code int) error {
+	return &exitError{code: code}
+}
+
+// silent reports whether err only carries an exit code.
+func silent(err error) bool {
+	var e *exitError
+	return errors.As(err, &e) && e.err == nil
+}
+
+func providerErrors(err error) []ProviderError {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.providerErrors
+	}
+	return nil
+}
+
+func exitCode(err error) int {
+	var e *exitError
+	switch {
+	case errors.As(err, &e):
+		return e.code
+	case errors.Is(err, pricefeed.ErrRateLimited):
+		return exitRateLimited
+	}
+	return exitGeneric
+}
+
+// fetchFailure explains why no usable price came back for a coin, picking
+// the exit code from what the providers reported: rate limits take
+// precedence, even over stale quotes, and a coin that every provider failed
+// to find is reported as unknown.
+func fetchFailure(crypto string, results []pricefeed.Result) error {
+	var stale int
+	var failures []ProviderError
+	kinds := make(map[pricefeed.ErrorKind][]string)
+	var order []pricefeed.ErrorKind
+	for _, r := range results {
+		if r.Stale {
+			stale++
+			failures = append(failures, ProviderError{Coin: crypto, Provider: r.Source, Error: tr("StaleQuote", "stale quote (age %s)", r.Age())})
+			continue
+		}
+		if r.Error == "" {
+			continue
+		}
+		failures = append(failures, ProviderError{Coin: crypto, Provider: r.Source, Error: r.Error, Kind: r.Kind})
+		if kinds[r.Kind] == nil {
+			order = append(order, r.Kind)
+		}
+		kinds[r.Kind] = append(kinds[r.Kind], r.Source)
+	}
+
+	if offline && stale == 0 {
+		return &exitError{
+			code:           exitAllProvidersFailed,
+			err:            errors.New(tr("FetchFailedOffline", "no cached price for %s: run once without --offline to cache it", crypto)),
+			providerErrors: failures,
+		}
+	}
+
+	var reasons []string
+	for _, kind := range order {
+		reasons = append(reasons, fmt.Sprintf("%s: %s", errorKindLabel(kind), strings.Join(kinds[kind], ", ")))
+	}
+	e := &exitError{code: exitAllProvidersFailed, providerErrors: failures}
+	switch {
+	case len(kinds[pricefeed.KindRateLimited]) > 0:
+		e.code = exitRateLimited
+		e.err = errors.New(tr("FetchFailedRateLimited", "failed to fetch the price of %s: rate limited by %d of %d providers", crypto, len(kinds[pricefeed.KindRateLimited]), len(results)))
+	case stale > 0:
+		e.code = exitStaleOnly
+		e.err = errors.New(tr("FetchFailedStale", "failed to fetch the price of %s: only stale quotes were available (%d of %d providers)", crypto, stale, len(results)))
+	case len(results) > 0 && len(kinds[pricefeed.KindNotFound]) == len(results):
+		e.code = exitCoinNotFound
+		e.err = errors.New(tr("FetchFailedNotFound", "failed to fetch the price of %s: no provider has a price for it", crypto))
+	default:
+		e.err = errors.New(tr("FetchFailedAll", "failed to fetch the price of %s: all providers failed", crypto))
+	}
+	if len(reasons) > 0 && e.code != exitCoinNotFound {
+		e.err = fmt.Errorf("%w (%s)", e.err, strings.Join(reasons, "; "))
+	}
+	return e
+}
+
+func errorKindLabel(kind pricefeed.ErrorKind) string {
+	switch kind {
+	case pricefeed.KindUnreachable:
+		return tr("KindUnreachable", "unreachable")
+	case pricefeed.KindNotFound:
+		return tr("KindNotFound", "not found")
+	case pricefeed.KindInvalidResponse:
+		return tr("KindInvalidResponse", "invalid response")
+	case pricefeed.KindRateLimited:
+		return tr("KindRateLimited", "rate limited")
+	case pricefeed.KindNoAPIKey:
+		return tr("KindNoAPIKey", "no API key")
+	}
+	return tr("KindOther", "failed")
+}
+
+// batchError summarizes the failed coins of a multi-coin run, using the
+// exit code of the first failure and keeping every provider error.
+func batchError(quotes []CoinQuote) error {
+	var first error
+	var failures []ProviderError
+	failed := 0
+	for _, q := range quotes {
+		if q.err == nil {
+			continue
+		}
+		failed++
+		if first == nil {
+			first = q.err
+		}
+		failures = append(failures, providerErrors(q.err)...)
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &exitError{
+		code:           exitCode(first),
+		err:            errors.New(tr("BatchFailed", "failed to fetch %d of %d coins", failed, len(quotes))),
+		providerErrors: failures,
+	}
+}
+
+// marshalJSON is json.Marshal without HTML escaping, for MarshalJSON
+// methods whose output printJSON would otherwise pass through escaped.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printJSONLines writes each quote as one compact JSON object per line, for
+// --output ndjson. Stdout is unbuffered, so every line reaches a pipe as
+// soon as it is written.
+func printJSONLines(quotes []CoinQuote) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	for _, q := range quotes {
+		if err := enc.Encode(q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printJSONError writes a failure to stderr as {error, code,
+// provider_errors} for consumers of --output json.
+func printJSONError(err error) {
+	payload := struct {
+		Error          string          `json:"error"`
+		Code           int             `json:"code"`
+		ProviderErrors []ProviderError `json:"provider_errors"`
+	}{err.Error(), exitCode(err), providerErrors(err)}
+	if payload.ProviderErrors == nil {
+		payload.ProviderErrors = []ProviderError{}
+	}
+	json.NewEncoder(os.Stderr).Encode(payload)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cli-crypto-price/pricefeed"
+)
+
+func TestFetchFailure(t *testing.T) {
+	failed := func(source string, kind pricefeed.ErrorKind) pricefeed.Result {
+		return pricefeed.Result{Source: source, Error: string(kind), Kind: kind}
+	}
+	stale := pricefeed.Result{Source: "Kraken", Price: 67000, Stale: true}
+	tests := []struct {
+		name    string
+		results []pricefeed.Result
+		code    int
+		message string
+	}{
+		{
+			name:    "all unreachable",
+			results: []pricefeed.Result{failed("CoinGecko", pricefeed.KindUnreachable), failed("Binance", pricefeed.KindUnreachable)},
+			code:    exitAllProvidersFailed,
+			message: "all providers failed (unreachable: CoinGecko, Binance)",
+		},
+		{
+			name:    "rate limit wins over stale quotes",
+			results: []pricefeed.Result{failed("CoinGecko", pricefeed.KindRateLimited), stale},
+			code:    exitRateLimited,
+			message: "rate limited by 1 of 2 providers",
+		},
+		{
+			name:    "only stale quotes",
+			results: []pricefeed.Result{failed("CoinGecko", pricefeed.KindUnreachable), stale},
+			code:    exitStaleOnly,
+			message: "only stale quotes were available (1 of 2 providers)",
+		},
+		{
+			name:    "unknown to every provider",
+			results: []pricefeed.Result{failed("CoinGecko", pricefeed.KindNotFound), failed("Binance", pricefeed.KindNotFound)},
+			code:    exitCoinNotFound,
+			message: "no provider has a price for it",
+		},
+		{
+			name:    "not found by some",
+			results: []pricefeed.Result{failed("CoinGecko", pricefeed.KindNotFound), failed("Binance", pricefeed.KindInvalidResponse)},
+			code:    exitAllProvidersFailed,
+			message: "(not found: CoinGecko; invalid response: Binance)",
+		},
+		{
+			name:    "no providers",
+			code:    exitAllProvidersFailed,
+			message: "all providers failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fetchFailure("bitcoin", tt.results)
+			if code := exitCode(err); code != tt.code {
+				t.Errorf("exit code = %d, want %d (%v)", code, tt.code, err)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.message)
+			}
+			if got := len(providerErrors(err)); got != len(tt.results) {
+				t.Errorf("%d provider errors, want %d", got, len(tt.results))
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	rateLimited := fmt.Errorf("coingecko: %w", pricefeed.ErrRateLimited)
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"plain error", errors.New("boom"), exitGeneric},
+		{"explicit code", withExitCode(exitCoinNotFound, errors.New("unknown coin")), exitCoinNotFound},
+		{"rate limited", rateLimited, exitRateLimited},
+		{"failed fetch that was rate limited", withExitCode(exitAllProvidersFailed, rateLimited), exitRateLimited},
+		{"other code kept when rate limited", withExitCode(exitCoinNotFound, rateLimited), exitCoinNotFound},
+		{"wrapped exit error", fmt.Errorf("line 3: %w", withExitCode(exitStaleOnly, errors.New("stale"))), exitStaleOnly},
+		{"silent", exitSilently(exitAlertTriggered), exitAlertTriggered},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	coinmarketcalBaseURL   = "https://developers.coinmarketcal.com/v1"
+	coinmarketcalEventsAPI = "/events?coins=%s&max=%d&sortBy=hot_events"
+)
+
+var eventLimit int
+
+type Event struct {
+	Date       time.Time `json:"date"`
+	Title      string    `json:"title"`
+	Categories []string  `json:"categories,omitempty"`
+	Source     string    `json:"source,omitempty"`
+}
+
+type EventReport struct {
+	Coin   string  `json:"coin"`
+	Price  float64 `json:"price,omitempty"`
+	Events []Event `json:"events"`
+}
+
+func fetchEvents(ctx context.Context, coin string, limit int) ([]Event, error) {
+	if providerKey("coinmarketcal") == "" {
+		return nil, fmt.Errorf("coinmarketcal: no API key configured")
+	}
+	var resp struct {
+		Status struct {
+			ErrorCode    int    `json:"error_code"`
+			ErrorMessage string `json:"error_message"`
+		} `json:"status"`
+		Body []struct {
+			Title struct {
+				En string `json:"en"`
+			} `json:"title"`
+			DateEvent  time.Time `json:"date_event"`
+			Source     string    `json:"source"`
+			Categories []struct {
+				Name string `json:"name"`
+			} `json:"categories"`
+		} `json:"body"`
+	}
+	if err := getJSON(ctx, providerURL("coinmarketcal")+fmt.Sprintf(coinmarketcalEventsAPI, url.QueryEscape(coin), limit), "coinmarketcal", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status.ErrorCode != 0 {
+		return nil, fmt.Errorf("coinmarketcal: %s", resp.Status.ErrorMessage)
+	}
+
+	events := make([]Event, 0, len(resp.Body))
+	for _, b := range resp.Body {
+		e := Event{Date: b.DateEvent, Title: b.Title.En, Source: b.Source}
+		for _, c := range b.Categories {
+			e.Categories = append(e.Categories, c.Name)
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events <coin>",
+	Short: "List upcoming project events such as launches, forks and conferences",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		events, err := fetchEvents(cmd.Context(), coin, eventLimit)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		report := EventReport{Coin: coin, Events: events}
+		if q := quoteCoin(cmd.Context(), coin, "usd"); q.err == nil {
+			report.Price = q.Price
+		}
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+
+		if report.Price > 0 {
+			fmt.Printf("%s: %s\n", coin, formatPrice(report.Price, "usd"))
+		}
+		if len(events) == 0 {
+			fmt.Printf("No upcoming events for %s\n", coin)
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "DATE\tCATEGORY\tEVENT\n")
+		for _, e := range events {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", e.Date.Format("2006-01-02"), joinOrDash(e.Categories), e.Title)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	eventsCmd.Flags().IntVar(&eventLimit, "limit", 10, "maximum number of events to show")
+	rootCmd.AddCommand(eventsCmd)
+}
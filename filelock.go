@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	lockPollInterval = 50 * time.Millisecond
+	// staleLockAge is when a lock is taken to be left over from a run
+	// that was killed; appends take milliseconds.
+	staleLockAge = 2 * time.Minute
+)
+
+var lockTimeout = 30 * time.Second
+
+// lockFile takes an exclusive lock on path by creating path.lock, waiting
+// up to lockTimeout for another process to release it. A lock file works
+// the same on every platform and on network file systems where flock does
+// not.
+func lockFile(path string) (unlock func(), err error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			fmt.Fprintln(f, os.Getpid())
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another run (%s); remove it if no other crypto-cli is writing", path, lockOwner(lock))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// lockOwner names the process holding the lock, as far as it can tell.
+func lockOwner(lock string) string {
+	data, err := os.ReadFile(lock)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return "pid " + pid
+	}
+	return lock
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	replayDir         string
+	recordFixturesDir string
+)
+
+// fixture is one recorded HTTP response. A JSON body is kept as JSON so
+// fixtures can be read and edited by hand; anything else as text.
+type fixture struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// fixtureHeaders are the response headers worth keeping: those providers'
+// parsing and rate limiting look at.
+var fixtureHeaders = []string{"Content-Type", "Retry-After", "X-Mbx-Used-Weight-1m", "X-Ratelimit-Remaining"}
+
+// fixtureURL is the request URL with API keys redacted and the query
+// sorted, so a fixture matches whatever key replays it.
+func fixtureURL(u *url.URL) string {
+	c, err := url.Parse(redactURL(u))
+	if err != nil {
+		return u.String()
+	}
+	c.RawQuery = c.Query().Encode()
+	return c.String()
+}
+
+// fixturePath names the fixture for a request after its host and path,
+// with a hash of the method and URL to tell queries apart.
+func fixturePath(dir string, req *http.Request) string {
+	key := fixtureURL(req.URL)
+	sum := sha256.Sum256([]byte(req.Method + " " + key))
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.Trim(req.URL.Host+req.URL.Path, "/"))
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return filepath.Join(dir, name+"-"+hex.EncodeToString(sum[:4])+".json")
+}
+
+// checkFixtures validates --replay and --record-fixtures. Both bypass the
+// quote cache, so every price comes from a fixture or is captured in one.
+func checkFixtures() error {
+	if replayDir != "" && recordFixturesDir != "" {
+		return errors.New("--replay and --record-fixtures cannot be used together")
+	}
+	if replayDir != "" {
+		if info, err := os.Stat(replayDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("--replay %s: not a directory of fixtures", replayDir)
+		}
+	}
+	if replayDir != "" || recordFixturesDir != "" {
+		noCache = true
+	}
+	return nil
+}
+
+// replayTransport answers every request from the fixtures in dir and never
+// touches the network.
+type replayTransport struct {
+	dir string
+}
+
+// fixtureFor returns the fixture file for the request: the one recorded
+// for its exact URL or, for URLs that change between runs such as those
+// carrying the current time, the only one recorded for its path.
+func (t replayTransport) fixtureFor(req *http.Request) (string, error) {
+	path := fixturePath(t.dir, req)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	prefix := path[:strings.LastIndex(path, "-")+1]
+	if matches, _ := filepath.Glob(prefix + "*.json"); len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("no fixture for %s %s (expected %s)", req.Method, fixtureURL(req.URL), path)
+}
+
+func (t replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, err := t.fixtureFor(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	body := []byte(f.Body)
+	if len(f.JSON) > 0 {
+		body = f.JSON
+	}
+	header := f.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	logger.Debug("replayed fixture", "url", fixtureURL(req.URL), "file", path)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// recordingTransport saves every response it gets as a fixture in dir.
+// Failing to save one never fails the request.
+type recordingTransport struct {
+	next http.RoundTripper
+	dir  string
+}
+
+func (t recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	f := fixture{Method: req.Method, URL: fixtureURL(req.URL), Status: resp.StatusCode, Header: make(http.Header)}
+	for _, name := range fixtureHeaders {
+		if v := resp.Header.Values(name); len(v) > 0 {
+			f.Header[name] = v
+		}
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		f.JSON = indented.Bytes()
+	} else {
+		f.Body = string(body)
+	}
+	if err := saveFixture(fixturePath(t.dir, req), f); err != nil {
+		logger.Warn("could not save fixture", "url", f.URL, "err", err)
+	}
+	return resp, nil
+}
+
+func saveFixture(path string, f fixture) error {
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fixture-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Answer every HTTP request from the fixtures in this directory instead of the network, e.g. for demos or bug reports")
+	rootCmd.PersistentFlags().StringVar(&recordFixturesDir, "record-fixtures", "", "Save every HTTP response to this directory as a fixture for --replay (API keys are left out)")
+}
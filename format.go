@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var (
+	formatTemplate string
+	quoteTemplate  *template.Template
+)
+
+// templateFuncs are available to --format templates in addition to Go's
+// built-ins such as printf.
+var templateFuncs = template.FuncMap{
+	"upper":  strings.ToUpper,
+	"lower":  strings.ToLower,
+	"symbol": func(coin string) string { return strings.ToUpper(coinSymbol(coin)) },
+	"price":  formatPrice,
+}
+
+// parseFormat compiles --format up front, so a broken template fails
+// before any request is made.
+func parseFormat() error {
+	if formatTemplate == "" {
+		return nil
+	}
+	t, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(formatTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	quoteTemplate = t
+	return nil
+}
+
+// printFormatted renders every successful quote with the --format template,
+// one line each. Failed quotes are reported through the returned error.
+func printFormatted(quotes []CoinQuote) error {
+	var b bytes.Buffer
+	for _, q := range quotes {
+		if q.err != nil {
+			continue
+		}
+		if err := quoteTemplate.Execute(&b, q); err != nil {
+			return fmt.Errorf("--format: %w", err)
+		}
+		if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+			b.WriteByte('\n')
+		}
+	}
+	fmt.Print(b.String())
+	return batchError(quotes)
+}
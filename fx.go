@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// fxBase is the currency prices are converted from when a provider has no
+// market in the requested one; every provider quotes it.
+const fxBase = "usd"
+
+const fxBaseURL = "https://open.er-api.com/v6"
+
+// fxCacheTTL is how long fetched exchange rates are reused. The source
+// updates them once a day.
+const fxCacheTTL = 6 * time.Hour
+
+// fxRates are the exchange rates from one base currency, keyed by
+// lowercase currency code.
+type fxRates struct {
+	Base    string             `json:"base"`
+	Rates   map[string]float64 `json:"rates"`
+	Source  string             `json:"source"`
+	Updated time.Time          `json:"updated"`
+	Fetched time.Time          `json:"fetched"`
+}
+
+// fxConversion is how a provider's price was converted, for --verbose.
+type fxConversion struct {
+	From    string
+	To      string
+	Rate    float64
+	Source  string
+	Updated time.Time
+}
+
+var (
+	fxMu        sync.Mutex
+	fxLoaded    *fxRates
+	fxConverted = make(map[string]fxConversion)
+)
+
+func fxCachePath() string {
+	return filepath.Join(cacheDir(), "fx.json")
+}
+
+// fxRate returns the rate converting 1 fxBase into currency, from the
+// on-disk cache while it is younger than fxCacheTTL, or at any age under
+// --offline.
+func fxRate(ctx context.Context, currency string) (float64, fxRates, error) {
+	fxMu.Lock()
+	defer fxMu.Unlock()
+	if fxLoaded == nil {
+		var cached fxRates
+		if data, err := os.ReadFile(fxCachePath()); err == nil && json.Unmarshal(data, &cached) == nil && cached.Base == fxBase {
+			fxLoaded = &cached
+		}
+	}
+	fresh := fxLoaded != nil && (offline || !noCache && time.Since(fxLoaded.Fetched) < fxCacheTTL)
+	if !fresh {
+		if offline {
+			return 0, fxRates{}, errOffline
+		}
+		rates, err := fetchFXRates(ctx)
+		if err != nil {
+			return 0, fxRates{}, err
+		}
+		fxLoaded = &rates
+		saveFXRates(rates)
+	}
+	rate := fxLoaded.Rates[currency]
+	if rate <= 0 {
+		return 0, *fxLoaded, fmt.Errorf("%s has no %s rate", fxLoaded.Source, strings.ToUpper(currency))
+	}
+	return rate, *fxLoaded, nil
+}
+
+func fetchFXRates(ctx context.Context) (fxRates, error) {
+	var resp struct {
+		Result    string             `json:"result"`
+		ErrorType string             `json:"error-type"`
+		Updated   int64              `json:"time_last_update_unix"`
+		Rates     map[string]float64 `json:"rates"`
+	}
+	if err := getJSON(ctx, providerURL("fx")+"/latest/"+strings.ToUpper(fxBase), "fx", &resp); err != nil {
+		return fxRates{}, err
+	}
+	if resp.Result != "success" {
+		return fxRates{}, fmt.Errorf("fx: %s", resp.ErrorType)
+	}
+	rates := fxRates{Base: fxBase, Rates: make(map[string]float64, len(resp.Rates)), Source: "ExchangeRate-API", Fetched: time.Now()}
+	for code, rate := range resp.Rates {
+		rates.Rates[strings.ToLower(code)] = rate
+	}
+	if resp.Updated > 0 {
+		rates.Updated = time.Unix(resp.Updated, 0)
+	}
+	logger.Debug("fetched exchange rates", "source", rates.Source, "base", fxBase, "updated", rates.Updated)
+	return rates, nil
+}
+
+// saveFXRates caches the rates on disk. Failing to write the cache never
+// fails the request.
+func saveFXRates(rates fxRates) {
+	data, err := json.Marshal(rates)
+	if err != nil {
+		return
+	}
+	path := fxCachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err == nil {
+		os.Rename(tmp, path)
+	}
+}
+
+// fxProvider prices a coin in a currency the provider has no market for,
+// such as uah on Binance, by fetching the fxBase price and converting it
+// at the current exchange rate. The provider's own error is kept when the
+// rate is not available either.
+type fxProvider struct {
+	pricefeed.Provider
+}
+
+func (p fxProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	q, err := p.Provider.Fetch(ctx, coin, currency)
+	if currency == fxBase || !errors.Is(err, pricefeed.ErrNotFound) {
+		return q, err
+	}
+	rate, rates, fxErr := fxRate(ctx, currency)
+	if fxErr != nil {
+		logger.Debug("no exchange rate", "currency", currency, "err", fxErr)
+		return q, err
+	}
+	base, baseErr := p.Provider.Fetch(ctx, coin, fxBase)
+	if baseErr != nil {
+		return q, err
+	}
+	base.Price *= rate
+	base.Currency = currency
+	fxMu.Lock()
+	fxConverted[cacheKey(base.Source, coin, currency)] = fxConversion{fxBase, currency, rate, rates.Source, rates.Updated}
+	fxMu.Unlock()
+	return base, nil
+}
+
+// fxNote describes how the quote was converted from fxBase, or is empty
+// when the provider quoted the currency itself.
+func fxNote(q CoinQuote) string {
+	fxMu.Lock()
+	c, ok := fxConverted[cacheKey(q.Source, q.Coin, q.Currency)]
+	fxMu.Unlock()
+	if !ok {
+		return ""
+	}
+	updated := ""
+	if !c.Updated.IsZero() {
+		updated = ", " + c.Updated.Format("2006-01-02")
+	}
+	return tr("FXNote", "  Converted from %s at 1 %s = %s %s (%s%s)\n", strings.ToUpper(c.From), strings.ToUpper(c.From), localizeNumber(c.Rate, 4), strings.ToUpper(c.To), c.Source, updated)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	blocknativeBaseURL = "https://api.blocknative.com"
+
+	blocknativeGasAPI = "/gasprices/blockprices?chainid=%d"
+)
+
+var (
+	gasLimit    int
+	gasBySource bool
+)
+
+type gasChain struct {
+	Label   string
+	ChainID int
+	Coin    string // CoinGecko ID of the native coin that pays for gas
+	Symbol  string
+}
+
+var gasChains = map[string]gasChain{
+	"eth":     {"Ethereum", 1, "ethereum", "ETH"},
+	"polygon": {"Polygon", 137, "polygon-ecosystem-token", "POL"},
+	"bsc":     {"BNB Smart Chain", 56, "binancecoin", "BNB"},
+}
+
+var gasChainAliases = map[string]string{
+	"ethereum": "eth",
+	"matic":    "polygon",
+	"pol":      "polygon",
+	"bnb":      "bsc",
+}
+
+// GasEstimate is one source's gas prices in gwei.
+type GasEstimate struct {
+	Source   string  `json:"source"`
+	Slow     float64 `json:"slow_gwei,omitempty"`
+	Standard float64 `json:"standard_gwei,omitempty"`
+	Fast     float64 `json:"fast_gwei,omitempty"`
+	BaseFee  float64 `json:"base_fee_gwei,omitempty"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// GasReport combines the sources by taking the median of each speed, and
+// prices a transaction of GasLimit gas at it.
+type GasReport struct {
+	Chain     string        `json:"chain"`
+	ChainID   int           `json:"chain_id"`
+	Coin      string        `json:"coin"`
+	Price     float64       `json:"price_usd,omitempty"`
+	Slow      float64       `json:"slow_gwei"`
+	Standard  float64       `json:"standard_gwei"`
+	Fast      float64       `json:"fast_gwei"`
+	GasLimit  int           `json:"gas_limit"`
+	Costs     *gasCosts     `json:"cost_usd,omitempty"`
+	Estimates []GasEstimate `json:"sources"`
+}
+
+type gasCosts struct {
+	Slow     float64 `json:"slow"`
+	Standard float64 `json:"standard"`
+	Fast     float64 `json:"fast"`
+}
+
+type gasFetcher struct {
+	name   string
+	chains []int
+	fetch  func(ctx context.Context, chainID int) (GasEstimate, error)
+}
+
+var gasFetchers = []gasFetcher{
+	{"Etherscan", []int{1, 137, 56}, fetchEtherscanGasOracle},
+	{"Blocknative", []int{1, 137}, fetchBlocknativeGas},
+}
+
+// fetchEtherscanGasOracle uses the multichain V2 API, which serves every
+// supported chain from one host with one key.
+func fetchEtherscanGasOracle(ctx context.Context, chainID int) (GasEstimate, error) {
+	params := url.Values{"chainid": {strconv.Itoa(chainID)}, "module": {"gastracker"}, "action": {"gasoracle"}}
+	if key := providerKey("etherscan"); key != "" {
+		params.Set("apikey", key)
+	}
+	var resp etherscanResponse
+	if err := getJSON(ctx, providerURL("etherscan")+"/v2/api?"+params.Encode(), "etherscan", &resp); err != nil {
+		return GasEstimate{}, err
+	}
+	if err := resp.err(); err != nil {
+		return GasEstimate{}, err
+	}
+	oracle, _ := resp.Result.(map[string]interface{})
+	field := func(name string) float64 {
+		s, _ := oracle[name].(string)
+		return parseFloat(s)
+	}
+	return GasEstimate{Slow: field("SafeGasPrice"), Standard: field("ProposeGasPrice"), Fast: field("FastGasPrice"), BaseFee: field("suggestBaseFee")}, nil
+}
+
+// fetchBlocknativeGas maps Blocknative's inclusion confidence levels to
+// speeds: 70% slow, 90% standard, 99% fast.
+func fetchBlocknativeGas(ctx context.Context, chainID int) (GasEstimate, error) {
+	var resp struct {
+		BlockPrices []struct {
+			BaseFeePerGas   float64 `json:"baseFeePerGas"`
+			EstimatedPrices []struct {
+				Confidence int     `json:"confidence"`
+				Price      float64 `json:"price"`
+			} `json:"estimatedPrices"`
+		} `json:"blockPrices"`
+	}
+	if err := getJSON(ctx, providerURL("blocknative")+fmt.Sprintf(blocknativeGasAPI, chainID), "blocknative", &resp); err != nil {
+		return GasEstimate{}, err
+	}
+	if len(resp.BlockPrices) == 0 {
+		return GasEstimate{}, fmt.Errorf("blocknative: no block prices")
+	}
+	block := resp.BlockPrices[0]
+	est := GasEstimate{BaseFee: block.BaseFeePerGas}
+	for _, p := range block.EstimatedPrices {
+		switch p.Confidence {
+		case 70:
+			est.Slow = p.Price
+		case 90:
+			est.Standard = p.Price
+		case 99:
+			est.Fast = p.Price
+		}
+	}
+	return est, nil
+}
+
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func fetchGas(ctx context.Context, name string, chain gasChain) GasReport {
+	report := GasReport{Chain: name, ChainID: chain.ChainID, Coin: chain.Coin, GasLimit: gasLimit}
+	var fetchers []gasFetcher
+	for _, f := range gasFetchers {
+		for _, id := range f.chains {
+			if id == chain.ChainID {
+				fetchers = append(fetchers, f)
+			}
+		}
+	}
+
+	report.Estimates = make([]GasEstimate, len(fetchers))
+	var wg sync.WaitGroup
+	wg.Add(len(fetchers) + 1)
+	for i, f := range fetchers {
+		go func(i int, f gasFetcher) {
+			defer wg.Done()
+			start := time.Now()
+			est, err := f.fetch(ctx, chain.ChainID)
+			est.Source, est.Duration = f.name, milliseconds(time.Since(start))
+			if err != nil {
+				est.Error = err.Error()
+			}
+			report.Estimates[i] = est
+		}(i, f)
+	}
+	go func() {
+		defer wg.Done()
+		if q := quoteCoin(ctx, chain.Coin, "usd"); q.err == nil {
+			report.Price = q.Price
+		}
+	}()
+	wg.Wait()
+
+	var slow, standard, fast []float64
+	for _, est := range report.Estimates {
+		if est.Error != "" {
+			continue
+		}
+		slow, standard, fast = append(slow, est.Slow), append(standard, est.Standard), append(fast, est.Fast)
+	}
+	report.Slow, report.Standard, report.Fast = medianOf(slow), medianOf(standard), medianOf(fast)
+	if report.Price > 0 {
+		cost := func(gwei float64) float64 { return gwei * 1e-9 * float64(gasLimit) * report.Price }
+		report.Costs = &gasCosts{cost(report.Slow), cost(report.Standard), cost(report.Fast)}
+	}
+	return report
+}
+
+func formatGwei(gwei float64) string {
+	if gwei == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(math.Round(gwei*1000)/1000, 'f', -1, 64)
+}
+
+func printGasReport(report GasReport, chain gasChain) error {
+	fmt.Printf("%s gas", chain.Label)
+	if report.Price > 0 {
+		fmt.Printf(" (%s %s)", chain.Symbol, formatPrice(report.Price, "usd"))
+	}
+	fmt.Println()
+
+	color := useColor(os.Stdout)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SPEED\tGWEI\tCOST")
+	for _, row := range []struct {
+		name string
+		gwei float64
+		cost func(*gasCosts) float64
+	}{
+		{"slow", report.Slow, func(c *gasCosts) float64 { return c.Slow }},
+		{"standard", report.Standard, func(c *gasCosts) float64 { return c.Standard }},
+		{"fast", report.Fast, func(c *gasCosts) float64 { return c.Fast }},
+	} {
+		cost := "-"
+		if report.Costs != nil && row.gwei > 0 {
+			cost = formatPrice(row.cost(report.Costs), "usd")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", row.name, formatGwei(row.gwei), cost)
+	}
+	w.Flush()
+	footer := fmt.Sprintf("Median of %s", gasSources(report))
+	if report.Costs != nil {
+		footer += fmt.Sprintf("; cost of %d gas", gasLimit)
+	}
+	fmt.Println(dim(footer, color))
+
+	if gasBySource {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SOURCE\tSLOW\tSTANDARD\tFAST\tBASE FEE\tDURATION")
+		for _, est := range report.Estimates {
+			if est.Error != "" {
+				fmt.Fprintf(w, "%s\t-\t-\t-\t-\terror: %s\n", est.Source, est.Error)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.0fms\n", est.Source, formatGwei(est.Slow), formatGwei(est.Standard), formatGwei(est.Fast), formatGwei(est.BaseFee), est.Duration)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func gasSources(report GasReport) string {
+	var names []string
+	for _, est := range report.Estimates {
+		if est.Error == "" {
+			names = append(names, est.Source)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func gasChainNames() []string {
+	names := make([]string, 0, len(gasChains))
+	for name := range gasChains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var gasCmd = &cobra.Command{
+	Use:   "gas [eth|polygon|bsc]",
+	Short: "Show current gas prices and the cost of a transfer on an EVM chain",
+	Long: `Query the gas price sources for a chain at once and show slow, standard and
+fast gas prices in gwei, with what a transaction of --gas-limit gas costs in
+USD at each. Etherscan covers every chain; Blocknative adds Ethereum and
+Polygon. Both work without a key at low request rates; set
+providers.etherscan.key or providers.blocknative.key to raise the limits.`,
+	Example: `  crypto-cli gas
+  crypto-cli gas polygon --sources
+  crypto-cli gas eth --gas-limit 65000   # an ERC-20 transfer`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"eth", "polygon", "bsc"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := "eth"
+		if len(args) > 0 {
+			name = strings.ToLower(args[0])
+		}
+		if alias, ok := gasChainAliases[name]; ok {
+			name = alias
+		}
+		chain, ok := gasChains[name]
+		if !ok {
+			return fmt.Errorf("unknown chain %q (supported: %s)", name, strings.Join(gasChainNames(), ", "))
+		}
+
+		report := fetchGas(cmd.Context(), name, chain)
+		if report.Standard == 0 {
+			for _, est := range report.Estimates {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", est.Source, est.Error)
+			}
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no gas prices for %s", chain.Label))
+		}
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+		return printGasReport(report, chain)
+	},
+}
+
+func init() {
+	gasCmd.Flags().IntVar(&gasLimit, "gas-limit", 21000, "gas used by the transaction to price (21000 is a plain transfer)")
+	gasCmd.Flags().BoolVar(&gasBySource, "sources", false, "also show each source's prices and base fee")
+	rootCmd.AddCommand(gasCmd)
+}
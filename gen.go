@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var manDir string
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate man pages and shell completions for packaging",
+}
+
+var genManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Write man pages for every command",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := os.MkdirAll(manDir, 0o755); err != nil {
+			return err
+		}
+		header := &doc.GenManHeader{Title: "CRYPTO-CLI", Section: "1"}
+		if err := doc.GenManTree(rootCmd, header, manDir); err != nil {
+			return err
+		}
+		fmt.Printf("Wrote man pages to %s\n", manDir)
+		return nil
+	},
+}
+
+var genCompletionCmd = &cobra.Command{
+	Use:       "completion bash|zsh|fish|powershell",
+	Short:     "Write a shell completion script to stdout",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return rootCmd.GenFishCompletion(os.Stdout, true)
+		default:
+			return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+func init() {
+	genManCmd.Flags().StringVar(&manDir, "dir", "man", "Directory to write the man pages to")
+	genCmd.AddCommand(genManCmd, genCompletionCmd)
+	rootCmd.AddCommand(genCmd)
+}
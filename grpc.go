@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cli-crypto-price/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultStreamInterval = 10 * time.Second
+	minStreamInterval     = time.Second
+)
+
+// priceServer answers api.PriceService with the same quotes as the HTTP
+// endpoints.
+type priceServer struct {
+	api.UnimplementedPriceServiceServer
+}
+
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+		return handler(ctx, req)
+	}))
+	api.RegisterPriceServiceServer(server, priceServer{})
+	reflection.Register(server)
+	return server
+}
+
+// grpcStatus maps a quote failure to a status code by its exit code, like
+// httpStatus.
+func grpcStatus(err error) error {
+	code := codes.InvalidArgument
+	switch exitCode(err) {
+	case exitCoinNotFound:
+		code = codes.NotFound
+	case exitRateLimited:
+		code = codes.ResourceExhausted
+	case exitAllProvidersFailed, exitStaleOnly:
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+func quoteMessage(q CoinQuote) *api.Quote {
+	m := &api.Quote{Coin: q.Coin, Currency: q.Currency}
+	if q.err != nil {
+		m.Error = q.err.Error()
+		return m
+	}
+	m.Price, m.Source, m.Duration = q.Price, q.Source, durationpb.New(q.Duration)
+	if !q.Timestamp.IsZero() {
+		m.Timestamp = timestamppb.New(q.Timestamp)
+	}
+	return m
+}
+
+// lowerList trims and lowercases values, dropping empty ones.
+func lowerList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func requestCurrencies(values []string) ([]string, error) {
+	currencies, err := parseCurrencies(values)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return currencies, nil
+}
+
+func (priceServer) GetPrice(ctx context.Context, req *api.GetPriceRequest) (*api.Quote, error) {
+	currencies, err := requestCurrencies([]string{req.Currency})
+	if err != nil {
+		return nil, err
+	}
+	currency := currencies[0]
+	coin, err := resolveCoin(ctx, strings.TrimSpace(req.Coin), false)
+	if err != nil {
+		return nil, grpcStatus(err)
+	}
+	q := quoteCoin(ctx, coin, currency)
+	if q.err != nil {
+		return nil, grpcStatus(q.err)
+	}
+	return quoteMessage(q), nil
+}
+
+// GetPrices answers with every quote, failed ones carrying an error, and
+// only fails as a whole when no coins were asked for.
+func (priceServer) GetPrices(ctx context.Context, req *api.GetPricesRequest) (*api.GetPricesResponse, error) {
+	ids := lowerList(req.Coins)
+	if len(ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing coins")
+	}
+	currencies, err := requestCurrencies(req.Currencies)
+	if err != nil {
+		return nil, err
+	}
+	resp := &api.GetPricesResponse{}
+	for _, q := range quoteCoins(ctx, ids, currencies) {
+		resp.Quotes = append(resp.Quotes, quoteMessage(q))
+	}
+	return resp, nil
+}
+
+// StreamPrices sends a round of quotes every interval until the client
+// goes away. As with GetPrices, failed quotes are sent with an error.
+func (priceServer) StreamPrices(req *api.StreamPricesRequest, stream grpc.ServerStreamingServer[api.Quote]) error {
+	ids := lowerList(req.Coins)
+	if len(ids) == 0 {
+		return status.Error(codes.InvalidArgument, "missing coins")
+	}
+	interval := defaultStreamInterval
+	if req.Interval != nil {
+		interval = req.Interval.AsDuration()
+	}
+	if interval < minStreamInterval {
+		return status.Errorf(codes.InvalidArgument, "interval must be at least %s", minStreamInterval)
+	}
+	currencies, err := requestCurrencies(req.Currencies)
+	if err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		reloadMu.RLock()
+		quotes := quoteCoins(ctx, ids, currencies)
+		reloadMu.RUnlock()
+		for _, q := range quotes {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := stream.Send(quoteMessage(q)); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
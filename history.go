@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const coingeckoMarketChartRangeAPI = "/coins/%s/market_chart/range?vs_currency=%s&from=%d&to=%d"
+
+// CoinGecko returns hourly points for ranges of up to 90 days and daily
+// points beyond that.
+const maxHourlyRange = 90 * 24 * time.Hour
+
+var (
+	historyDays     int
+	historyFrom     string
+	historyTo       string
+	historyHourly   bool
+	historyCurrency string
+	historyChart    string
+)
+
+// marketChartResponse holds [unix ms, value] pairs.
+type marketChartResponse struct {
+	Prices       [][2]float64 `json:"prices"`
+	MarketCaps   [][2]float64 `json:"market_caps"`
+	TotalVolumes [][2]float64 `json:"total_volumes"`
+}
+
+type PricePoint struct {
+	Time      time.Time `json:"time"`
+	Price     float64   `json:"price"`
+	MarketCap float64   `json:"market_cap,omitempty"`
+	Volume    float64   `json:"volume,omitempty"`
+}
+
+func fetchHistory(ctx context.Context, coin, currency string, from, to time.Time) ([]PricePoint, error) {
+	if mockMode {
+		return mockHistory(coin, currency, from, to), nil
+	}
+	var chart marketChartResponse
+	url := providerURL("coingecko") + fmt.Sprintf(coingeckoMarketChartRangeAPI, coin, currency, from.Unix(), to.Unix())
+	if err := getJSON(ctx, url, "coingecko", &chart); err != nil {
+		return nil, err
+	}
+
+	points := make([]PricePoint, len(chart.Prices))
+	for i, p := range chart.Prices {
+		points[i] = PricePoint{Time: time.UnixMilli(int64(p[0])).UTC(), Price: p[1]}
+		if i < len(chart.MarketCaps) {
+			points[i].MarketCap = chart.MarketCaps[i][1]
+		}
+		if i < len(chart.TotalVolumes) {
+			points[i].Volume = chart.TotalVolumes[i][1]
+		}
+	}
+	return points, nil
+}
+
+// mockHistory is a seeded random walk ending at the coin's --mock price.
+func mockHistory(coin, currency string, from, to time.Time) []PricePoint {
+	step := time.Hour
+	if to.Sub(from) > maxHourlyRange {
+		step = 24 * time.Hour
+	}
+	var points []PricePoint
+	price := mockPrice(coin, currency, "history")
+	for t := to.Truncate(step); !t.Before(from); t = t.Add(-step) {
+		points = append([]PricePoint{{Time: t.UTC(), Price: price}}, points...)
+		move := (float64(mockHash(coin, t.Format(time.RFC3339))%2001) - 1000) / 200000
+		price *= 1 - move
+	}
+	return points
+}
+
+// dailyPoints keeps the last point of each UTC day.
+func dailyPoints(points []PricePoint) []PricePoint {
+	var daily []PricePoint
+	for _, p := range points {
+		if n := len(daily); n > 0 && daily[n-1].Time.Format(time.DateOnly) == p.Time.Format(time.DateOnly) {
+			daily[n-1] = p
+			continue
+		}
+		daily = append(daily, p)
+	}
+	return daily
+}
+
+// parseDate accepts a date such as 2024-01-31 (midnight UTC) or an RFC 3339
+// timestamp.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, errors.New(tr("InvalidDate", "invalid date %q: use YYYY-MM-DD or RFC 3339", s))
+	}
+	return t, nil
+}
+
+// historyRange works out the requested time range from --from and --to,
+// falling back to the last --days days.
+func historyRange() (time.Time, time.Time, error) {
+	to := time.Now()
+	if historyTo != "" {
+		t, err := parseDate(historyTo)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -historyDays)
+	if historyFrom != "" {
+		t, err := parseDate(historyFrom)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	} else if historyDays < 1 {
+		return time.Time{}, time.Time{}, errors.New(tr("HistoryDaysTooFew", "--days must be at least 1"))
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errors.New(tr("HistoryFromNotBeforeTo", "--from must be before --to"))
+	}
+	if historyHourly && to.Sub(from) > maxHourlyRange {
+		return time.Time{}, time.Time{}, errors.New(tr("HistoryHourlyLimit", "hourly history is limited to %d days", int(maxHourlyRange.Hours()/24)))
+	}
+	return from, to, nil
+}
+
+func printHistoryCSV(points []PricePoint, currency string) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"time", "price", "currency", "market_cap", "volume"})
+	for _, p := range points {
+		w.Write([]string{
+			p.Time.Format(time.RFC3339),
+			strconv.FormatFloat(p.Price, 'f', -1, 64),
+			currency,
+			strconv.FormatFloat(p.MarketCap, 'f', 0, 64),
+			strconv.FormatFloat(p.Volume, 'f', 0, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func printHistoryTable(points []PricePoint, currency string) error {
+	layout := time.DateOnly
+	if historyHourly {
+		layout = "2006-01-02 15:04"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("HistoryTableHeader", "TIME\tPRICE\tCHANGE\tVOLUME"))
+	for i, p := range points {
+		change := "-"
+		if i > 0 && points[i-1].Price > 0 {
+			change = fmt.Sprintf("%+.2f%%", percentChange(points[i-1].Price, p.Price))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Time.Format(layout), formatPrice(p.Price, currency), change, formatVolume(p.Volume))
+	}
+	return w.Flush()
+}
+
+// historyFields are the columns --columns can pick for history.
+func historyFields(points []PricePoint, currency string) []field {
+	layout := time.DateOnly
+	if historyHourly {
+		layout = "2006-01-02 15:04"
+	}
+	change := func(i int) interface{} {
+		if i == 0 || points[i-1].Price <= 0 {
+			return nil
+		}
+		return percentChange(points[i-1].Price, points[i].Price)
+	}
+	return []field{
+		{"time", "TIME", func(i int) string { return points[i].Time.Format(layout) }, func(i int) interface{} { return points[i].Time }},
+		{"price", "PRICE", func(i int) string { return formatPrice(points[i].Price, currency) }, func(i int) interface{} { return points[i].Price }},
+		{"currency", "CURRENCY", func(i int) string { return strings.ToUpper(currency) }, func(i int) interface{} { return currency }},
+		{"change", "CHANGE", func(i int) string {
+			if c, ok := change(i).(float64); ok {
+				return fmt.Sprintf("%+.2f%%", c)
+			}
+			return "-"
+		}, change},
+		{"market_cap", "MARKET CAP", func(i int) string { return formatVolume(points[i].MarketCap) }, func(i int) interface{} { return points[i].MarketCap }},
+		{"volume", "VOLUME", func(i int) string { return formatVolume(points[i].Volume) }, func(i int) interface{} { return points[i].Volume }},
+	}
+}
+
+func formatVolume(v float64) string {
+	switch {
+	case v <= 0:
+		return "-"
+	case humanizeNumbers:
+		if s, ok := humanizeNumber(v); ok {
+			return s
+		}
+	case v >= 1e12:
+		return localizeNumber(v/1e12, 2) + "T"
+	case v >= 1e9:
+		return localizeNumber(v/1e9, 2) + "B"
+	case v >= 1e6:
+		return localizeNumber(v/1e6, 2) + "M"
+	}
+	return localizeNumber(math.Round(v), 0)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history <coin>",
+	Short: "Show a coin's daily or hourly price history",
+	Example: `  crypto-cli history bitcoin --days 30
+  crypto-cli history ethereum --from 2024-01-01 --to 2024-03-31 -o csv
+  crypto-cli history solana --days 2 --hourly
+  crypto-cli history bitcoin --days 7 --chart
+  crypto-cli history bitcoin --days 90 --chart=candle
+  crypto-cli history bitcoin --days 7 --columns time,price,volume -o csv`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		currency := strings.ToLower(historyCurrency)
+		from, to, err := historyRange()
+		if err != nil {
+			return err
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+
+		points, err := fetchHistory(cmd.Context(), coin, currency, from, to)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if !historyHourly && historyChart == "" {
+			points = dailyPoints(points)
+		}
+		if len(points) == 0 {
+			return withExitCode(exitAllProvidersFailed, errors.New(tr("NoPriceHistoryBetween", "no price history for %s between %s and %s", coin, from.Format(time.DateOnly), to.Format(time.DateOnly))))
+		}
+
+		if len(selectedColumns) > 0 && historyChart == "" {
+			return printColumns(historyFields(points, currency), len(points))
+		}
+		switch outputFormat {
+		case "json":
+			return printJSON(points)
+		case "csv":
+			return printHistoryCSV(points, currency)
+		}
+		if historyChart != "" {
+			return printChart(coin, currency, historyChart, points)
+		}
+		fmt.Print(tr("HistoryHeading", "%s (%s), %s to %s\n", coin, strings.ToUpper(currency), points[0].Time.Format(time.DateOnly), points[len(points)-1].Time.Format(time.DateOnly)))
+		return printHistoryTable(points, currency)
+	},
+}
+
+func init() {
+	historyCmd.Flags().IntVar(&historyDays, "days", 30, "number of days of history, ending at --to")
+	historyCmd.Flags().StringVar(&historyFrom, "from", "", "start date (YYYY-MM-DD or RFC 3339), instead of --days")
+	historyCmd.Flags().StringVar(&historyTo, "to", "", "end date (YYYY-MM-DD or RFC 3339, default now)")
+	historyCmd.Flags().BoolVar(&historyHourly, "hourly", false, "show hourly prices (up to 90 days) instead of one per day")
+	historyCmd.Flags().StringVarP(&historyCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	historyCmd.Flags().StringVar(&historyChart, "chart", "", "draw a chart instead of the table: spark (default) or candle, e.g. --chart=candle")
+	historyCmd.Flags().Lookup("chart").NoOptDefVal = "spark"
+	historyCmd.Flags().StringSliceVar(&selectedColumns, "columns", nil, "columns to show, in order: time, price, currency, change, market_cap, volume")
+	historyCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	proxyURL           string
+	userAgent          string
+	insecureSkipVerify bool
+)
+
+var (
+	transportOnce   sync.Once
+	sharedTransport http.RoundTripper
+
+	// httpTransport, when set before the first request, replaces the
+	// network under every request, e.g. with a pricefeedtest server's.
+	httpTransport http.RoundTripper
+)
+
+// baseTransport is the transport every outgoing request ends in: one
+// connection pool for the whole run, the proxy from --proxy or the
+// HTTP(S)_PROXY environment, and the User-Agent header. It is built on
+// first use, after the flags are parsed. --replay swaps the network for
+// fixtures and --record-fixtures saves what it returns.
+func baseTransport() http.RoundTripper {
+	transportOnce.Do(func() {
+		if replayDir != "" {
+			sharedTransport = userAgentTransport{replayTransport{replayDir}}
+			return
+		}
+		if httpTransport != nil {
+			sharedTransport = userAgentTransport{httpTransport}
+			return
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConnsPerHost = 10
+		t.IdleConnTimeout = 90 * time.Second
+		if proxyURL != "" {
+			// configure has already checked that it parses.
+			u, _ := url.Parse(proxyURL)
+			t.Proxy = http.ProxyURL(u)
+		}
+		if insecureSkipVerify {
+			t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		if recordFixturesDir != "" {
+			sharedTransport = userAgentTransport{recordingTransport{t, recordFixturesDir}}
+			return
+		}
+		sharedTransport = userAgentTransport{t}
+	})
+	return sharedTransport
+}
+
+// newHTTPClient returns a client over the shared transport. A zero timeout
+// means none.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: baseTransport()}
+}
+
+type userAgentTransport struct {
+	next http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", effectiveUserAgent())
+	}
+	return t.next.RoundTrip(req)
+}
+
+func effectiveUserAgent() string {
+	if userAgent != "" {
+		return userAgent
+	}
+	return "crypto-cli/" + buildVersion()
+}
+
+func checkProxy() error {
+	if proxyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid --proxy %q: expected a URL such as http://proxy.example.com:8080", proxyURL)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+		return nil
+	}
+	return fmt.Errorf("invalid --proxy %q: the scheme must be http, https or socks5", proxyURL)
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "Send requests through this proxy URL (default: HTTPS_PROXY/HTTP_PROXY from the environment)")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "User-Agent header sent to providers (default crypto-cli/<version>)")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "Do not verify TLS certificates, e.g. behind an intercepting corporate proxy (unsafe)")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var localeFS embed.FS
+
+var (
+	lang      string
+	localizer *i18n.Localizer
+)
+
+// loadLocales builds the message bundle from the embedded locales plus any
+// <lang>.yaml files in the config directory's locales folder, and selects
+// the language from --lang or the environment.
+func loadLocales() error {
+	bundle := i18n.NewBundle(language.English)
+	bundle.RegisterUnmarshalFunc("yaml", yaml.Unmarshal)
+
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if _, err := bundle.LoadMessageFileFS(localeFS, "locales/"+e.Name()); err != nil {
+			return err
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(configDir(), "locales", "*.yaml"))
+	for _, f := range files {
+		if _, err := bundle.LoadMessageFile(f); err != nil {
+			return fmt.Errorf("loading locale %s: %w", f, err)
+		}
+	}
+
+	localizer = i18n.NewLocalizer(bundle, preferredLanguages()...)
+	return nil
+}
+
+func preferredLanguages() []string {
+	if lang != "" {
+		return []string{lang}
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" || value == "C" || value == "POSIX" {
+			continue
+		}
+		value, _, _ = strings.Cut(value, ".")
+		return []string{strings.ReplaceAll(value, "_", "-")}
+	}
+	return nil
+}
+
+// tr formats a Printf-style message, using the translation for id in the
+// selected language when one exists.
+func tr(id, format string, args ...interface{}) string {
+	if localizer != nil {
+		msg, _ := localizer.Localize(&i18n.LocalizeConfig{DefaultMessage: &i18n.Message{ID: id, Other: format}})
+		if msg != "" {
+			format = msg
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// trError is tr for errors, wrapping the argument of a %w verb as
+// fmt.Errorf does.
+func trError(id, format string, args ...interface{}) error {
+	return fmt.Errorf(tr(id, format), args...)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const coingeckoCoinAPI = "/coins/%s?localization=false&tickers=false&community_data=false&developer_data=false&sparkline=false"
+
+var infoCurrency string
+
+// coinDetailResponse is the part of CoinGecko's coin endpoint we use. The
+// per-currency fields are keyed by the lowercase currency.
+type coinDetailResponse struct {
+	ID         string `json:"id"`
+	Symbol     string `json:"symbol"`
+	Name       string `json:"name"`
+	MarketData struct {
+		CurrentPrice        map[string]float64   `json:"current_price"`
+		MarketCap           map[string]float64   `json:"market_cap"`
+		TotalVolume         map[string]float64   `json:"total_volume"`
+		High24h             map[string]float64   `json:"high_24h"`
+		Low24h              map[string]float64   `json:"low_24h"`
+		ATH                 map[string]float64   `json:"ath"`
+		ATHChangePercentage map[string]float64   `json:"ath_change_percentage"`
+		ATHDate             map[string]time.Time `json:"ath_date"`
+		Change24h           float64              `json:"price_change_percentage_24h"`
+		Change7d            float64              `json:"price_change_percentage_7d"`
+		Change30d           float64              `json:"price_change_percentage_30d"`
+		MarketCapRank       int                  `json:"market_cap_rank"`
+		CirculatingSupply   float64              `json:"circulating_supply"`
+		TotalSupply         *float64             `json:"total_supply"`
+		MaxSupply           *float64             `json:"max_supply"`
+		LastUpdated         time.Time            `json:"last_updated"`
+	} `json:"market_data"`
+}
+
+type MarketInfo struct {
+	Coin              string    `json:"coin"`
+	Symbol            string    `json:"symbol"`
+	Name              string    `json:"name"`
+	Currency          string    `json:"currency"`
+	Price             float64   `json:"price"`
+	Rank              int       `json:"market_cap_rank,omitempty"`
+	MarketCap         float64   `json:"market_cap"`
+	Volume24h         float64   `json:"volume_24h"`
+	High24h           float64   `json:"high_24h,omitempty"`
+	Low24h            float64   `json:"low_24h,omitempty"`
+	Change24h         float64   `json:"change_24h_pct"`
+	Change7d          float64   `json:"change_7d_pct"`
+	Change30d         float64   `json:"change_30d_pct"`
+	CirculatingSupply float64   `json:"circulating_supply"`
+	TotalSupply       *float64  `json:"total_supply"`
+	MaxSupply         *float64  `json:"max_supply"`
+	ATH               float64   `json:"ath"`
+	ATHChange         float64   `json:"ath_change_pct"`
+	ATHDate           time.Time `json:"ath_date,omitempty"`
+	Updated           time.Time `json:"updated"`
+}
+
+func fetchMarketInfo(ctx context.Context, coin, currency string) (MarketInfo, error) {
+	var detail coinDetailResponse
+	if err := getJSON(ctx, providerURL("coingecko")+fmt.Sprintf(coingeckoCoinAPI, coin), "coingecko", &detail); err != nil {
+		return MarketInfo{}, err
+	}
+	md := detail.MarketData
+	price, ok := md.CurrentPrice[currency]
+	if !ok {
+		return MarketInfo{}, errors.New(tr("InfoNoMarketData", "no %s market data for %s", currency, coin))
+	}
+	return MarketInfo{
+		Coin:              detail.ID,
+		Symbol:            strings.ToUpper(detail.Symbol),
+		Name:              detail.Name,
+		Currency:          currency,
+		Price:             price,
+		Rank:              md.MarketCapRank,
+		MarketCap:         md.MarketCap[currency],
+		Volume24h:         md.TotalVolume[currency],
+		High24h:           md.High24h[currency],
+		Low24h:            md.Low24h[currency],
+		Change24h:         md.Change24h,
+		Change7d:          md.Change7d,
+		Change30d:         md.Change30d,
+		CirculatingSupply: md.CirculatingSupply,
+		TotalSupply:       md.TotalSupply,
+		MaxSupply:         md.MaxSupply,
+		ATH:               md.ATH[currency],
+		ATHChange:         md.ATHChangePercentage[currency],
+		ATHDate:           md.ATHDate[currency],
+		Updated:           md.LastUpdated,
+	}, nil
+}
+
+func formatSupply(supply *float64, symbol string) string {
+	if supply == nil || *supply <= 0 {
+		return "-"
+	}
+	return formatVolume(*supply) + " " + symbol
+}
+
+func printMarketInfo(info MarketInfo) error {
+	fmt.Printf("%s (%s)", info.Name, info.Symbol)
+	if info.Rank > 0 {
+		fmt.Print(tr("InfoRank", ", rank #%d", info.Rank))
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\n", tr("InfoPrice", "Price"), formatPrice(info.Price, info.Currency))
+	color := useColor(os.Stdout)
+	fmt.Fprintf(w, "%s\t%s\n", tr("Info24hChange", "24h change"), colorChange(fmt.Sprintf("%+.2f%%", info.Change24h), info.Change24h, color))
+	fmt.Fprintf(w, "%s\t%s\n", tr("Info7dChange", "7d change"), colorChange(fmt.Sprintf("%+.2f%%", info.Change7d), info.Change7d, color))
+	fmt.Fprintf(w, "%s\t%s\n", tr("Info30dChange", "30d change"), colorChange(fmt.Sprintf("%+.2f%%", info.Change30d), info.Change30d, color))
+	if info.High24h > 0 && info.Low24h > 0 {
+		fmt.Fprintf(w, "%s\t%s - %s\n", tr("Info24hRange", "24h range"), formatPrice(info.Low24h, info.Currency), formatPrice(info.High24h, info.Currency))
+	}
+	fmt.Fprintf(w, "%s\t%s\n", tr("InfoMarketCap", "Market cap"), formatMarketValue(info.MarketCap, info.Currency))
+	fmt.Fprintf(w, "%s\t%s\n", tr("Info24hVolume", "24h volume"), formatMarketValue(info.Volume24h, info.Currency))
+	fmt.Fprintf(w, "%s\t%s\n", tr("InfoCirculatingSupply", "Circulating supply"), formatSupply(&info.CirculatingSupply, info.Symbol))
+	fmt.Fprintf(w, "%s\t%s\n", tr("InfoTotalSupply", "Total supply"), formatSupply(info.TotalSupply, info.Symbol))
+	fmt.Fprintf(w, "%s\t%s\n", tr("InfoMaxSupply", "Max supply"), formatSupply(info.MaxSupply, info.Symbol))
+	if info.ATH > 0 {
+		ath := fmt.Sprintf("%s (%+.2f%%", formatPrice(info.ATH, info.Currency), info.ATHChange)
+		if !info.ATHDate.IsZero() {
+			ath += ", " + info.ATHDate.Format(time.DateOnly)
+		}
+		fmt.Fprintf(w, "%s\t%s)\n", tr("InfoATH", "All-time high"), ath)
+	}
+	if !info.Updated.IsZero() {
+		fmt.Fprintf(w, "%s\t%s\n", tr("InfoUpdated", "Updated"), info.Updated.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info <coin>",
+	Short: "Show a coin's market data: market cap, volume, supply, all-time high and price changes",
+	Example: `  crypto-cli info bitcoin
+  crypto-cli info eth -c eur -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		info, err := fetchMarketInfo(cmd.Context(), coin, strings.ToLower(infoCurrency))
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if outputFormat == "json" {
+			return printJSON(info)
+		}
+		return printMarketInfo(info)
+	},
+}
+
+func init() {
+	infoCmd.Flags().StringVarP(&infoCurrency, "vs-currency", "c", "usd", "currency to show prices and totals in")
+	infoCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.AddCommand(infoCmd)
+}
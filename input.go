@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// inputAmounts holds the amounts given next to coins in --file or stdin
+// input, keyed by the coin as written. A coin listed more than once gets
+// the sum of its amounts.
+var inputAmounts = make(map[string]float64)
+
+// readCoinList reads coin IDs separated by newlines, commas or spaces,
+// ignoring blank lines and # comments. A number after a coin is its amount,
+// so CSV rows such as "bitcoin,0.5" work; a leading "coin,amount" header
+// row is skipped.
+func readCoinList(r io.Reader) ([]string, error) {
+	var coins []string
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == ';'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		if first && isHeaderRow(fields) {
+			first = false
+			continue
+		}
+		first = false
+		for _, field := range fields {
+			field = strings.Trim(field, `"`)
+			amount, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				coins = append(coins, field)
+				continue
+			}
+			if len(coins) > 0 {
+				inputAmounts[coins[len(coins)-1]] += amount
+			}
+		}
+	}
+	return coins, scanner.Err()
+}
+
+func isHeaderRow(fields []string) bool {
+	for _, f := range fields {
+		switch strings.ToLower(strings.Trim(f, `"`)) {
+		case "coin", "id", "symbol", "amount", "quantity":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func readCoinFile(path string) ([]string, error) {
+	if path == "-" {
+		return readCoinList(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readCoinList(f)
+}
+
+// expandCoinArgs replaces a "-" argument with the coins read from stdin and
+// appends the coins from --file.
+func expandCoinArgs(args []string) ([]string, error) {
+	var coins []string
+	for _, arg := range args {
+		if arg != "-" {
+			coins = append(coins, arg)
+			continue
+		}
+		list, err := readCoinList(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		coins = append(coins, list...)
+	}
+	for _, path := range []string{coinsFile, legacyCoinsFile} {
+		if path == "" {
+			continue
+		}
+		list, err := readCoinFile(path)
+		if err != nil {
+			return nil, err
+		}
+		coins = append(coins, list...)
+	}
+	return coins, nil
+}
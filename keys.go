@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+const keyringService = "crypto-cli"
+
+var (
+	keyringMu    sync.Mutex
+	keyringCache = make(map[string]string)
+)
+
+// keyringKey returns the API key stored in the OS keyring for a provider,
+// or "" if there is none or no keyring is available. Lookups are cached for
+// the life of the process since fetchers ask for keys concurrently.
+func keyringKey(provider string) string {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	if key, ok := keyringCache[provider]; ok {
+		return key
+	}
+	key, err := keyring.Get(keyringService, provider)
+	if err != nil {
+		key = ""
+	}
+	keyringCache[provider] = key
+	return key
+}
+
+func knownProvider(name string) error {
+	if _, ok := providerBaseURLs[name]; ok {
+		return nil
+	}
+	if _, ok := pluginPaths[name]; ok {
+		return nil
+	}
+	return fmt.Errorf("unknown provider %q", name)
+}
+
+func readSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if isTerminal(os.Stdin) {
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(secret)), err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage provider API keys in the OS keyring",
+}
+
+var keysSetCmd = &cobra.Command{
+	Use:   "set <provider>",
+	Short: "Store a provider API key in the OS keyring",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if err := knownProvider(name); err != nil {
+			return err
+		}
+		key, err := readSecret(fmt.Sprintf("API key for %s: ", name))
+		if err != nil {
+			return err
+		}
+		if key == "" {
+			return errors.New("empty key, nothing stored")
+		}
+		if err := keyring.Set(keyringService, name, key); err != nil {
+			return fmt.Errorf("storing key in keyring: %w", err)
+		}
+		fmt.Printf("Stored API key for %s\n", name)
+		return nil
+	},
+}
+
+var keysDeleteCmd = &cobra.Command{
+	Use:   "delete <provider>",
+	Short: "Remove a provider API key from the OS keyring",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if err := keyring.Delete(keyringService, name); err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("no key stored for %s", name)
+			}
+			return err
+		}
+		fmt.Printf("Deleted API key for %s\n", name)
+		return nil
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show where each provider's API key comes from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, p := range providers {
+			source := "none"
+			switch {
+			case keyFlags[p.name] != nil && *keyFlags[p.name] != "":
+				source = "flag, " + envName(keyFlagNames[p.name]) + " or default." + keyFlagNames[p.name]
+			case os.Getenv(envName(p.name+"_key")) != "":
+				source = "environment"
+			case keyringKey(p.name) != "":
+				source = "keyring"
+			case secrets[p.name] != "":
+				source = "encrypted config"
+			case config.GetString("providers."+p.name+".key") != "":
+				source = "config"
+			}
+			fmt.Printf("%-14s %s\n", p.name, source)
+		}
+	},
+}
+
+// keyFlagNames are the flags that pass a provider's API key directly,
+// also read from their CRYPTO_CLI_* variables like any other flag.
+var keyFlagNames = map[string]string{
+	"coingecko":     "coingecko-api-key",
+	"coinmarketcap": "cmc-api-key",
+	"cryptocompare": "cryptocompare-api-key",
+}
+
+var keyFlags = make(map[string]*string)
+
+// coingeckoPlan is "pro" for paid CoinGecko keys, which go to the pro API
+// with their own header and a higher rate limit, or "demo".
+var coingeckoPlan string
+
+func coingeckoPro() bool {
+	return coingeckoPlan == "pro"
+}
+
+func checkCoinGeckoPlan() error {
+	coingeckoPlan = strings.ToLower(coingeckoPlan)
+	if coingeckoPlan != "demo" && coingeckoPlan != "pro" {
+		return fmt.Errorf("invalid --coingecko-plan %q: must be demo or pro", coingeckoPlan)
+	}
+	return nil
+}
+
+func init() {
+	for _, p := range providers {
+		if flag, ok := keyFlagNames[p.name]; ok {
+			keyFlags[p.name] = rootCmd.PersistentFlags().String(flag, "", p.label+" API key (overrides the keyring and config)")
+		}
+	}
+	rootCmd.PersistentFlags().StringVar(&coingeckoPlan, "coingecko-plan", "demo", "CoinGecko plan of the API key: demo, or pro for paid keys, which use pro-api.coingecko.com")
+	keysCmd.AddCommand(keysSetCmd, keysDeleteCmd, keysListCmd)
+	rootCmd.AddCommand(keysCmd)
+}
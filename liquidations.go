@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	coinglassBaseURL     = "https://open-api-v3.coinglass.com"
+	coinglassLiquidation = "/api/futures/liquidation/coin-list"
+)
+
+var liquidationWindows = []string{"1h", "4h", "12h", "24h"}
+
+var (
+	liquidationWindow string
+	liquidationLimit  int
+)
+
+type Liquidations struct {
+	Symbol string  `json:"symbol"`
+	Window string  `json:"window"`
+	Long   float64 `json:"long_usd"`
+	Short  float64 `json:"short_usd"`
+	Total  float64 `json:"total_usd"`
+}
+
+// longShare returns the percentage of the liquidated volume that was longs.
+func (l Liquidations) longShare() float64 {
+	if l.Total == 0 {
+		return 0
+	}
+	return l.Long / l.Total * 100
+}
+
+func fetchLiquidations(ctx context.Context, window string) ([]Liquidations, error) {
+	if providerKey("coinglass") == "" {
+		return nil, errors.New("coinglass: no API key configured")
+	}
+	var resp struct {
+		Code string                   `json:"code"`
+		Msg  string                   `json:"msg"`
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("coinglass")+coinglassLiquidation, "coinglass", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Code != "0" {
+		return nil, fmt.Errorf("coinglass: %s", resp.Msg)
+	}
+
+	var list []Liquidations
+	for _, item := range resp.Data {
+		symbol, _ := item["symbol"].(string)
+		long, _ := item["longLiquidationUsd"+window].(float64)
+		short, _ := item["shortLiquidationUsd"+window].(float64)
+		list = append(list, Liquidations{Symbol: symbol, Window: window, Long: long, Short: short, Total: long + short})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Total > list[j].Total })
+	return list, nil
+}
+
+// filterLiquidations keeps the entries matching the given coins, which may
+// be registry IDs or ticker symbols.
+func filterLiquidations(list []Liquidations, coins []string) []Liquidations {
+	want := make(map[string]bool)
+	for _, c := range coins {
+		want[strings.ToUpper(c)] = true
+		want[coinSymbol(resolveAlias(c))] = true
+	}
+	var filtered []Liquidations
+	for _, l := range list {
+		if want[strings.ToUpper(l.Symbol)] {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+func balanceLabel(l Liquidations, color bool) string {
+	share := l.longShare()
+	label := fmt.Sprintf("%.0f%% long / %.0f%% short", share, 100-share)
+	if !color {
+		return label
+	}
+	switch {
+	case share > 50:
+		return "\033[31m" + label + "\033[0m"
+	case share < 50:
+		return "\033[32m" + label + "\033[0m"
+	}
+	return label
+}
+
+var liquidationsCmd = &cobra.Command{
+	Use:   "liquidations [coin...]",
+	Short: "Summarize long and short futures liquidations per coin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		valid := false
+		for _, w := range liquidationWindows {
+			valid = valid || w == liquidationWindow
+		}
+		if !valid {
+			return fmt.Errorf("invalid --window %q: must be one of %s", liquidationWindow, strings.Join(liquidationWindows, ", "))
+		}
+
+		args, err := expandWatchlists(args)
+		if err != nil {
+			return err
+		}
+		list, err := fetchLiquidations(cmd.Context(), liquidationWindow)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if len(args) > 0 {
+			list = filterLiquidations(list, args)
+			if len(list) == 0 {
+				return withExitCode(exitCoinNotFound, fmt.Errorf("no liquidation data for %s", strings.Join(args, ", ")))
+			}
+		} else if liquidationLimit > 0 && len(list) > liquidationLimit {
+			list = list[:liquidationLimit]
+		}
+		if outputFormat == "json" {
+			return printJSON(list)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "COIN\tLONGS\tSHORTS\tTOTAL\tBALANCE (%s)\n", liquidationWindow)
+		for _, l := range list {
+			fmt.Fprintf(w, "%s\t$%.0f\t$%.0f\t$%.0f\t%s\n", l.Symbol, l.Long, l.Short, l.Total, balanceLabel(l, useColor(os.Stdout)))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	liquidationsCmd.Flags().StringVar(&liquidationWindow, "window", "24h", "time window: "+strings.Join(liquidationWindows, ", "))
+	liquidationsCmd.Flags().IntVar(&liquidationLimit, "limit", 10, "number of coins to show when none are given")
+	rootCmd.AddCommand(liquidationsCmd)
+}
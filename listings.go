@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	coingeckoNewCoinsAPI      = "/coins/list/new"
+	binanceFuturesExchangeAPI = "/fapi/v1/exchangeInfo"
+	binanceFuturesTickerAPI   = "/fapi/v1/ticker/24hr?symbol=%s"
+)
+
+var listingDays int
+
+type Listing struct {
+	Coin     string    `json:"coin"`
+	Symbol   string    `json:"symbol"`
+	Name     string    `json:"name,omitempty"`
+	Source   string    `json:"source"`
+	ListedAt time.Time `json:"listed_at"`
+	Price    float64   `json:"price,omitempty"`
+	Volume   float64   `json:"volume_24h,omitempty"`
+}
+
+func fetchCoingeckoListings(ctx context.Context, since time.Time) ([]Listing, error) {
+	var coins []struct {
+		ID          string `json:"id"`
+		Symbol      string `json:"symbol"`
+		Name        string `json:"name"`
+		ActivatedAt int64  `json:"activated_at"`
+	}
+	if err := getJSON(ctx, providerURL("coingecko")+coingeckoNewCoinsAPI, "coingecko", &coins); err != nil {
+		return nil, err
+	}
+
+	var listings []Listing
+	var ids []string
+	for _, c := range coins {
+		listed := time.Unix(c.ActivatedAt, 0)
+		if listed.Before(since) {
+			continue
+		}
+		listings = append(listings, Listing{Coin: c.ID, Symbol: strings.ToUpper(c.Symbol), Name: c.Name, Source: "CoinGecko", ListedAt: listed})
+		ids = append(ids, c.ID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	markets, err := fetchMarkets(ctx, ids)
+	if err != nil {
+		return listings, nil
+	}
+	byID := make(map[string]coinMarket)
+	for _, m := range markets {
+		byID[m.ID] = m
+	}
+	for i := range listings {
+		m := byID[listings[i].Coin]
+		listings[i].Price, listings[i].Volume = m.Price, m.Volume
+	}
+	return listings, nil
+}
+
+// fetchBinanceListings reports perpetual contracts onboarded on Binance
+// Futures since the given time.
+func fetchBinanceListings(ctx context.Context, since time.Time) ([]Listing, error) {
+	var info struct {
+		Symbols []struct {
+			Symbol       string `json:"symbol"`
+			BaseAsset    string `json:"baseAsset"`
+			ContractType string `json:"contractType"`
+			Status       string `json:"status"`
+			OnboardDate  int64  `json:"onboardDate"`
+		} `json:"symbols"`
+	}
+	if err := getJSON(ctx, providerURL("binance-futures")+binanceFuturesExchangeAPI, "binance-futures", &info); err != nil {
+		return nil, err
+	}
+
+	var listings []Listing
+	for _, s := range info.Symbols {
+		listed := time.UnixMilli(s.OnboardDate)
+		if s.ContractType != "PERPETUAL" || s.Status != "TRADING" || listed.Before(since) {
+			continue
+		}
+		l := Listing{Coin: s.Symbol, Symbol: s.BaseAsset, Source: "Binance Futures", ListedAt: listed}
+		var ticker struct {
+			LastPrice   string `json:"lastPrice"`
+			QuoteVolume string `json:"quoteVolume"`
+		}
+		if err := getJSON(ctx, providerURL("binance-futures")+fmt.Sprintf(binanceFuturesTickerAPI, s.Symbol), "binance-futures", &ticker); err == nil {
+			l.Price, l.Volume = parseFloat(ticker.LastPrice), parseFloat(ticker.QuoteVolume)
+		}
+		listings = append(listings, l)
+	}
+	return listings, nil
+}
+
+var listingSources = []func(context.Context, time.Time) ([]Listing, error){fetchCoingeckoListings, fetchBinanceListings}
+
+func fetchListings(ctx context.Context, since time.Time) ([]Listing, []error) {
+	sources := listingSources
+	results := make([][]Listing, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	wg.Add(len(sources))
+	for i, fetch := range sources {
+		go func(i int, fetch func(context.Context, time.Time) ([]Listing, error)) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(ctx, since)
+		}(i, fetch)
+	}
+	wg.Wait()
+
+	var listings []Listing
+	var failed []error
+	for i := range sources {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+		}
+		listings = append(listings, results[i]...)
+	}
+	sort.Slice(listings, func(i, j int) bool { return listings[i].ListedAt.After(listings[j].ListedAt) })
+	return listings, failed
+}
+
+var listingsCmd = &cobra.Command{
+	Use:   "listings",
+	Short: "Show recently listed coins with their prices and volumes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if listingDays <= 0 {
+			return fmt.Errorf("--days must be positive")
+		}
+		listings, errs := fetchListings(cmd.Context(), time.Now().AddDate(0, 0, -listingDays))
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if len(errs) == len(listingSources) {
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no listing source available"))
+		}
+		if outputFormat == "json" {
+			return printJSON(listings)
+		}
+		if len(listings) == 0 {
+			fmt.Printf("No new listings in the last %d days\n", listingDays)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "LISTED\tSYMBOL\tCOIN\tSOURCE\tPRICE\tVOLUME\n")
+		for _, l := range listings {
+			price, volume := "-", "-"
+			if l.Price > 0 {
+				price = fmt.Sprintf("$%.6g", l.Price)
+			}
+			if l.Volume > 0 {
+				volume = fmt.Sprintf("$%.0f", l.Volume)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", l.ListedAt.Format("2006-01-02"), l.Symbol, l.Coin, l.Source, price, volume)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	listingsCmd.Flags().IntVar(&listingDays, "days", 7, "how many days back to look")
+	rootCmd.AddCommand(listingsCmd)
+}
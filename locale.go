@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	localemessage "golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+var (
+	localeFlag      string
+	humanizeNumbers bool
+
+	// numberPrinter formats numbers for --locale; nil keeps the plain
+	// 1234.56 form that scripts parse.
+	numberPrinter *localemessage.Printer
+	numberLocale  language.Tag
+
+	timezoneFlag string
+	showTime     bool
+)
+
+// symbolAfterLanguages write the currency symbol after the amount, as in
+// "1.234,56 €". Regions that differ from their language are listed in
+// symbolBeforeRegions.
+var symbolAfterLanguages = map[string]bool{
+	"bg": true, "cs": true, "da": true, "de": true, "el": true, "es": true,
+	"et": true, "fi": true, "fr": true, "hr": true, "hu": true, "it": true,
+	"lt": true, "lv": true, "nb": true, "no": true, "pl": true, "pt": true,
+	"ro": true, "ru": true, "sk": true, "sl": true, "sv": true, "uk": true,
+}
+
+var symbolBeforeRegions = map[string]bool{"de-AT": true, "de-CH": true, "de-LI": true, "pt-BR": true}
+
+// setupLocale parses --locale. "auto" takes the locale from LC_ALL,
+// LC_MONETARY or LANG.
+func setupLocale() error {
+	name := localeFlag
+	if name == "auto" {
+		name = ""
+		for _, env := range []string{"LC_ALL", "LC_MONETARY", "LC_NUMERIC", "LANG"} {
+			value := os.Getenv(env)
+			if value == "" || value == "C" || value == "POSIX" {
+				continue
+			}
+			value, _, _ = strings.Cut(value, ".")
+			name = strings.ReplaceAll(value, "_", "-")
+			break
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	tag, err := language.Parse(name)
+	if err != nil {
+		return fmt.Errorf("invalid --locale %q: use a language tag such as en-US or de-DE", name)
+	}
+	numberLocale, numberPrinter = tag, localemessage.NewPrinter(tag)
+	return nil
+}
+
+// setupTimezone makes --tz the zone every time is shown in, in place of
+// the system's local zone.
+func setupTimezone() error {
+	if timezoneFlag == "" || strings.EqualFold(timezoneFlag, "local") {
+		return nil
+	}
+	loc, err := time.LoadLocation(timezoneFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --tz %q: use an IANA zone such as Europe/Kyiv, UTC or local", timezoneFlag)
+	}
+	time.Local = loc
+	return nil
+}
+
+// machineTime is t for JSON and CSV: in UTC, or in the --tz zone when one
+// is given.
+func machineTime(t time.Time) time.Time {
+	if timezoneFlag == "" || strings.EqualFold(timezoneFlag, "local") {
+		return t.UTC()
+	}
+	return t.Local()
+}
+
+// localizeNumber renders v with exactly decimals places, with the locale's
+// separators when --locale is set. Negative decimals show as many as v
+// needs.
+func localizeNumber(v float64, decimals int) string {
+	if numberPrinter == nil {
+		return strconv.FormatFloat(v, 'f', decimals, 64)
+	}
+	if decimals < 0 {
+		_, frac, _ := strings.Cut(strconv.FormatFloat(v, 'f', -1, 64), ".")
+		decimals = len(frac)
+	}
+	return numberPrinter.Sprint(number.Decimal(v, number.Scale(decimals)))
+}
+
+var humanizeUnits = []struct {
+	size   float64
+	suffix string
+}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "k"}}
+
+// humanizeNumber abbreviates v to three significant digits, e.g. 67.2k or
+// 1.23T. ok is false below 1000, which is left to the caller.
+func humanizeNumber(v float64) (string, bool) {
+	abs := math.Abs(v)
+	for i, unit := range humanizeUnits {
+		if abs < unit.size {
+			continue
+		}
+		scaled := abs / unit.size
+		decimals := max(2-int(math.Floor(math.Log10(scaled))), 0)
+		rounded := math.Round(scaled*math.Pow10(decimals)) / math.Pow10(decimals)
+		// 999.96k rounds to 1000k, which reads better as 1M.
+		if rounded >= 1000 && i > 0 {
+			rounded, unit = rounded/1000, humanizeUnits[i-1]
+		}
+		return localizeNumber(math.Copysign(rounded, v), -1) + unit.suffix, true
+	}
+	return "", false
+}
+
+// currencySymbol returns the symbol priced amounts are written with, and
+// false for crypto quote currencies, which are written as a code after
+// the amount.
+func currencySymbol(code string) (string, bool) {
+	if numberPrinter == nil {
+		symbol, ok := currencySymbols[code]
+		return symbol, ok
+	}
+	unit, err := currency.ParseISO(code)
+	if err != nil || strings.HasPrefix(unit.String(), "X") {
+		return "", false
+	}
+	return numberPrinter.Sprint(currency.NarrowSymbol(unit)), true
+}
+
+// withCurrency places the symbol before or after the amount as the
+// locale does; without --locale it always goes before.
+func withCurrency(amount, code string) string {
+	symbol, ok := currencySymbol(code)
+	if !ok {
+		return amount + " " + strings.ToUpper(code)
+	}
+	if numberPrinter == nil {
+		return symbol + amount
+	}
+	base, _ := numberLocale.Base()
+	region, _ := numberLocale.Region()
+	if symbolAfterLanguages[base.String()] && !symbolBeforeRegions[base.String()+"-"+region.String()] {
+		return amount + " " + symbol
+	}
+	sign := ""
+	if rest, ok := strings.CutPrefix(amount, "-"); ok {
+		sign, amount = "-", rest
+	}
+	// Letter codes such as CHF are always set apart from the amount.
+	if len([]rune(symbol)) > 1 || symbolBeforeRegions[base.String()+"-"+region.String()] || base.String() == "nl" {
+		return sign + symbol + " " + amount
+	}
+	return sign + symbol + amount
+}
+
+// formatMarketValue renders a market cap or trading volume: abbreviated
+// with the currency code, or like a price with --humanize.
+func formatMarketValue(v float64, currency string) string {
+	if humanizeNumbers && v > 0 {
+		return formatPrice(v, currency)
+	}
+	return formatVolume(v) + " " + strings.ToUpper(currency)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+var logLevel string
+
+// logger writes diagnostics to stderr. It stays at the default level,
+// warnings and errors only, until setupLogging has read the flags.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+// setupLogging builds the logger from --log-level; -v without a level
+// turns on debug logging.
+func setupLogging(cmd *cobra.Command) error {
+	level := logLevel
+	if verbose && !cmd.Flags().Changed("log-level") {
+		level = "debug"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", level)
+	}
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l}))
+	return nil
+}
+
+// secretParams are query parameters whose values are left out of logged
+// URLs.
+var secretParams = []string{"auth_token", "api_key", "apikey", "key", "token"}
+
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	redacted := false
+	for name := range q {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				q.Set(name, "REDACTED")
+				redacted = true
+			}
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func logRequest(provider string, u *url.URL, status int, d time.Duration, err error) {
+	if err != nil {
+		logger.Info("provider request failed", "provider", provider, "url", redactURL(u), "latency", d.Round(time.Microsecond), "error", err)
+		return
+	}
+	logger.Debug("provider request", "provider", provider, "url", redactURL(u), "status", status, "latency", d.Round(time.Microsecond))
+}
+
+// logQuote records every provider's answer for the quote, the outliers
+// left out of an aggregate and which price was used.
+func logQuote(q CoinQuote) {
+	for _, r := range q.results {
+		switch {
+		case r.Error != "":
+			logger.Info("provider result", "coin", q.Coin, "currency", q.Currency, "provider", r.Source, "kind", string(r.Kind), "error", r.Error, "latency", r.Duration.Round(time.Microsecond))
+		case r.Stale:
+			logger.Info("provider result", "coin", q.Coin, "currency", q.Currency, "provider", r.Source, "price", r.Price, "stale", true, "age", r.Age().Round(time.Second))
+		default:
+			logger.Debug("provider result", "coin", q.Coin, "currency", q.Currency, "provider", r.Source, "price", r.Price, "latency", r.Duration.Round(time.Microsecond))
+		}
+	}
+	if q.Aggregate != nil {
+		for _, r := range q.Aggregate.Excluded {
+			logger.Info("provider result excluded", "coin", q.Coin, "currency", q.Currency, "provider", r.Source, "price", r.Price,
+				"median", q.Aggregate.Median, "deviation_pct", math.Round(pricefeed.Deviation(r.Price, q.Aggregate.Median)*100)/100)
+		}
+	}
+	if q.err != nil {
+		logger.Debug("no price selected", "coin", q.Coin, "currency", q.Currency, "error", q.err)
+		return
+	}
+	logger.Debug("price selected", "coin", q.Coin, "currency", q.Currency, "source", q.Source, "price", q.Price, "reason", q.Reason)
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "warn", "Log to stderr at this level: debug (every provider request), info (failures and retries), warn or error")
+}
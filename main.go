@@ -1,156 +1,622 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"github.com/spf13/cobra"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
-)
 
-const (
-	coingeckoAPI     = "https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=usd"
-	coinmarketcapAPI = "https://api.coinmarketcap.com/v1/ticker/%s/"
-	cryptocompareAPI = "https://min-api.cryptocompare.com/data/price?fsym=%s&tsyms=USD"
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
 )
 
-type CryptoPrice struct {
-	USD float64 `json:"usd"`
+var apiKeyHeaders = map[string]string{
+	"coingecko":     "x-cg-demo-api-key",
+	"coinmarketcap": "X-CMC_PRO_API_KEY",
+	"cryptocompare": "authorization",
+	"reservoir":     "x-api-key",
+	"opensea":       "X-API-KEY",
+	"coinglass":     "CG-API-KEY",
+	"tokenomist":    "x-api-key",
+	"coinmarketcal": "x-api-key",
+	"lunarcrush":    "Authorization",
+	"blocknative":   "Authorization",
 }
 
-type CoinMarketCapResponse struct {
-	PriceUSD string `json:"price_usd"`
+var apiKeyPrefixes = map[string]string{
+	"cryptocompare": "Apikey ",
+	"lunarcrush":    "Bearer ",
 }
 
-type CryptoCompareResponse struct {
-	USD float64 `json:"USD"`
+// providerClient returns an HTTP client that applies the provider's
+// configured timeout, retries and backoff, falling back to --timeout,
+// --retries and --retry-backoff, its rate limit to every attempt and its
+// circuit breaker to every request.
+func providerClient(provider string) *http.Client {
+	settings := settingsFor(provider)
+	if settings.Timeout == 0 {
+		settings.Timeout = requestTimeout
+	}
+	if settings.Retries == nil {
+		settings.Retries = &retries
+	}
+	if settings.RetryBackoff == 0 {
+		settings.RetryBackoff = retryBackoff
+	}
+	if settings.RateLimit == 0 {
+		settings.RateLimit = defaultRateLimit(provider)
+	}
+	if settings.Burst <= 0 {
+		settings.Burst = defaultBurst
+	}
+	if settings.BreakerThreshold == 0 {
+		settings.BreakerThreshold = defaultBreakerThreshold
+	}
+	if settings.BreakerCooldown == 0 {
+		settings.BreakerCooldown = defaultBreakerCooldown
+	}
+	return &http.Client{
+		Timeout: settings.Timeout,
+		Transport: breakerTransport{
+			provider:  provider,
+			threshold: settings.BreakerThreshold,
+			cooldown:  settings.BreakerCooldown,
+			next: retryTransport{
+				next:    rateLimitedTransport{provider, settings.RateLimit, settings.Burst},
+				retries: *settings.Retries,
+				backoff: settings.RetryBackoff,
+			},
+		},
+	}
 }
 
-type PriceResult struct {
-	Price    float64
-	Source   string
-	Duration time.Duration
+func httpGet(ctx context.Context, url, provider string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if header, ok := apiKeyHeaders[provider]; ok {
+		if provider == "coingecko" && coingeckoPro() {
+			header = "x-cg-pro-api-key"
+		}
+		if key := providerKey(provider); key != "" {
+			req.Header.Set(header, apiKeyPrefixes[provider]+key)
+		}
+	}
+	return providerClient(provider).Do(req)
 }
 
-func fetchCryptoPriceFromCoingecko(crypto string, ch chan<- PriceResult, wg *sync.WaitGroup) {
-	defer wg.Done()
-	url := fmt.Sprintf(coingeckoAPI, crypto)
-	start := time.Now()
-	resp, err := http.Get(url)
-	duration := time.Since(start)
+// statusError reports an answer other than 200 OK the way pricefeed
+// does, so that a 429 from any API ends the command with exitRateLimited.
+func statusError(provider string, resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("%s: %w (%s)", provider, pricefeed.ErrRateLimited, resp.Status)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%s: %w (%s)", provider, pricefeed.ErrUnreachable, resp.Status)
+	}
+	return fmt.Errorf("%s: %w", provider, &pricefeed.StatusError{Code: resp.StatusCode, Status: resp.Status})
+}
+
+func getJSON(ctx context.Context, url, provider string, v interface{}) error {
+	resp, err := httpGet(ctx, url, provider)
 	if err != nil {
-		ch <- PriceResult{0, "CoinGecko", duration}
-		return
+		return err
 	}
 	defer resp.Body.Close()
-
-	var result map[string]CryptoPrice
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		ch <- PriceResult{0, "CoinGecko", duration}
-		return
+	if resp.StatusCode != http.StatusOK {
+		return statusError(provider, resp)
 	}
-
-	price, ok := result[crypto]
-	if ok {
-		ch <- PriceResult{price.USD, "CoinGecko", duration}
-	} else {
-		ch <- PriceResult{0, "CoinGecko", duration}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", provider, err)
 	}
+	return nil
 }
 
-func fetchCryptoPriceFromCoinMarketCap(crypto string, ch chan<- PriceResult, wg *sync.WaitGroup) {
-	defer wg.Done()
-	url := fmt.Sprintf(coinmarketcapAPI, crypto)
-	start := time.Now()
-	resp, err := http.Get(url)
-	duration := time.Since(start)
+func getText(ctx context.Context, url, provider string) (string, error) {
+	resp, err := httpGet(ctx, url, provider)
 	if err != nil {
-		ch <- PriceResult{0, "CoinMarketCap", duration}
-		return
+		return "", err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(provider, resp)
+	}
+	body, err := io.ReadAll(resp.Body)
+	return strings.TrimSpace(string(body)), err
+}
 
-	var result []CoinMarketCapResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		ch <- PriceResult{0, "CoinMarketCap", duration}
-		return
+type provider struct {
+	name        string
+	label       string
+	keyRequired bool
+}
+
+var providers = []provider{
+	{"coingecko", "CoinGecko", false},
+	{"coinmarketcap", "CoinMarketCap", true},
+	{"cryptocompare", "CryptoCompare", false},
+	{"binance", "Binance", false},
+	{"kraken", "Kraken", false},
+	{"coinbase", "Coinbase", false},
+}
+
+var providerBaseURLs = map[string]string{
+	"coingecko":     pricefeed.CoinGeckoBaseURL,
+	"coinmarketcap": pricefeed.CoinMarketCapBaseURL,
+	"cryptocompare": pricefeed.CryptoCompareBaseURL,
+	"binance":       pricefeed.BinanceBaseURL,
+	"kraken":        pricefeed.KrakenBaseURL,
+	"coinbase":      pricefeed.CoinbaseBaseURL,
+	"reservoir":     reservoirBaseURL,
+	"opensea":       openseaBaseURL,
+
+	"binance-futures":   binanceFuturesBaseURL,
+	"bybit":             bybitBaseURL,
+	"okx":               okxBaseURL,
+	"coinbase-exchange": coinbaseExchangeBaseURL,
+	"coinglass":         coinglassBaseURL,
+
+	"blockchain":       blockchainBaseURL,
+	"mempool":          mempoolBaseURL,
+	"etherscan":        etherscanBaseURL,
+	"blocknative":      blocknativeBaseURL,
+	"alternative":      alternativeBaseURL,
+	"tokenomist":       tokenomistBaseURL,
+	"coinmarketcal":    coinmarketcalBaseURL,
+	"cryptopanic":      cryptopanicBaseURL,
+	"lunarcrush":       lunarcrushBaseURL,
+	"defillama-yields": defillamaYieldsBaseURL,
+	"ensideas":         ensideasBaseURL,
+	"ethplorer":        ethplorerBaseURL,
+	"telegram":         telegramBaseURL,
+	"fx":               fxBaseURL,
+}
+
+// enabledProviders returns the providers named by --providers, or else
+// those not disabled in the config, less any --exclude-providers.
+func enabledProviders() []provider {
+	var enabled []provider
+	for _, p := range providers {
+		if containsFold(excludedProviders, p.name) {
+			continue
+		}
+		if len(selectedProviders) > 0 {
+			if containsFold(selectedProviders, p.name) {
+				enabled = append(enabled, p)
+			}
+		} else if settingsFor(p.name).enabled() {
+			enabled = append(enabled, p)
+		}
 	}
+	return enabled
+}
 
-	if len(result) > 0 {
-		var price float64
-		fmt.Sscanf(result[0].PriceUSD, "%f", &price)
-		ch <- PriceResult{price, "CoinMarketCap", duration}
-	} else {
-		ch <- PriceResult{0, "CoinMarketCap", duration}
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
 	}
+	return false
 }
 
-func fetchCryptoPriceFromCryptoCompare(crypto string, ch chan<- PriceResult, wg *sync.WaitGroup) {
-	defer wg.Done()
-	url := fmt.Sprintf(cryptocompareAPI, crypto)
-	start := time.Now()
-	resp, err := http.Get(url)
-	duration := time.Since(start)
-	if err != nil {
-		ch <- PriceResult{0, "CryptoCompare", duration}
-		return
+// feedProvider builds the pricefeed provider for p from its config, or
+// the synthetic one under --mock.
+func feedProvider(p provider) pricefeed.Provider {
+	if mockMode {
+		return mockProvider{p}
 	}
-	defer resp.Body.Close()
+	if path, ok := pluginPaths[p.name]; ok {
+		return &pricefeed.Exec{Label: p.label, Path: path, Env: pluginEnv(p)}
+	}
+	base, key, client := providerURL(p.name), providerKey(p.name), providerClient(p.name)
+	switch p.name {
+	case "coinmarketcap":
+		return &pricefeed.CoinMarketCap{BaseURL: base, APIKey: key, HTTPClient: client}
+	case "cryptocompare":
+		return &pricefeed.CryptoCompare{BaseURL: base, APIKey: key, HTTPClient: client}
+	case "binance":
+		return &pricefeed.Binance{BaseURL: base, Symbol: coinSymbol, HTTPClient: client}
+	case "kraken":
+		return &pricefeed.Kraken{BaseURL: base, Symbol: coinSymbol, HTTPClient: client}
+	case "coinbase":
+		return &pricefeed.Coinbase{BaseURL: base, Symbol: coinSymbol, HTTPClient: client}
+	}
+	return &pricefeed.CoinGecko{BaseURL: base, APIKey: key, Pro: coingeckoPro(), HTTPClient: client}
+}
 
-	var result CryptoCompareResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		ch <- PriceResult{0, "CryptoCompare", duration}
+// activeProviders returns the enabled providers in --priority order, with
+// providers left out of the list last, skipping those that need an API key
+// and have none.
+func activeProviders() []provider {
+	enabled := enabledProviders()
+	sort.SliceStable(enabled, func(i, j int) bool {
+		return providerRank(enabled[i].name) < providerRank(enabled[j].name)
+	})
+	var active []provider
+	for _, p := range enabled {
+		if p.keyRequired && !mockMode && !offline && providerKey(p.name) == "" {
+			warnKeyless(p)
+			continue
+		}
+		active = append(active, p)
+	}
+	return active
+}
+
+// priceClient returns a client over the active providers, leaving out
+// those the coin map turns off for coin.
+func priceClient(coin string) *pricefeed.Client {
+	client := pricefeed.NewClient()
+	for _, p := range activeProviders() {
+		if coinProviderOff(p.name, coin) {
+			continue
+		}
+		fp := pricefeed.Provider(mappedProvider{feedProvider(p), p.name})
+		if metricsEnabled {
+			fp = meteredProvider{fp, p.name}
+		}
+		fp = fxProvider{prefetchProvider{fp, p.name}}
+		if !mockMode && (offline || !noCache && cacheTTL > 0) {
+			fp = cachingProvider{fp, p.name}
+		}
+		client.Providers = append(client.Providers, fp)
+	}
+	client.MaxAge = maxAge
+	if !mockMode {
+		client.OnResult = recordProviderResult
+	}
+	return client
+}
+
+var (
+	keylessMu     sync.Mutex
+	keylessWarned = make(map[string]bool)
+)
+
+// warnKeyless explains, once per run, that a provider needing an API key
+// is skipped because it has none.
+func warnKeyless(p provider) {
+	keylessMu.Lock()
+	defer keylessMu.Unlock()
+	if keylessWarned[p.name] || quiet {
 		return
 	}
+	keylessWarned[p.name] = true
+	fmt.Fprint(os.Stderr, tr("KeylessProvider", "Warning: skipping %s, which requires an API key: pass %s, set %s, or disable it with providers.%s.enabled: false\n",
+		p.label, "--"+keyFlagNames[p.name], envName(keyFlagNames[p.name]), p.name))
+}
 
-	ch <- PriceResult{result.USD, "CryptoCompare", duration}
+func providerRank(source string) int {
+	for i, name := range priorityOrder {
+		if strings.EqualFold(name, source) {
+			return i
+		}
+	}
+	return len(priorityOrder)
 }
 
-func fetchCryptoPriceConcurrently(crypto string) PriceResult {
-	ch := make(chan PriceResult, 3)
-	var wg sync.WaitGroup
+func validatePriority() error {
+	return checkProviderNames(priorityOrder, "priority list")
+}
 
-	wg.Add(3)
-	go fetchCryptoPriceFromCoingecko(crypto, ch, &wg)
-	go fetchCryptoPriceFromCoinMarketCap(crypto, ch, &wg)
-	go fetchCryptoPriceFromCryptoCompare(crypto, ch, &wg)
+func checkProviderNames(names []string, where string) error {
+	for _, name := range names {
+		known := false
+		for _, p := range providers {
+			known = known || strings.EqualFold(p.name, name)
+		}
+		if !known {
+			return fmt.Errorf("unknown provider %q in %s", name, where)
+		}
+	}
+	return nil
+}
+
+// selectionReason explains why a single-source result was chosen, for
+// --verbose output.
+func selectionReason(mode string, selected pricefeed.Result, results []pricefeed.Result) string {
+	if !selected.Usable() {
+		return "no provider returned a usable price"
+	}
+	switch mode {
+	case "quorum":
+		return quorumReason(selected, results)
+	case "preferred":
+		return preferredReason(selected)
+	}
+	if mode == "first" {
+		return tr("ReasonFirst", "%s was the first provider to return a usable price (%s)", selected.Source, selected.Duration)
+	}
 
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
+	var skipped []string
+	for _, r := range results {
+		if providerRank(r.Source) < providerRank(selected.Source) {
+			skipped = append(skipped, r.Source)
+		}
+	}
+	order := strings.Join(priorityOrder, " > ")
+	if len(skipped) == 0 {
+		return tr("ReasonPriority", "%s is the highest-priority provider (%s)", selected.Source, order)
+	}
+	return tr("ReasonPrioritySkipped", "%s is the highest-priority provider with a usable price (%s); no price from %s", selected.Source, order, strings.Join(skipped, ", "))
+}
 
-	for result := range ch {
-		if result.Price > 0 {
-			return result
+func firstUsable(results []pricefeed.Result) pricefeed.Result {
+	best := pricefeed.Result{Source: "None"}
+	for _, r := range results {
+		if r.Usable() && (!best.Usable() || r.Duration < best.Duration) {
+			best = r
 		}
 	}
+	return best
+}
 
-	return PriceResult{0, "None", 0}
+func selectByPriority(results []pricefeed.Result) pricefeed.Result {
+	best := pricefeed.Result{Source: "None"}
+	bestRank := len(priorityOrder) + 1
+	for _, r := range results {
+		if rank := providerRank(r.Source); r.Usable() && rank < bestRank {
+			best, bestRank = r, rank
+		}
+	}
+	return best
+}
+
+func countUsable(results []pricefeed.Result) int {
+	n := 0
+	for _, r := range results {
+		if r.Usable() {
+			n++
+		}
+	}
+	return n
+}
+
+var (
+	aggregateMode       string
+	maxDeviation        float64
+	divergenceThreshold float64
+	verbose             bool
+	quiet               bool
+	quietDecimals       = autoDecimals
+	priorityOrder       []string
+	selectedProviders   []string
+	excludedProviders   []string
+	minSources          int
+	maxAge              time.Duration
+	requestTimeout      time.Duration
+	exactID             bool
+	coinsFile           string
+	legacyCoinsFile     string
+	outputFormat        string
+	vsCurrencies        []string
+)
+
+func ageSuffix(r pricefeed.Result) string {
+	if r.Timestamp.IsZero() {
+		return ""
+	}
+	return tr("AgeSuffix", ", Age: %s", r.Age())
 }
 
 var rootCmd = &cobra.Command{
-	Use:   "crypto-cli",
+	Use:   "crypto-cli [coin...] [-]",
 	Short: "A CLI tool to fetch cryptocurrency prices",
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
-			fmt.Println("Please specify a cryptocurrency (e.g., bitcoin, ethereum)")
-			return
-		}
-		crypto := args[0]
-		result := fetchCryptoPriceConcurrently(crypto)
-		if result.Price > 0 {
-			fmt.Printf("The current price of %s is $%.2f (Source: %s, Duration: %s)\n", crypto, result.Price, result.Source, result.Duration)
-		} else {
-			fmt.Println("Failed to fetch the price")
+	Long: `A CLI tool to fetch cryptocurrency prices.
+
+Exit codes:
+  0  success
+  1  unknown coin
+  2  all providers failed
+  3  rate limited
+  4  alert threshold triggered or assert bounds not met
+  5  only stale prices were available
+  6  any other error, such as an invalid flag or config file
+
+Environment:
+  Every flag can be set with a CRYPTO_CLI_* variable named after it, e.g.
+  CRYPTO_CLI_VS_CURRENCY=eur (or CRYPTO_CLI_CURRENCY=eur), CRYPTO_CLI_TIMEOUT=5s.
+  Provider API keys are read from CRYPTO_CLI_<PROVIDER>_KEY, e.g.
+  CRYPTO_CLI_COINGECKO_KEY. Command-line flags win over the environment,
+  and the environment over the config file.`,
+	Example: `  crypto-cli bitcoin ethereum -c eur
+  crypto-cli btc -q --decimals 0
+  crypto-cli bitcoin --at 2021-11-10
+  crypto-cli eth --at '2024-03-01 14:00 UTC' -c eur
+
+  # Feed a status bar from one long-running process, one line per refresh:
+  crypto-cli btc eth -o statusbar --every 1m                    # i3blocks, lemonbar
+  crypto-cli btc eth -o statusbar --markup pango --every 1m     # waybar custom module
+  crypto-cli btc eth -o statusbar --markup polybar --every 1m   # polybar, tail = true`,
+	Args:              cobra.ArbitraryArgs,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: configure,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coins, err := expandCoinArgs(args)
+		if err != nil {
+			return err
+		}
+		if len(coins) == 0 {
+			coins = defaultCoins()
+		}
+		if coins, err = expandWatchlists(coins); err != nil {
+			return err
+		}
+		if len(coins) == 0 && allowPrompt && isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+			if registry, err := loadRegistry(false); err == nil {
+				coin, err := pickCoinInteractively(registry.coins)
+				if err != nil {
+					return err
+				}
+				coins = []string{coin}
+			}
 		}
+		if len(coins) == 0 {
+			fmt.Println(tr("SpecifyCoin", "Please specify a cryptocurrency (e.g., bitcoin, ethereum)"))
+			return nil
+		}
+		if err := validatePriority(); err != nil {
+			return err
+		}
+		demoteUnreliable()
+		if err := validateAggregateMode(); err != nil {
+			return err
+		}
+		if err := validatePolicy(); err != nil {
+			return err
+		}
+		if outputFormat != "text" && outputFormat != "json" && outputFormat != "ndjson" && outputFormat != "csv" && outputFormat != "statusbar" {
+			return fmt.Errorf("unknown output format %q (expected text, json, ndjson, csv or statusbar)", outputFormat)
+		}
+		if err := checkStatusbarMarkup(); err != nil {
+			return err
+		}
+		if err := normalizeCurrencies(); err != nil {
+			return err
+		}
+		if err := parseFormat(); err != nil {
+			return err
+		}
+		if err := checkChangePeriods(); err != nil {
+			return err
+		}
+		if err := checkPriceAt(); err != nil {
+			return err
+		}
+		if err := checkCompare(); err != nil {
+			return err
+		}
+
+		if repeatEvery > 0 {
+			return runRepeat(cmd.Context(), coins)
+		}
+		if appendPath != "" {
+			return fmt.Errorf("--append requires --every")
+		}
+
+		quotes := quoteCoins(cmd.Context(), coins, vsCurrencies)
+		if copyToClipboard {
+			copyQuotes(quotes)
+		}
+		if quiet {
+			return printQuiet(quotes)
+		}
+		if quoteTemplate != nil {
+			return printFormatted(quotes)
+		}
+		if outputFormat == "statusbar" {
+			return printStatusbar(cmd.Context(), quotes)
+		}
+		if outputFormat == "ndjson" {
+			if err := printJSONLines(quotes); err != nil {
+				return err
+			}
+			return batchError(quotes)
+		}
+		if len(quotes) == 1 {
+			if quotes[0].err != nil {
+				return quotes[0].err
+			}
+			switch outputFormat {
+			case "json":
+				return printJSON(quotes[0])
+			case "csv":
+				return writeQuotesCSV(os.Stdout, quotes, true)
+			}
+			printQuote(quotes[0])
+			return nil
+		}
+
+		switch outputFormat {
+		case "json":
+			if err := printJSON(quotes); err != nil {
+				return err
+			}
+		case "csv":
+			if err := writeQuotesCSV(os.Stdout, quotes, true); err != nil {
+				return err
+			}
+		default:
+			if !priceAt.IsZero() {
+				fmt.Print(tr("HistoricalTableHeading", "Prices at %s\n", priceAt.Format(atTimeLayout)))
+			}
+			printQuoteTable(quotes)
+		}
+		return batchError(quotes)
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default is $HOME/.config/crypto-cli/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Named config profile to use")
+	rootCmd.PersistentFlags().StringVar(&secretsKeyFile, "secrets-key-file", "", "age identity file used to decrypt the config's secrets section")
+	rootCmd.Flags().StringVar(&aggregateMode, "aggregate", "priority", "How to combine provider prices: priority (first usable price in provider order), first (fastest), mean, median, vwap or all")
+	rootCmd.Flags().Float64Var(&maxDeviation, "max-deviation", 5, "Drop sources deviating more than this percentage from the median when aggregating (0 disables)")
+	rootCmd.Flags().Float64Var(&divergenceThreshold, "divergence-threshold", 2, "Warn when sources disagree by more than this percentage")
+	rootCmd.Flags().StringSliceVar(&priorityOrder, "priority", []string{"coingecko", "coinmarketcap", "cryptocompare", "binance", "kraken", "coinbase"}, "Provider preference order used by the priority strategy")
+	rootCmd.PersistentFlags().StringSliceVar(&selectedProviders, "providers", nil, "Only query these price providers, in order of preference (overrides --priority), e.g. coingecko,binance")
+	rootCmd.PersistentFlags().StringSliceVar(&excludedProviders, "exclude-providers", nil, "Never query these price providers")
+	rootCmd.Flags().IntVar(&minSources, "min-sources", 1, "Fail unless at least this many providers return a usable price")
+	rootCmd.Flags().Float64Var(&demoteBelow, "demote-below", 0, "Move providers whose recorded success rate over the past week is below this percentage to the end of the priority order (0 disables)")
+	rootCmd.Flags().DurationVar(&maxAge, "max-age", 0, "Reject quotes whose upstream timestamp is older than this (0 disables)")
+	rootCmd.Flags().StringVar(&coinsFile, "file", "", "Read coins from a file (- for stdin): one or more per line, or CSV rows of coin and amount to also show what each holding is worth")
+	rootCmd.Flags().StringVar(&legacyCoinsFile, "coins-file", "", "Read additional coins from a file, one or more per line (- for stdin)")
+	rootCmd.Flags().MarkDeprecated("coins-file", "use --file instead")
+	rootCmd.Flags().StringSliceVarP(&vsCurrencies, "vs-currency", "c", []string{"usd"}, "Currencies to quote in, e.g. eur,btc (repeatable); fiat a provider does not quote is converted from USD")
+	rootCmd.Flags().BoolVar(&exactID, "exact-id", false, "Treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "text", "Output format: text, json, ndjson (one object per line), csv or statusbar")
+	rootCmd.Flags().DurationVar(&repeatEvery, "every", 0, "Keep pricing the coins at this interval until interrupted")
+	rootCmd.Flags().StringVar(&appendPath, "append", "", "With --every, append each result to this CSV file (or JSON lines for .jsonl/.ndjson)")
+	rootCmd.Flags().BoolVar(&copyToClipboard, "copy", false, "Copy the fetched price to the system clipboard")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 8, "Price at most this many coins at once; CoinGecko is asked for all of them in one request")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 5*time.Second, "Give up on providers that have not answered within this time (0 disables)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 2, "Retry failed, rate-limited or 5xx provider requests this many times")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", 500*time.Millisecond, "Delay before the first retry, doubled with jitter for each further one")
+	rootCmd.PersistentFlags().BoolVar(&mockMode, "mock", false, "Return deterministic synthetic prices without any network calls")
+	rootCmd.PersistentFlags().Int64Var(&mockSeed, "mock-seed", 1, "Seed for --mock prices")
+	rootCmd.Flags().StringVar(&formatTemplate, "format", "", `Print each quote with a Go template, e.g. '{{.Coin}}: {{.Price | printf "%.0f"}} {{.Currency}}'; fields: Coin, Price, Currency, Source, Duration, Timestamp, Fetched, Changes (with --change), Amount and Value (with --file amounts); functions: upper, lower, symbol, price`)
+	rootCmd.Flags().StringVar(&priceAtFlag, "at", "", "Show the historical price nearest to this time instead of the current one, e.g. 2021-11-10 or '2024-03-01 14:00 UTC'")
+	rootCmd.Flags().BoolVar(&showTime, "show-time", false, "Show when each price was fetched")
+	rootCmd.Flags().Float64Var(&compareBaseline, "compare", 0, "Also show the difference from this baseline price, e.g. an entry point of 60000")
+	rootCmd.Flags().StringVar(&compareDateFlag, "compare-date", "", "Also show the difference from the price on this date, e.g. 2024-01-01")
+	rootCmd.Flags().StringSliceVar(&changePeriods, "change", nil, "Also show the percentage change over these periods, e.g. 24h,7d (1h, 24h, 7d, 14d, 30d, 200d, 1y)")
+	rootCmd.Flags().StringVar(&statusbarMarkup, "markup", "auto", "Colors for --output statusbar: auto (ANSI on a terminal), none, ansi, pango (waybar, i3blocks) or polybar")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print only the price, one per line, and nothing else; failures only set the exit code")
+	rootCmd.Flags().Var(autoInt{&quietDecimals, autoDecimals}, "decimals", "Decimal places printed by --quiet, as for --precision when auto (-1 for as many as needed)")
+	rootCmd.PersistentFlags().Var(autoInt{&pricePrecision, -1}, "precision", "Decimal places shown for prices; auto shows 2, or 4 significant digits for prices below 1")
+	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print per-source details and log every provider request (--log-level debug)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable ANSI colors (also when NO_COLOR is set or stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", false, "Screen-reader-friendly output: no colors, box drawing, spinners or in-place redraw")
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Language for messages, e.g. de (default from LC_ALL, LC_MESSAGES or LANG)")
+	rootCmd.PersistentFlags().StringVar(&localeFlag, "locale", "", "Format amounts the way this locale does, e.g. de-DE for 67.000,00 $; auto reads LC_ALL, LC_MONETARY or LANG")
+	rootCmd.PersistentFlags().StringVar(&timezoneFlag, "tz", "", "Show times in this zone, e.g. Europe/Kyiv or UTC (default the local zone)")
+	rootCmd.PersistentFlags().BoolVar(&humanizeNumbers, "humanize", false, "Abbreviate amounts of 1000 and more, e.g. $67.2k and $1.23T")
+}
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatalf("Error: %v", err)
+	err := rootCmd.Execute()
+	if statsErr := saveProviderStats(); statsErr != nil {
+		logger.Warn("could not save provider stats", "path", statsPath(), "err", statsErr)
+	}
+	if err != nil {
+		if silent(err) {
+			os.Exit(exitCode(err))
+		}
+		if outputFormat == "json" || outputFormat == "ndjson" {
+			printJSONError(err)
+		} else {
+			log.Print(tr("Error", "Error: %v", err))
+		}
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the provider latency
+// histogram.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricsEnabled makes priceClient meter provider requests. serve turns it
+// on; one-shot commands have nothing to export.
+var metricsEnabled bool
+
+type priceKey struct{ coin, currency, source string }
+
+type errorKey struct{ provider, kind string }
+
+type latencyHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type metricsRegistry struct {
+	mu      sync.Mutex
+	prices  map[priceKey]float64
+	latency map[string]*latencyHistogram
+	errors  map[errorKey]uint64
+}
+
+var metrics = &metricsRegistry{
+	prices:  make(map[priceKey]float64),
+	latency: make(map[string]*latencyHistogram),
+	errors:  make(map[errorKey]uint64),
+}
+
+func (m *metricsRegistry) observe(provider, coin, currency string, price float64, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.latency[provider]
+	if h == nil {
+		h = &latencyHistogram{counts: make([]uint64, len(latencyBuckets))}
+		m.latency[provider] = h
+	}
+	seconds := d.Seconds()
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+	if err != nil {
+		m.errors[errorKey{provider, string(pricefeed.Classify(err))}]++
+		return
+	}
+	m.prices[priceKey{coin, currency, provider}] = price
+}
+
+// metricName keeps the characters Prometheus allows in a metric name.
+func metricName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// write prints the metrics in the Prometheus text exposition format, sorted
+// so scrapes are stable.
+func (m *metricsRegistry) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	prices := make([]priceKey, 0, len(m.prices))
+	for k := range m.prices {
+		prices = append(prices, k)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		a, b := prices[i], prices[j]
+		if a.currency != b.currency {
+			return a.currency < b.currency
+		}
+		if a.coin != b.coin {
+			return a.coin < b.coin
+		}
+		return a.source < b.source
+	})
+	lastCurrency := ""
+	for _, k := range prices {
+		name := "crypto_price_" + metricName(k.currency)
+		if k.currency != lastCurrency {
+			fmt.Fprintf(w, "# HELP %s Last price fetched from each provider, in %s.\n", name, strings.ToUpper(k.currency))
+			fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+			lastCurrency = k.currency
+		}
+		fmt.Fprintf(w, "%s{coin=\"%s\",source=\"%s\"} %g\n", name, labelEscaper.Replace(k.coin), labelEscaper.Replace(k.source), m.prices[k])
+	}
+
+	providers := make([]string, 0, len(m.latency))
+	for p := range m.latency {
+		providers = append(providers, p)
+	}
+	sort.Strings(providers)
+	if len(providers) > 0 {
+		fmt.Fprintln(w, "# HELP crypto_provider_request_duration_seconds Time taken by provider price requests, including retries.")
+		fmt.Fprintln(w, "# TYPE crypto_provider_request_duration_seconds histogram")
+	}
+	for _, p := range providers {
+		h := m.latency[p]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "crypto_provider_request_duration_seconds_bucket{provider=\"%s\",le=\"%g\"} %d\n", p, bound, h.counts[i])
+		}
+		fmt.Fprintf(w, "crypto_provider_request_duration_seconds_bucket{provider=\"%s\",le=\"+Inf\"} %d\n", p, h.count)
+		fmt.Fprintf(w, "crypto_provider_request_duration_seconds_sum{provider=\"%s\"} %g\n", p, h.sum)
+		fmt.Fprintf(w, "crypto_provider_request_duration_seconds_count{provider=\"%s\"} %d\n", p, h.count)
+	}
+
+	errs := make([]errorKey, 0, len(m.errors))
+	for k := range m.errors {
+		errs = append(errs, k)
+	}
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].provider != errs[j].provider {
+			return errs[i].provider < errs[j].provider
+		}
+		return errs[i].kind < errs[j].kind
+	})
+	if len(errs) > 0 {
+		fmt.Fprintln(w, "# HELP crypto_provider_errors_total Failed provider price requests, by kind of failure.")
+		fmt.Fprintln(w, "# TYPE crypto_provider_errors_total counter")
+	}
+	for _, k := range errs {
+		fmt.Fprintf(w, "crypto_provider_errors_total{provider=\"%s\",kind=\"%s\"} %d\n", k.provider, k.kind, m.errors[k])
+	}
+}
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	metrics.write(w)
+}
+
+// meteredProvider records every request that reaches the provider. It sits
+// below the quote cache, so cache hits do not count as requests.
+type meteredProvider struct {
+	pricefeed.Provider
+	name string
+}
+
+func (p meteredProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	start := time.Now()
+	q, err := p.Provider.Fetch(ctx, coin, currency)
+	if ctx.Err() != context.Canceled {
+		metrics.observe(p.name, coin, currency, q.Price, time.Since(start), err)
+	}
+	return q, err
+}
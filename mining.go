@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	mempoolHashrateAPI = "/api/v1/mining/hashrate/3d"
+	mempoolTipAPI      = "/api/blocks/tip/height"
+
+	halvingInterval = 210000
+)
+
+var (
+	miningHashrate string
+	miningPower    string
+	miningKWh      float64
+)
+
+var hashrateUnits = map[string]float64{
+	"H":  1,
+	"KH": 1e3,
+	"MH": 1e6,
+	"GH": 1e9,
+	"TH": 1e12,
+	"PH": 1e15,
+	"EH": 1e18,
+}
+
+type MiningEstimate struct {
+	Coin        string  `json:"coin"`
+	Price       float64 `json:"price"`
+	Hashrate    float64 `json:"hashrate"`
+	PowerWatts  float64 `json:"power_watts"`
+	KWhPrice    float64 `json:"kwh_price"`
+	Difficulty  float64 `json:"difficulty"`
+	BlockReward float64 `json:"block_reward"`
+	CoinsPerDay float64 `json:"coins_per_day"`
+	Revenue     float64 `json:"revenue_per_day"`
+	PowerCost   float64 `json:"power_cost_per_day"`
+	Profit      float64 `json:"profit_per_day"`
+}
+
+// splitUnit separates a number from its unit suffix, e.g. "100TH" into
+// 100 and "TH".
+func splitUnit(s string) (float64, string, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid number %q", s)
+	}
+	return value, strings.ToUpper(strings.TrimSpace(s[i:])), nil
+}
+
+// parseHashrate parses values like "100TH", "1.5 PH/s" or "500GH" into
+// hashes per second.
+func parseHashrate(s string) (float64, error) {
+	value, unit, err := splitUnit(s)
+	if err != nil {
+		return 0, err
+	}
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "/S"), "S")
+	if unit == "" {
+		return value, nil
+	}
+	scale, ok := hashrateUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown hashrate unit in %q", s)
+	}
+	return value * scale, nil
+}
+
+// parsePower parses values like "3000W" or "3.2kW" into watts.
+func parsePower(s string) (float64, error) {
+	value, unit, err := splitUnit(s)
+	if err != nil {
+		return 0, err
+	}
+	switch unit {
+	case "", "W":
+		return value, nil
+	case "KW":
+		return value * 1e3, nil
+	}
+	return 0, fmt.Errorf("unknown power unit in %q", s)
+}
+
+func fetchBitcoinDifficulty(ctx context.Context) (float64, error) {
+	var resp struct {
+		CurrentDifficulty float64 `json:"currentDifficulty"`
+	}
+	if err := getJSON(ctx, providerURL("mempool")+mempoolHashrateAPI, "mempool", &resp); err != nil {
+		return 0, err
+	}
+	if resp.CurrentDifficulty == 0 {
+		return 0, fmt.Errorf("mempool: no difficulty reported")
+	}
+	return resp.CurrentDifficulty, nil
+}
+
+// fetchBlockReward returns the block subsidy at the current chain tip.
+func fetchBlockReward(ctx context.Context) (float64, error) {
+	text, err := getText(ctx, providerURL("mempool")+mempoolTipAPI, "mempool")
+	if err != nil {
+		return 0, err
+	}
+	height, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("mempool: invalid block height %q", text)
+	}
+	return 50 / math.Pow(2, float64(height/halvingInterval)), nil
+}
+
+func estimateMining(ctx context.Context, hashrate, watts, kwh float64) (MiningEstimate, error) {
+	est := MiningEstimate{Coin: "bitcoin", Hashrate: hashrate, PowerWatts: watts, KWhPrice: kwh}
+	var err error
+	if est.Difficulty, err = fetchBitcoinDifficulty(ctx); err != nil {
+		return est, err
+	}
+	if est.BlockReward, err = fetchBlockReward(ctx); err != nil {
+		return est, err
+	}
+	q := quoteCoin(ctx, "bitcoin", "usd")
+	if q.err != nil {
+		return est, q.err
+	}
+	est.Price = q.Price
+
+	// A share of the network hashrate finds difficulty*2^32 hashes per block.
+	est.CoinsPerDay = hashrate * 86400 * est.BlockReward / (est.Difficulty * math.Pow(2, 32))
+	est.Revenue = est.CoinsPerDay * est.Price
+	est.PowerCost = watts / 1000 * 24 * kwh
+	est.Profit = est.Revenue - est.PowerCost
+	return est, nil
+}
+
+var miningCmd = &cobra.Command{
+	Use:   "mining <coin>",
+	Short: "Estimate daily mining revenue and profit at the current price and difficulty",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		if coin != "bitcoin" {
+			return fmt.Errorf("mining estimates are only available for bitcoin")
+		}
+		hashrate, err := parseHashrate(miningHashrate)
+		if err != nil {
+			return err
+		}
+		watts, err := parsePower(miningPower)
+		if err != nil {
+			return err
+		}
+
+		est, err := estimateMining(cmd.Context(), hashrate, watts, miningKWh)
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			return printJSON(est)
+		}
+		fmt.Printf("Price:        $%.2f\n", est.Price)
+		fmt.Printf("Difficulty:   %.4g\n", est.Difficulty)
+		fmt.Printf("Block reward: %g BTC\n", est.BlockReward)
+		fmt.Printf("Mined:        %.8f BTC/day\n", est.CoinsPerDay)
+		fmt.Printf("Revenue:      $%.2f/day\n", est.Revenue)
+		fmt.Printf("Power cost:   $%.2f/day (%.0f W at $%.3f/kWh)\n", est.PowerCost, est.PowerWatts, est.KWhPrice)
+		fmt.Printf("Profit:       $%.2f/day, $%.2f/month\n", est.Profit, est.Profit*30)
+		return nil
+	},
+}
+
+func init() {
+	miningCmd.Flags().StringVar(&miningHashrate, "hashrate", "", "miner hashrate, e.g. 100TH or 1.5PH/s")
+	miningCmd.Flags().StringVar(&miningPower, "power", "0W", "power draw, e.g. 3000W or 3.2kW")
+	miningCmd.Flags().Float64Var(&miningKWh, "kwh", 0, "electricity price per kWh in USD")
+	miningCmd.MarkFlagRequired("hashrate")
+	rootCmd.AddCommand(miningCmd)
+}
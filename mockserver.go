@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cli-crypto-price/pricefeed/pricefeedtest"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mockServerListen   string
+	mockServerFailures []string
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Serve fake price provider APIs for trying configurations offline",
+	Long: `Serve fake versions of the CoinGecko, CoinMarketCap, CryptoCompare,
+Binance, Kraken and Coinbase price APIs, quoting fixed Bitcoin and Ethereum
+prices, and print the config that points crypto-cli at them. --fail makes
+a provider answer every request with an error instead, to see how retries,
+fallbacks and aggregation handle it: rate-limited (429), server-error
+(500), malformed or not-found.`,
+	Example: `  crypto-cli mock-server --listen 127.0.0.1:8765 > mock.yaml
+  crypto-cli mock-server --fail coingecko=rate-limited --fail kraken=malformed`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		handler := pricefeedtest.NewHandler()
+		for _, f := range mockServerFailures {
+			name, kind, ok := strings.Cut(f, "=")
+			if !ok || !containsFold(pricefeedtest.Providers, name) {
+				return fmt.Errorf("invalid --fail %q: use provider=failure, with a provider of %s", f, strings.Join(pricefeedtest.Providers, ", "))
+			}
+			failure, err := pricefeedtest.ParseFailure(kind)
+			if err != nil {
+				return fmt.Errorf("invalid --fail %q: %w", f, err)
+			}
+			handler.Fail(strings.ToLower(name), failure)
+		}
+
+		ln, err := net.Listen("tcp", mockServerListen)
+		if err != nil {
+			return err
+		}
+		fmt.Println("providers:")
+		for _, name := range pricefeedtest.Providers {
+			fmt.Printf("  %s:\n    base_url: http://%s/%s\n", name, ln.Addr(), name)
+			if name == "coinmarketcap" {
+				fmt.Println("    key: test")
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Serving mock providers on %s (Ctrl-C to stop)\n", ln.Addr())
+
+		srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+		}()
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	mockServerCmd.Flags().StringVar(&mockServerListen, "listen", "127.0.0.1:8765", "address to listen on")
+	mockServerCmd.Flags().StringArrayVar(&mockServerFailures, "fail", nil, "make a provider fail, e.g. coingecko=rate-limited (repeatable)")
+	rootCmd.AddCommand(mockServerCmd)
+}
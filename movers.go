@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	moversWindow   string
+	moversTop      int
+	moversUniverse int
+	moversCurrency string
+)
+
+// Mover is a coin's change over the window.
+type Mover struct {
+	ID     string  `json:"id"`
+	Symbol string  `json:"symbol"`
+	Name   string  `json:"name"`
+	Rank   int     `json:"market_cap_rank"`
+	Price  float64 `json:"price"`
+	Change float64 `json:"change_pct"`
+}
+
+type MoversReport struct {
+	Window   string  `json:"window"`
+	Currency string  `json:"currency"`
+	Universe int     `json:"universe"`
+	Gainers  []Mover `json:"gainers"`
+	Losers   []Mover `json:"losers"`
+}
+
+// findMovers picks the n biggest rises and the n biggest falls. Coins
+// without a change for the window are left out.
+func findMovers(markets []coinMarket, window string, n int) (gainers, losers []Mover) {
+	var movers []Mover
+	for _, m := range markets {
+		change, ok := m.Changes[window]
+		if !ok {
+			continue
+		}
+		movers = append(movers, Mover{m.ID, strings.ToUpper(m.Symbol), m.Name, m.Rank, m.Price, change})
+	}
+	sort.SliceStable(movers, func(i, j int) bool { return movers[i].Change > movers[j].Change })
+	gainers, losers = []Mover{}, []Mover{}
+	for _, m := range movers {
+		if m.Change > 0 && len(gainers) < n {
+			gainers = append(gainers, m)
+		}
+	}
+	for i := len(movers) - 1; i >= 0; i-- {
+		if movers[i].Change < 0 && len(losers) < n {
+			losers = append(losers, movers[i])
+		}
+	}
+	return gainers, losers
+}
+
+// printMoversTable puts gainers and losers side by side. Empty change cells
+// are still painted so every row has the same escape codes and tabwriter
+// keeps the columns aligned.
+func printMoversTable(report MoversReport) error {
+	color := useColor(os.Stdout)
+	fmt.Print(tr("MoversHeading", "Top movers over %s among the top %d coins by market cap\n", report.Window, report.Universe))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	window := strings.ToUpper(report.Window)
+	fmt.Fprintln(w, paintHeader(tr("MoversTableHeader", "GAINERS\tPRICE\t%s\t\tLOSERS\tPRICE\t%s", window, window), color, 2, 6))
+	cells := func(movers []Mover, i int) string {
+		if i >= len(movers) {
+			return "\t\t" + paint("", "39", color)
+		}
+		m := movers[i]
+		change := colored(fmt.Sprintf("%+.2f%%", m.Change), m.Change >= 0, color)
+		return m.Symbol + "\t" + formatPrice(m.Price, report.Currency) + "\t" + change
+	}
+	for i := 0; i < max(len(report.Gainers), len(report.Losers)); i++ {
+		fmt.Fprintf(w, "%s\t\t%s\n", cells(report.Gainers, i), cells(report.Losers, i))
+	}
+	if len(report.Gainers) == 0 && len(report.Losers) == 0 {
+		fmt.Fprintln(w, "-\t\t\t\t-\t\t")
+	}
+	return w.Flush()
+}
+
+func printMoversCSV(report MoversReport) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"side", "rank", "id", "symbol", "name", "price", "currency", "change_" + report.Window + "_pct"})
+	for _, side := range []struct {
+		name   string
+		movers []Mover
+	}{{"gainer", report.Gainers}, {"loser", report.Losers}} {
+		for _, m := range side.movers {
+			w.Write([]string{
+				side.name,
+				strconv.Itoa(m.Rank),
+				m.ID,
+				m.Symbol,
+				m.Name,
+				strconv.FormatFloat(m.Price, 'f', -1, 64),
+				report.Currency,
+				strconv.FormatFloat(m.Change, 'f', 2, 64),
+			})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var moversCmd = &cobra.Command{
+	Use:   "movers",
+	Short: "Show the biggest gainers and losers among the top coins",
+	Long: `Show the coins with the biggest percentage rise and fall over --window
+among the top --universe coins by market cap, --top of each side by side.
+Windows are the periods CoinGecko reports changes for: 1h, 24h, 7d, 14d,
+30d, 200d and 1y.`,
+	Example: `  crypto-cli movers
+  crypto-cli movers --window 7d --top 5
+  crypto-cli movers --window 1h --universe 250 -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		window := strings.ToLower(moversWindow)
+		if !containsFold(changePeriodNames, window) {
+			return errors.New(tr("MoversUnknownWindow", "unknown --window %q (expected %s)", moversWindow, strings.Join(changePeriodNames, ", ")))
+		}
+		if moversTop < 1 || moversUniverse < 1 {
+			return errors.New(tr("MoversTooFew", "--top and --universe must be at least 1"))
+		}
+		currency := strings.ToLower(moversCurrency)
+		markets, err := fetchMarketPage(cmd.Context(), currency, "market_cap_desc", 0, moversUniverse, window)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		report := MoversReport{Window: window, Currency: currency, Universe: len(markets)}
+		report.Gainers, report.Losers = findMovers(markets, window, moversTop)
+
+		switch outputFormat {
+		case "json":
+			return printJSON(report)
+		case "csv":
+			return printMoversCSV(report)
+		}
+		return printMoversTable(report)
+	},
+}
+
+func init() {
+	moversCmd.Flags().StringVar(&moversWindow, "window", "24h", "period to measure the change over: "+strings.Join(changePeriodNames, ", "))
+	moversCmd.Flags().IntVar(&moversTop, "top", 10, "number of gainers and of losers to show")
+	moversCmd.Flags().IntVar(&moversUniverse, "universe", 100, "how many of the top coins by market cap to consider")
+	moversCmd.Flags().StringVarP(&moversCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	rootCmd.AddCommand(moversCmd)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	cryptopanicBaseURL = "https://cryptopanic.com/api/v1"
+
+	cryptocompareNewsAPI = "/data/v2/news/?lang=EN&categories=%s"
+	cryptopanicPostsAPI  = "/posts/?public=true&currencies=%s"
+)
+
+var (
+	newsLimit     int
+	newsSentiment bool
+)
+
+type Headline struct {
+	Title     string    `json:"title"`
+	URL       string    `json:"url"`
+	Source    string    `json:"source"`
+	Published time.Time `json:"published"`
+	Sentiment string    `json:"sentiment,omitempty"`
+}
+
+func fetchCryptoCompareNews(ctx context.Context, symbol string) ([]Headline, error) {
+	var resp struct {
+		Message string `json:"Message"`
+		Data    []struct {
+			Title       string `json:"title"`
+			URL         string `json:"url"`
+			Source      string `json:"source"`
+			PublishedOn int64  `json:"published_on"`
+		} `json:"Data"`
+	}
+	if err := getJSON(ctx, providerURL("cryptocompare")+fmt.Sprintf(cryptocompareNewsAPI, url.QueryEscape(symbol)), "cryptocompare", &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 && resp.Message != "" {
+		return nil, fmt.Errorf("cryptocompare: %s", resp.Message)
+	}
+	headlines := make([]Headline, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		headlines = append(headlines, Headline{Title: d.Title, URL: d.URL, Source: d.Source, Published: time.Unix(d.PublishedOn, 0)})
+	}
+	return headlines, nil
+}
+
+// fetchCryptoPanicNews reads posts from CryptoPanic, which takes its key as
+// the auth_token query parameter and carries community votes used for the
+// sentiment flag.
+func fetchCryptoPanicNews(ctx context.Context, symbol string) ([]Headline, error) {
+	key := providerKey("cryptopanic")
+	if key == "" {
+		return nil, nil
+	}
+	var resp struct {
+		Results []struct {
+			Title       string    `json:"title"`
+			URL         string    `json:"url"`
+			PublishedAt time.Time `json:"published_at"`
+			Source      struct {
+				Title string `json:"title"`
+			} `json:"source"`
+			Votes struct {
+				Positive int `json:"positive"`
+				Negative int `json:"negative"`
+			} `json:"votes"`
+		} `json:"results"`
+	}
+	u := providerURL("cryptopanic") + fmt.Sprintf(cryptopanicPostsAPI, url.QueryEscape(symbol)) + "&auth_token=" + url.QueryEscape(key)
+	if err := getJSON(ctx, u, "cryptopanic", &resp); err != nil {
+		return nil, err
+	}
+	headlines := make([]Headline, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		h := Headline{Title: r.Title, URL: r.URL, Source: r.Source.Title, Published: r.PublishedAt, Sentiment: "neutral"}
+		switch {
+		case r.Votes.Positive > r.Votes.Negative:
+			h.Sentiment = "bullish"
+		case r.Votes.Negative > r.Votes.Positive:
+			h.Sentiment = "bearish"
+		}
+		headlines = append(headlines, h)
+	}
+	return headlines, nil
+}
+
+var newsSources = []func(context.Context, string) ([]Headline, error){fetchCryptoCompareNews, fetchCryptoPanicNews}
+
+// fetchNews merges headlines from all sources, newest first, dropping
+// duplicates reported by more than one source.
+func fetchNews(ctx context.Context, symbol string, limit int) ([]Headline, []error) {
+	results := make([][]Headline, len(newsSources))
+	errs := make([]error, len(newsSources))
+	var wg sync.WaitGroup
+	wg.Add(len(newsSources))
+	for i, fetch := range newsSources {
+		go func(i int, fetch func(context.Context, string) ([]Headline, error)) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(ctx, symbol)
+		}(i, fetch)
+	}
+	wg.Wait()
+
+	var headlines []Headline
+	var failed []error
+	seen := make(map[string]bool)
+	for i := range newsSources {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+		}
+		for _, h := range results[i] {
+			key := strings.ToLower(h.Title)
+			if !seen[key] {
+				seen[key] = true
+				headlines = append(headlines, h)
+			}
+		}
+	}
+	sort.Slice(headlines, func(i, j int) bool { return headlines[i].Published.After(headlines[j].Published) })
+	if limit > 0 && len(headlines) > limit {
+		headlines = headlines[:limit]
+	}
+	return headlines, failed
+}
+
+var newsCmd = &cobra.Command{
+	Use:   "news <coin>",
+	Short: "Show recent news headlines for a coin",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		if newsSentiment && providerKey("cryptopanic") == "" {
+			fmt.Fprintln(os.Stderr, tr("NewsSentimentKey", "Warning: --sentiment needs a CryptoPanic API key"))
+		}
+		headlines, errs := fetchNews(cmd.Context(), coinSymbol(coin), newsLimit)
+		for _, err := range errs {
+			fmt.Fprint(os.Stderr, tr("Warning", "Warning: %v\n", err))
+		}
+		if len(headlines) == 0 && len(errs) > 0 {
+			return withExitCode(exitAllProvidersFailed, errors.New(tr("NewsNone", "no news available for %s", coin)))
+		}
+		if !newsSentiment {
+			for i := range headlines {
+				headlines[i].Sentiment = ""
+			}
+		}
+		if outputFormat == "json" {
+			return printJSON(headlines)
+		}
+		if len(headlines) == 0 {
+			fmt.Print(tr("NewsNoRecent", "No recent news for %s\n", coin))
+			return nil
+		}
+
+		for _, h := range headlines {
+			flag := ""
+			if h.Sentiment != "" {
+				flag = "[" + h.Sentiment + "] "
+			}
+			fmt.Printf("%s  %s%s (%s)\n  %s\n", h.Published.Format("2006-01-02 15:04"), flag, h.Title, h.Source, h.URL)
+		}
+		return nil
+	},
+}
+
+func init() {
+	newsCmd.Flags().IntVar(&newsLimit, "limit", 10, "maximum number of headlines")
+	newsCmd.Flags().BoolVar(&newsSentiment, "sentiment", false, "flag each headline as bullish, bearish or neutral (needs a CryptoPanic key)")
+	rootCmd.AddCommand(newsCmd)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	reservoirBaseURL = "https://api.reservoir.tools"
+	openseaBaseURL   = "https://api.opensea.io"
+
+	reservoirCollectionAPI = "/collections/v7?slug=%s"
+	openseaStatsAPI        = "/api/v2/collections/%s/stats"
+)
+
+type NFTFloor struct {
+	Collection string  `json:"collection"`
+	Name       string  `json:"name,omitempty"`
+	FloorETH   float64 `json:"floor_eth"`
+	Volume24h  float64 `json:"volume_24h_eth"`
+	FloorUSD   float64 `json:"floor_usd,omitempty"`
+	VolumeUSD  float64 `json:"volume_24h_usd,omitempty"`
+	Source     string  `json:"source"`
+}
+
+type reservoirCollectionsResponse struct {
+	Collections []struct {
+		Name     string `json:"name"`
+		FloorAsk struct {
+			Price struct {
+				Amount struct {
+					Decimal float64 `json:"decimal"`
+				} `json:"amount"`
+			} `json:"price"`
+		} `json:"floorAsk"`
+		Volume struct {
+			OneDay float64 `json:"1day"`
+		} `json:"volume"`
+	} `json:"collections"`
+}
+
+type openseaStatsResponse struct {
+	Total struct {
+		FloorPrice float64 `json:"floor_price"`
+	} `json:"total"`
+	Intervals []struct {
+		Interval string  `json:"interval"`
+		Volume   float64 `json:"volume"`
+	} `json:"intervals"`
+}
+
+func fetchReservoirFloor(ctx context.Context, slug string) (NFTFloor, error) {
+	floor := NFTFloor{Collection: slug, Source: "Reservoir"}
+	resp, err := httpGet(ctx, providerURL("reservoir")+fmt.Sprintf(reservoirCollectionAPI, url.QueryEscape(slug)), "reservoir")
+	if err != nil {
+		return floor, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return floor, statusError("reservoir", resp)
+	}
+
+	var result reservoirCollectionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return floor, fmt.Errorf("reservoir: invalid response: %w", err)
+	}
+	if len(result.Collections) == 0 {
+		return floor, fmt.Errorf("reservoir: collection %q not found", slug)
+	}
+	c := result.Collections[0]
+	floor.Name = c.Name
+	floor.FloorETH = c.FloorAsk.Price.Amount.Decimal
+	floor.Volume24h = c.Volume.OneDay
+	return floor, nil
+}
+
+func fetchOpenSeaFloor(ctx context.Context, slug string) (NFTFloor, error) {
+	floor := NFTFloor{Collection: slug, Source: "OpenSea"}
+	if providerKey("opensea") == "" {
+		return floor, errors.New("opensea: no API key configured")
+	}
+	resp, err := httpGet(ctx, providerURL("opensea")+fmt.Sprintf(openseaStatsAPI, url.PathEscape(slug)), "opensea")
+	if err != nil {
+		return floor, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return floor, statusError("opensea", resp)
+	}
+
+	var result openseaStatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return floor, fmt.Errorf("opensea: invalid response: %w", err)
+	}
+	floor.FloorETH = result.Total.FloorPrice
+	for _, i := range result.Intervals {
+		if i.Interval == "one_day" {
+			floor.Volume24h = i.Volume
+		}
+	}
+	return floor, nil
+}
+
+var nftCmd = &cobra.Command{
+	Use:   "nft <collection>",
+	Short: "Show the floor price and 24h volume of an NFT collection",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		slug := args[0]
+		floor, err := fetchReservoirFloor(cmd.Context(), slug)
+		if err != nil {
+			var openseaErr error
+			if floor, openseaErr = fetchOpenSeaFloor(cmd.Context(), slug); openseaErr != nil {
+				return fmt.Errorf("%v; %v", err, openseaErr)
+			}
+		}
+
+		if eth := quoteCoin(cmd.Context(), "ethereum", "usd"); eth.err == nil {
+			floor.FloorUSD = floor.FloorETH * eth.Price
+			floor.VolumeUSD = floor.Volume24h * eth.Price
+		}
+
+		if outputFormat == "json" {
+			return printJSON(floor)
+		}
+		name := floor.Name
+		if name == "" {
+			name = floor.Collection
+		}
+		fmt.Printf("%s floor: %.4f ETH", name, floor.FloorETH)
+		if floor.FloorUSD > 0 {
+			fmt.Printf(" ($%.2f)", floor.FloorUSD)
+		}
+		fmt.Printf(", 24h volume: %.2f ETH", floor.Volume24h)
+		if floor.VolumeUSD > 0 {
+			fmt.Printf(" ($%.2f)", floor.VolumeUSD)
+		}
+		fmt.Printf(" (Source: %s)\n", floor.Source)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(nftCmd)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/smtp"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const telegramBaseURL = "https://api.telegram.org"
+
+// message is what notifiers deliver. Event, if set, is the structured form
+// of the message: webhooks post it as JSON and chat notifiers append it.
+type message struct {
+	Subject string
+	Body    string
+	Event   interface{}
+}
+
+// text renders the body followed by the event as indented JSON.
+func (m message) text() string {
+	if m.Event == nil {
+		return m.Body
+	}
+	event, _ := json.MarshalIndent(m.Event, "", "  ")
+	return strings.TrimRight(m.Body, "\n") + "\n\n" + string(event) + "\n"
+}
+
+// notifier delivers a message through one of the channels configured in
+// the config's "notifiers" section.
+type notifier struct {
+	name string
+	send func(m message) error
+}
+
+const notifyTimeout = 15 * time.Second
+
+func postNotification(target string, contentType string, payload []byte) error {
+	resp, err := newHTTPClient(notifyTimeout).Post(target, contentType, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
+
+func slackNotifier(webhook string) notifier {
+	return notifier{"slack", func(m message) error {
+		payload, _ := json.Marshal(map[string]string{"text": "*" + m.Subject + "*\n```\n" + m.text() + "```"})
+		return postNotification(webhook, "application/json", payload)
+	}}
+}
+
+func telegramNotifier(token, chatID string) notifier {
+	return notifier{"telegram", func(m message) error {
+		form := url.Values{"chat_id": {chatID}, "text": {m.Subject + "\n\n" + m.text()}}
+		target := providerURL("telegram") + "/bot" + token + "/sendMessage"
+		return postNotification(target, "application/x-www-form-urlencoded", []byte(form.Encode()))
+	}}
+}
+
+func emailNotifier(host string, port int, username, password, from string, to []string) notifier {
+	return notifier{"email", func(m message) error {
+		var auth smtp.Auth
+		if username != "" {
+			auth = smtp.PlainAuth("", username, password, host)
+		}
+		msg := "From: " + from + "\r\nTo: " + strings.Join(to, ", ") + "\r\nSubject: " + m.Subject +
+			"\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n" + strings.ReplaceAll(m.text(), "\n", "\r\n")
+		return smtp.SendMail(fmt.Sprintf("%s:%d", host, port), auth, from, to, []byte(msg))
+	}}
+}
+
+// webhookNotifier posts the message's event as JSON, or the subject and
+// body when it has none.
+func webhookNotifier(target string) notifier {
+	return notifier{"webhook", func(m message) error {
+		payload := m.Event
+		if payload == nil {
+			payload = map[string]string{"subject": m.Subject, "text": m.Body}
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		return postNotification(target, "application/json", body)
+	}}
+}
+
+// configuredNotifiers returns every notifier with complete settings in the
+// config, sorted by name.
+func configuredNotifiers() []notifier {
+	var ns []notifier
+	if webhook := config.GetString("notifiers.slack.webhook_url"); webhook != "" {
+		ns = append(ns, slackNotifier(webhook))
+	}
+	token := config.GetString("notifiers.telegram.bot_token")
+	if token == "" {
+		token = providerKey("telegram")
+	}
+	if chat := config.GetString("notifiers.telegram.chat_id"); token != "" && chat != "" {
+		ns = append(ns, telegramNotifier(token, chat))
+	}
+	if target := config.GetString("notifiers.webhook.url"); target != "" {
+		ns = append(ns, webhookNotifier(target))
+	}
+	if host := config.GetString("notifiers.email.smtp_host"); host != "" {
+		port := config.GetInt("notifiers.email.smtp_port")
+		if port == 0 {
+			port = 587
+		}
+		ns = append(ns, emailNotifier(host, port,
+			config.GetString("notifiers.email.username"),
+			config.GetString("notifiers.email.password"),
+			config.GetString("notifiers.email.from"),
+			config.GetStringSlice("notifiers.email.to")))
+	}
+	sort.Slice(ns, func(i, j int) bool { return ns[i].name < ns[j].name })
+	return ns
+}
+
+// selectNotifiers picks the named notifiers, or all configured ones when
+// names is empty.
+func selectNotifiers(names []string) ([]notifier, error) {
+	all := configuredNotifiers()
+	if len(names) == 0 {
+		return all, nil
+	}
+	var selected []notifier
+	for _, name := range names {
+		found := false
+		for _, n := range all {
+			if strings.EqualFold(n.name, name) {
+				selected = append(selected, n)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("notifier %q is not configured (see the notifiers section of the config)", name)
+		}
+	}
+	return selected, nil
+}
+
+func notifyAll(ns []notifier, m message) error {
+	var errs []error
+	for _, n := range ns {
+		if err := n.send(m); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
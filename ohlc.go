@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	binanceKlinesAPI = "/api/v3/klines?symbol=%s&interval=%s&limit=%d"
+	coingeckoOHLCAPI = "/coins/%s/ohlc?vs_currency=%s&days=%d"
+
+	// binanceMaxKlines is the most candles Binance returns per request.
+	binanceMaxKlines = 1000
+)
+
+var (
+	ohlcInterval string
+	ohlcLimit    int
+	ohlcSource   string
+	ohlcCurrency string
+)
+
+var binanceIntervals = []string{"1m", "3m", "5m", "15m", "30m", "1h", "2h", "4h", "6h", "8h", "12h", "1d", "3d", "1w", "1M"}
+
+// coingeckoOHLCDays maps the candle sizes CoinGecko serves to the largest
+// days value that still returns them: 30-minute candles for 1-2 days,
+// 4-hour candles up to 30 days and 4-day candles beyond.
+var coingeckoOHLCDays = map[string]struct {
+	size    time.Duration
+	minDays int
+	maxDays int
+}{
+	"30m": {30 * time.Minute, 1, 2},
+	"4h":  {4 * time.Hour, 3, 30},
+	"4d":  {96 * time.Hour, 31, 365},
+}
+
+// Candle is one OHLC bar starting at Time. Volume is in the coin and only
+// Binance reports it.
+type Candle struct {
+	Time   time.Time `json:"time"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume,omitempty"`
+}
+
+// fetchBinanceCandles reads klines, which are arrays of open time, the
+// four prices and volume as strings, then fields this command ignores.
+func fetchBinanceCandles(ctx context.Context, coin, currency, interval string, limit int) ([]Candle, error) {
+	pair := coinSymbol(coin) + binanceQuoteAsset(currency)
+	var klines [][]json.RawMessage
+	if err := getJSON(ctx, providerURL("binance")+fmt.Sprintf(binanceKlinesAPI, pair, interval, limit), "binance", &klines); err != nil {
+		return nil, fmt.Errorf("binance: %s: %w", pair, err)
+	}
+	candles := make([]Candle, 0, len(klines))
+	for _, k := range klines {
+		if len(k) < 6 {
+			continue
+		}
+		var openTime int64
+		var fields [5]string
+		json.Unmarshal(k[0], &openTime)
+		for i := range fields {
+			json.Unmarshal(k[i+1], &fields[i])
+		}
+		candles = append(candles, Candle{
+			Time:   time.UnixMilli(openTime).UTC(),
+			Open:   parseFloat(fields[0]),
+			High:   parseFloat(fields[1]),
+			Low:    parseFloat(fields[2]),
+			Close:  parseFloat(fields[3]),
+			Volume: parseFloat(fields[4]),
+		})
+	}
+	return candles, nil
+}
+
+func binanceQuoteAsset(currency string) string {
+	if currency == "usd" {
+		return "USDT"
+	}
+	return strings.ToUpper(currency)
+}
+
+// fetchCoinGeckoCandles asks for enough days to cover limit candles of the
+// interval and keeps the last limit of them.
+func fetchCoinGeckoCandles(ctx context.Context, coin, currency, interval string, limit int) ([]Candle, error) {
+	granularity, ok := coingeckoOHLCDays[interval]
+	if !ok {
+		return nil, fmt.Errorf("coingecko: no %s candles (supported: 30m, 4h, 4d)", interval)
+	}
+	days := int((granularity.size*time.Duration(limit) + 24*time.Hour - 1) / (24 * time.Hour))
+	days = min(max(days, granularity.minDays), granularity.maxDays)
+
+	var rows [][5]float64
+	if err := getJSON(ctx, providerURL("coingecko")+fmt.Sprintf(coingeckoOHLCAPI, coin, currency, days), "coingecko", &rows); err != nil {
+		return nil, err
+	}
+	candles := make([]Candle, len(rows))
+	for i, r := range rows {
+		candles[i] = Candle{Time: time.UnixMilli(int64(r[0])).UTC(), Open: r[1], High: r[2], Low: r[3], Close: r[4]}
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// fetchCandles uses --source, or under auto tries Binance and falls back
+// to CoinGecko for coins or currencies Binance has no market for.
+func fetchCandles(ctx context.Context, coin, currency string) ([]Candle, string, error) {
+	switch ohlcSource {
+	case "binance":
+		candles, err := fetchBinanceCandles(ctx, coin, currency, ohlcInterval, ohlcLimit)
+		return candles, "Binance", err
+	case "coingecko":
+		candles, err := fetchCoinGeckoCandles(ctx, coin, currency, ohlcInterval, ohlcLimit)
+		return candles, "CoinGecko", err
+	}
+	candles, err := fetchBinanceCandles(ctx, coin, currency, ohlcInterval, ohlcLimit)
+	if err == nil && len(candles) > 0 {
+		return candles, "Binance", nil
+	}
+	fallback, cgErr := fetchCoinGeckoCandles(ctx, coin, currency, ohlcInterval, ohlcLimit)
+	if cgErr != nil {
+		return nil, "", fmt.Errorf("%v; %v", err, cgErr)
+	}
+	return fallback, "CoinGecko", nil
+}
+
+func printCandlesCSV(candles []Candle, currency string) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"time", "open", "high", "low", "close", "volume", "currency"})
+	for _, c := range candles {
+		w.Write([]string{
+			c.Time.Format(time.RFC3339),
+			strconv.FormatFloat(c.Open, 'f', -1, 64),
+			strconv.FormatFloat(c.High, 'f', -1, 64),
+			strconv.FormatFloat(c.Low, 'f', -1, 64),
+			strconv.FormatFloat(c.Close, 'f', -1, 64),
+			strconv.FormatFloat(c.Volume, 'f', -1, 64),
+			currency,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func printCandlesTable(candles []Candle, currency string) error {
+	color := useColor(os.Stdout)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, paintHeader("TIME\tOPEN\tHIGH\tLOW\tCLOSE\tCHANGE\tVOLUME", color, 5))
+	for _, c := range candles {
+		change := percentChange(c.Open, c.Close)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Time.Format("2006-01-02 15:04"),
+			formatPrice(c.Open, currency), formatPrice(c.High, currency), formatPrice(c.Low, currency), formatPrice(c.Close, currency),
+			colored(fmt.Sprintf("%+.2f%%", change), change >= 0, color), formatVolume(c.Volume))
+	}
+	return w.Flush()
+}
+
+var ohlcCmd = &cobra.Command{
+	Use:   "ohlc <coin>",
+	Short: "Show a coin's OHLC candles",
+	Long: `Show open, high, low and close prices for the last --limit candles of
+--interval, oldest first, as a table, CSV or JSON for backtesting and
+charting scripts. The last candle is still open.
+
+Binance serves every interval from 1m to 1M for coins it lists, with volume.
+CoinGecko covers every coin but only 30m, 4h and 4d candles, without volume.
+--source auto tries Binance first and falls back to CoinGecko.`,
+	Example: `  crypto-cli ohlc bitcoin --interval 1h --limit 48
+  crypto-cli ohlc ethereum --interval 1d --limit 365 -o csv > eth.csv
+  crypto-cli ohlc pepe --source coingecko --interval 4h -o json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstCoin,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch ohlcSource {
+		case "auto", "binance", "coingecko":
+		default:
+			return fmt.Errorf("unknown --source %q (expected auto, binance or coingecko)", ohlcSource)
+		}
+		if !containsFold(binanceIntervals, ohlcInterval) && coingeckoOHLCDays[ohlcInterval].size == 0 {
+			return fmt.Errorf("unknown --interval %q (expected %s or 4d)", ohlcInterval, strings.Join(binanceIntervals, ", "))
+		}
+		if ohlcLimit < 1 || ohlcLimit > binanceMaxKlines {
+			return fmt.Errorf("--limit must be between 1 and %d", binanceMaxKlines)
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		currency := strings.ToLower(ohlcCurrency)
+
+		candles, source, err := fetchCandles(cmd.Context(), coin, currency)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if len(candles) == 0 {
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no %s candles for %s", ohlcInterval, coin))
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(candles)
+		case "csv":
+			return printCandlesCSV(candles, currency)
+		}
+		fmt.Printf("%s (%s), %d %s candles from %s\n", coin, strings.ToUpper(currency), len(candles), ohlcInterval, source)
+		return printCandlesTable(candles, currency)
+	},
+}
+
+func init() {
+	ohlcCmd.Flags().StringVar(&ohlcInterval, "interval", "1h", "candle size: "+strings.Join(binanceIntervals, ", ")+" on Binance; 30m, 4h or 4d on CoinGecko")
+	ohlcCmd.Flags().IntVar(&ohlcLimit, "limit", 48, "number of candles, ending now")
+	ohlcCmd.Flags().StringVar(&ohlcSource, "source", "auto", "where to get candles: auto, binance or coingecko")
+	ohlcCmd.Flags().StringVarP(&ohlcCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	ohlcCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.AddCommand(ohlcCmd)
+}
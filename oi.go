@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	binanceFuturesBaseURL = "https://fapi.binance.com"
+	bybitBaseURL          = "https://api.bybit.com"
+	okxBaseURL            = "https://www.okx.com"
+
+	binanceOpenInterestHistAPI = "/futures/data/openInterestHist?symbol=%sUSDT&period=1h&limit=25"
+	bybitOpenInterestAPI       = "/v5/market/open-interest?category=linear&symbol=%sUSDT&intervalTime=1h&limit=25"
+	okxOpenInterestAPI         = "/api/v5/public/open-interest?instType=SWAP&instId=%s-USDT-SWAP"
+	okxOpenInterestHistAPI     = "/api/v5/rubik/stats/contracts/open-interest-volume?ccy=%s&period=1H"
+)
+
+type VenueOpenInterest struct {
+	Venue     string  `json:"venue"`
+	Amount    float64 `json:"open_interest"`
+	ValueUSD  float64 `json:"value_usd,omitempty"`
+	Change24h float64 `json:"change_24h_pct"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type OpenInterest struct {
+	Coin      string              `json:"coin"`
+	Symbol    string              `json:"symbol"`
+	Total     float64             `json:"total_open_interest"`
+	TotalUSD  float64             `json:"total_value_usd,omitempty"`
+	Change24h float64             `json:"change_24h_pct"`
+	Venues    []VenueOpenInterest `json:"venues"`
+}
+
+func percentChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func fetchBinanceOpenInterest(ctx context.Context, symbol string) (VenueOpenInterest, error) {
+	oi := VenueOpenInterest{Venue: "Binance"}
+	var hist []struct {
+		SumOpenInterest string `json:"sumOpenInterest"`
+	}
+	if err := getJSON(ctx, providerURL("binance-futures")+fmt.Sprintf(binanceOpenInterestHistAPI, symbol), "binance-futures", &hist); err != nil {
+		return oi, err
+	}
+	if len(hist) == 0 {
+		return oi, fmt.Errorf("binance: no %sUSDT perpetual", symbol)
+	}
+	oi.Amount = parseFloat(hist[len(hist)-1].SumOpenInterest)
+	oi.Change24h = percentChange(parseFloat(hist[0].SumOpenInterest), oi.Amount)
+	return oi, nil
+}
+
+func fetchBybitOpenInterest(ctx context.Context, symbol string) (VenueOpenInterest, error) {
+	oi := VenueOpenInterest{Venue: "Bybit"}
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				OpenInterest string `json:"openInterest"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, providerURL("bybit")+fmt.Sprintf(bybitOpenInterestAPI, symbol), "bybit", &resp); err != nil {
+		return oi, err
+	}
+	list := resp.Result.List
+	if resp.RetCode != 0 || len(list) == 0 {
+		return oi, fmt.Errorf("bybit: %s", resp.RetMsg)
+	}
+	oi.Amount = parseFloat(list[0].OpenInterest)
+	oi.Change24h = percentChange(parseFloat(list[len(list)-1].OpenInterest), oi.Amount)
+	return oi, nil
+}
+
+func fetchOKXOpenInterest(ctx context.Context, symbol string) (VenueOpenInterest, error) {
+	oi := VenueOpenInterest{Venue: "OKX"}
+	var current struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			OiCcy string `json:"oiCcy"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("okx")+fmt.Sprintf(okxOpenInterestAPI, symbol), "okx", &current); err != nil {
+		return oi, err
+	}
+	if current.Code != "0" || len(current.Data) == 0 {
+		return oi, fmt.Errorf("okx: %s", current.Msg)
+	}
+	oi.Amount = parseFloat(current.Data[0].OiCcy)
+
+	var hist struct {
+		Data [][]string `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("okx")+fmt.Sprintf(okxOpenInterestHistAPI, symbol), "okx", &hist); err == nil && len(hist.Data) > 24 {
+		oi.Change24h = percentChange(parseFloat(hist.Data[24][1]), parseFloat(hist.Data[0][1]))
+	}
+	return oi, nil
+}
+
+// coinSymbol returns the ticker symbol of a coin ID from the registry,
+// falling back to the input itself.
+func coinSymbol(id string) string {
+	if registry, err := loadRegistry(false); err == nil {
+		if c, ok := registry.lookup(id); ok {
+			return strings.ToUpper(c.Symbol)
+		}
+	}
+	return strings.ToUpper(id)
+}
+
+func fetchOpenInterest(ctx context.Context, coin string) OpenInterest {
+	symbol := coinSymbol(coin)
+	fetchers := []func(context.Context, string) (VenueOpenInterest, error){
+		fetchBinanceOpenInterest,
+		fetchBybitOpenInterest,
+		fetchOKXOpenInterest,
+	}
+
+	result := OpenInterest{Coin: coin, Symbol: symbol, Venues: make([]VenueOpenInterest, len(fetchers))}
+	var price float64
+	var wg sync.WaitGroup
+	wg.Add(len(fetchers) + 1)
+	for i, fetch := range fetchers {
+		go func(i int, fetch func(context.Context, string) (VenueOpenInterest, error)) {
+			defer wg.Done()
+			oi, err := fetch(ctx, symbol)
+			if err != nil {
+				oi.Error = err.Error()
+			}
+			result.Venues[i] = oi
+		}(i, fetch)
+	}
+	go func() {
+		defer wg.Done()
+		if q := quoteCoin(ctx, coin, "usd"); q.err == nil {
+			price = q.Price
+		}
+	}()
+	wg.Wait()
+
+	var before float64
+	for i := range result.Venues {
+		v := &result.Venues[i]
+		if v.Error != "" {
+			continue
+		}
+		v.ValueUSD = v.Amount * price
+		result.Total += v.Amount
+		before += v.Amount / (1 + v.Change24h/100)
+	}
+	result.TotalUSD = result.Total * price
+	result.Change24h = percentChange(before, result.Total)
+	return result
+}
+
+var oiCmd = &cobra.Command{
+	Use:   "oi <coin>",
+	Short: "Show perpetual futures open interest across Binance, Bybit and OKX",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		oi := fetchOpenInterest(cmd.Context(), coin)
+		if oi.Total == 0 {
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no open interest data for %s", coin))
+		}
+		if outputFormat == "json" {
+			return printJSON(oi)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "VENUE\tOPEN INTEREST\tVALUE\t24H CHANGE\n")
+		for _, v := range oi.Venues {
+			if v.Error != "" {
+				fmt.Fprintf(w, "%s\t-\t-\terror: %s\n", v.Venue, v.Error)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%.2f %s\t$%.0f\t%+.2f%%\n", v.Venue, v.Amount, oi.Symbol, v.ValueUSD, v.Change24h)
+		}
+		fmt.Fprintf(w, "Total\t%.2f %s\t$%.0f\t%+.2f%%\n", oi.Total, oi.Symbol, oi.TotalUSD, oi.Change24h)
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(oiCmd)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	blockchainBaseURL = "https://api.blockchain.info"
+	mempoolBaseURL    = "https://mempool.space"
+	etherscanBaseURL  = "https://api.etherscan.io"
+
+	blockchainChartAPI = "/charts/%s?timespan=1weeks&format=json"
+	mempoolFeesAPI     = "/api/v1/fees/recommended"
+	mempoolAPI         = "/api/mempool"
+)
+
+type OnchainMetric struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit,omitempty"`
+	Source string  `json:"source"`
+}
+
+func (m OnchainMetric) format() string {
+	value := fmt.Sprintf("%.2f", m.Value)
+	if m.Value == math.Trunc(m.Value) {
+		value = fmt.Sprintf("%.0f", m.Value)
+	}
+	return strings.TrimSpace(value + " " + m.Unit)
+}
+
+type OnchainReport struct {
+	Coin    string          `json:"coin"`
+	Price   float64         `json:"price,omitempty"`
+	Metrics []OnchainMetric `json:"metrics"`
+}
+
+// metricFetcher returns one or more metrics from a single request.
+type metricFetcher func(ctx context.Context) ([]OnchainMetric, error)
+
+var onchainFetchers = map[string][]metricFetcher{
+	"bitcoin": {
+		blockchainChart("n-unique-addresses", "Active addresses", ""),
+		blockchainChart("n-transactions", "Transactions (24h)", ""),
+		blockchainChart("transaction-fees-usd", "Fees (24h)", "USD"),
+		fetchMempoolFees,
+		fetchMempoolBacklog,
+	},
+	"ethereum": {
+		etherscanDaily("dailynewaddress", "newAddressCount", "New addresses (24h)", ""),
+		etherscanDaily("dailytx", "transactionCount", "Transactions (24h)", ""),
+		etherscanDaily("dailytxnfee", "transactionFee_Eth", "Fees (24h)", "ETH"),
+		fetchEtherscanGas,
+	},
+}
+
+func blockchainChart(chart, name, unit string) metricFetcher {
+	return func(ctx context.Context) ([]OnchainMetric, error) {
+		var resp struct {
+			Values []struct {
+				Y float64 `json:"y"`
+			} `json:"values"`
+		}
+		if err := getJSON(ctx, providerURL("blockchain")+fmt.Sprintf(blockchainChartAPI, chart), "blockchain", &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Values) == 0 {
+			return nil, fmt.Errorf("blockchain: no data for %s", chart)
+		}
+		return []OnchainMetric{{Name: name, Value: resp.Values[len(resp.Values)-1].Y, Unit: unit, Source: "blockchain.info"}}, nil
+	}
+}
+
+func fetchMempoolFees(ctx context.Context) ([]OnchainMetric, error) {
+	var fees struct {
+		FastestFee  float64 `json:"fastestFee"`
+		HalfHourFee float64 `json:"halfHourFee"`
+		HourFee     float64 `json:"hourFee"`
+	}
+	if err := getJSON(ctx, providerURL("mempool")+mempoolFeesAPI, "mempool", &fees); err != nil {
+		return nil, err
+	}
+	return []OnchainMetric{
+		{Name: "Fee rate (next block)", Value: fees.FastestFee, Unit: "sat/vB", Source: "mempool.space"},
+		{Name: "Fee rate (1 hour)", Value: fees.HourFee, Unit: "sat/vB", Source: "mempool.space"},
+	}, nil
+}
+
+func fetchMempoolBacklog(ctx context.Context) ([]OnchainMetric, error) {
+	var mempool struct {
+		Count float64 `json:"count"`
+	}
+	if err := getJSON(ctx, providerURL("mempool")+mempoolAPI, "mempool", &mempool); err != nil {
+		return nil, err
+	}
+	return []OnchainMetric{{Name: "Unconfirmed transactions", Value: mempool.Count, Source: "mempool.space"}}, nil
+}
+
+// etherscanURL builds an Etherscan API URL; the key goes in the query
+// string rather than a header.
+func etherscanURL(params url.Values) string {
+	if key := providerKey("etherscan"); key != "" {
+		params.Set("apikey", key)
+	}
+	return providerURL("etherscan") + "/api?" + params.Encode()
+}
+
+type etherscanResponse struct {
+	Status  string      `json:"status"`
+	Message string      `json:"message"`
+	Result  interface{} `json:"result"`
+}
+
+func (r etherscanResponse) err() error {
+	if r.Status == "1" {
+		return nil
+	}
+	if msg, ok := r.Result.(string); ok {
+		return fmt.Errorf("etherscan: %s", msg)
+	}
+	return fmt.Errorf("etherscan: %s", r.Message)
+}
+
+func etherscanDaily(action, field, name, unit string) metricFetcher {
+	return func(ctx context.Context) ([]OnchainMetric, error) {
+		day := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+		var resp etherscanResponse
+		params := url.Values{"module": {"stats"}, "action": {action}, "startdate": {day}, "enddate": {day}, "sort": {"desc"}}
+		if err := getJSON(ctx, etherscanURL(params), "etherscan", &resp); err != nil {
+			return nil, err
+		}
+		if err := resp.err(); err != nil {
+			return nil, err
+		}
+		rows, _ := resp.Result.([]interface{})
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("etherscan: no data for %s", action)
+		}
+		row, _ := rows[0].(map[string]interface{})
+		var value float64
+		switch v := row[field].(type) {
+		case float64:
+			value = v
+		case string:
+			value = parseFloat(v)
+		}
+		return []OnchainMetric{{Name: name, Value: value, Unit: unit, Source: "Etherscan"}}, nil
+	}
+}
+
+func fetchEtherscanGas(ctx context.Context) ([]OnchainMetric, error) {
+	var resp etherscanResponse
+	if err := getJSON(ctx, etherscanURL(url.Values{"module": {"gastracker"}, "action": {"gasoracle"}}), "etherscan", &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.err(); err != nil {
+		return nil, err
+	}
+	oracle, _ := resp.Result.(map[string]interface{})
+	propose, _ := oracle["ProposeGasPrice"].(string)
+	fast, _ := oracle["FastGasPrice"].(string)
+	return []OnchainMetric{
+		{Name: "Gas price", Value: parseFloat(propose), Unit: "gwei", Source: "Etherscan"},
+		{Name: "Gas price (fast)", Value: parseFloat(fast), Unit: "gwei", Source: "Etherscan"},
+	}, nil
+}
+
+func onchainCoins() []string {
+	coins := make([]string, 0, len(onchainFetchers))
+	for coin := range onchainFetchers {
+		coins = append(coins, coin)
+	}
+	sort.Strings(coins)
+	return coins
+}
+
+func fetchOnchain(ctx context.Context, coin string) (OnchainReport, []error) {
+	fetchers := onchainFetchers[coin]
+	report := OnchainReport{Coin: coin}
+	results := make([][]OnchainMetric, len(fetchers))
+	errs := make([]error, len(fetchers))
+
+	var wg sync.WaitGroup
+	wg.Add(len(fetchers) + 1)
+	for i, fetch := range fetchers {
+		go func(i int, fetch metricFetcher) {
+			defer wg.Done()
+			results[i], errs[i] = fetch(ctx)
+		}(i, fetch)
+	}
+	go func() {
+		defer wg.Done()
+		if q := quoteCoin(ctx, coin, "usd"); q.err == nil {
+			report.Price = q.Price
+		}
+	}()
+	wg.Wait()
+
+	var failed []error
+	for i := range fetchers {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		report.Metrics = append(report.Metrics, results[i]...)
+	}
+	return report, failed
+}
+
+var onchainCmd = &cobra.Command{
+	Use:   "onchain <coin>",
+	Short: "Show on-chain activity and fees next to the price",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		if _, ok := onchainFetchers[coin]; !ok {
+			return fmt.Errorf("no on-chain metrics for %s (supported: %s)", coin, strings.Join(onchainCoins(), ", "))
+		}
+
+		report, errs := fetchOnchain(cmd.Context(), coin)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if len(report.Metrics) == 0 {
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no on-chain data for %s", coin))
+		}
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+
+		if report.Price > 0 {
+			fmt.Printf("%s: %s\n", coin, formatPrice(report.Price, "usd"))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "METRIC\tVALUE\tSOURCE\n")
+		for _, m := range report.Metrics {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", m.Name, m.format(), m.Source)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(onchainCmd)
+}
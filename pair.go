@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	coinbaseExchangeBaseURL = "https://api.exchange.coinbase.com"
+
+	binanceTicker24hAPI   = "/api/v3/ticker/24hr?symbol=%s%s"
+	krakenTickerAPI       = "/0/public/Ticker?pair=%s%s"
+	coinbaseProductAPI    = "/products/%s-%s/ticker"
+	okxTickerAPI          = "/api/v5/market/ticker?instId=%s-%s"
+	bybitSpotTickerAPI    = "/v5/market/tickers?category=spot&symbol=%s%s"
+	pairNotFoundTemplate  = "%s has no %s/%s market"
+	pairExchangeNamesHelp = "binance, kraken, coinbase, okx or bybit"
+)
+
+var pairExchange string
+
+// PairQuote is one exchange's ticker for a trading pair. Prices are in the
+// quote asset; Volume is the 24h volume in the base asset.
+type PairQuote struct {
+	Exchange    string    `json:"exchange"`
+	Pair        string    `json:"pair"`
+	Base        string    `json:"base"`
+	Quote       string    `json:"quote"`
+	Last        float64   `json:"last"`
+	Bid         float64   `json:"bid,omitempty"`
+	Ask         float64   `json:"ask,omitempty"`
+	Spread      float64   `json:"spread_pct,omitempty"`
+	Volume      float64   `json:"volume_24h,omitempty"`
+	QuoteVolume float64   `json:"quote_volume_24h,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+type pairExchangeAPI struct {
+	label string
+	fetch func(ctx context.Context, base, quote string) (PairQuote, error)
+}
+
+var pairExchanges = map[string]pairExchangeAPI{
+	"binance":  {"Binance", fetchBinancePair},
+	"kraken":   {"Kraken", fetchKrakenPair},
+	"coinbase": {"Coinbase", fetchCoinbasePair},
+	"okx":      {"OKX", fetchOKXPair},
+	"bybit":    {"Bybit", fetchBybitPair},
+}
+
+var errPairNotFound = errors.New("pair not found")
+
+// getPairJSON is getJSON for exchange tickers, which answer 400 or 404 for
+// a pair they do not list.
+func getPairJSON(ctx context.Context, url, provider string, v interface{}) error {
+	resp, err := httpGet(ctx, url, provider)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest, http.StatusNotFound:
+		return errPairNotFound
+	default:
+		return statusError(provider, resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", provider, err)
+	}
+	return nil
+}
+
+// parsePair splits BTC/USDT, BTC-USDT or BTC_USDT into its assets.
+func parsePair(s string) (base, quote string, err error) {
+	fields := strings.FieldsFunc(strings.ToUpper(s), func(r rune) bool { return r == '/' || r == '-' || r == '_' })
+	if len(fields) != 2 {
+		return "", "", fmt.Errorf("invalid pair %q: use BASE/QUOTE, e.g. BTC/USDT", s)
+	}
+	return fields[0], fields[1], nil
+}
+
+func fetchBinancePair(ctx context.Context, base, quote string) (PairQuote, error) {
+	var t struct {
+		LastPrice   string `json:"lastPrice"`
+		BidPrice    string `json:"bidPrice"`
+		AskPrice    string `json:"askPrice"`
+		Volume      string `json:"volume"`
+		QuoteVolume string `json:"quoteVolume"`
+		CloseTime   int64  `json:"closeTime"`
+	}
+	if err := getPairJSON(ctx, providerURL("binance")+fmt.Sprintf(binanceTicker24hAPI, base, quote), "binance", &t); err != nil {
+		return PairQuote{}, err
+	}
+	return PairQuote{
+		Last: parseFloat(t.LastPrice), Bid: parseFloat(t.BidPrice), Ask: parseFloat(t.AskPrice),
+		Volume: parseFloat(t.Volume), QuoteVolume: parseFloat(t.QuoteVolume), Timestamp: time.UnixMilli(t.CloseTime),
+	}, nil
+}
+
+// krakenPairAssets are Kraken's own tickers for assets it names
+// differently.
+var krakenPairAssets = map[string]string{"BTC": "XBT", "DOGE": "XDG"}
+
+func krakenPairAsset(s string) string {
+	if asset, ok := krakenPairAssets[s]; ok {
+		return asset
+	}
+	return s
+}
+
+// fetchKrakenPair reads Kraken's ticker, whose fields are arrays of
+// strings: a and b start with the best ask and bid, c with the last trade
+// price, and v holds [today, last 24 hours] volume.
+func fetchKrakenPair(ctx context.Context, base, quote string) (PairQuote, error) {
+	var resp struct {
+		Error  []string `json:"error"`
+		Result map[string]struct {
+			Ask    []string `json:"a"`
+			Bid    []string `json:"b"`
+			Close  []string `json:"c"`
+			Volume []string `json:"v"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, providerURL("kraken")+fmt.Sprintf(krakenTickerAPI, krakenPairAsset(base), krakenPairAsset(quote)), "kraken", &resp); err != nil {
+		return PairQuote{}, err
+	}
+	if len(resp.Error) > 0 {
+		if strings.Contains(resp.Error[0], "Unknown asset pair") {
+			return PairQuote{}, errPairNotFound
+		}
+		return PairQuote{}, fmt.Errorf("kraken: %s", strings.Join(resp.Error, "; "))
+	}
+	for _, t := range resp.Result {
+		if len(t.Close) == 0 || len(t.Ask) == 0 || len(t.Bid) == 0 || len(t.Volume) < 2 {
+			break
+		}
+		q := PairQuote{Last: parseFloat(t.Close[0]), Bid: parseFloat(t.Bid[0]), Ask: parseFloat(t.Ask[0]), Volume: parseFloat(t.Volume[1]), Timestamp: time.Now()}
+		q.QuoteVolume = q.Volume * q.Last
+		return q, nil
+	}
+	return PairQuote{}, errPairNotFound
+}
+
+func fetchCoinbasePair(ctx context.Context, base, quote string) (PairQuote, error) {
+	var t struct {
+		Price  string    `json:"price"`
+		Bid    string    `json:"bid"`
+		Ask    string    `json:"ask"`
+		Volume string    `json:"volume"`
+		Time   time.Time `json:"time"`
+	}
+	if err := getPairJSON(ctx, providerURL("coinbase-exchange")+fmt.Sprintf(coinbaseProductAPI, base, quote), "coinbase-exchange", &t); err != nil {
+		return PairQuote{}, err
+	}
+	q := PairQuote{Last: parseFloat(t.Price), Bid: parseFloat(t.Bid), Ask: parseFloat(t.Ask), Volume: parseFloat(t.Volume), Timestamp: t.Time}
+	q.QuoteVolume = q.Volume * q.Last
+	return q, nil
+}
+
+func fetchOKXPair(ctx context.Context, base, quote string) (PairQuote, error) {
+	var resp struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Last      string `json:"last"`
+			BidPx     string `json:"bidPx"`
+			AskPx     string `json:"askPx"`
+			Vol24h    string `json:"vol24h"`
+			VolCcy24h string `json:"volCcy24h"`
+			Ts        string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("okx")+fmt.Sprintf(okxTickerAPI, base, quote), "okx", &resp); err != nil {
+		return PairQuote{}, err
+	}
+	// 51001 is OKX's "instrument ID does not exist".
+	if resp.Code == "51001" || resp.Code == "0" && len(resp.Data) == 0 {
+		return PairQuote{}, errPairNotFound
+	}
+	if resp.Code != "0" {
+		return PairQuote{}, fmt.Errorf("okx: %s", resp.Msg)
+	}
+	t := resp.Data[0]
+	ts, _ := strconv.ParseInt(t.Ts, 10, 64)
+	return PairQuote{
+		Last: parseFloat(t.Last), Bid: parseFloat(t.BidPx), Ask: parseFloat(t.AskPx),
+		Volume: parseFloat(t.Vol24h), QuoteVolume: parseFloat(t.VolCcy24h), Timestamp: time.UnixMilli(ts),
+	}, nil
+}
+
+func fetchBybitPair(ctx context.Context, base, quote string) (PairQuote, error) {
+	var resp struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Time    int64  `json:"time"`
+		Result  struct {
+			List []struct {
+				LastPrice   string `json:"lastPrice"`
+				Bid1Price   string `json:"bid1Price"`
+				Ask1Price   string `json:"ask1Price"`
+				Volume24h   string `json:"volume24h"`
+				Turnover24h string `json:"turnover24h"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := getJSON(ctx, providerURL("bybit")+fmt.Sprintf(bybitSpotTickerAPI, base, quote), "bybit", &resp); err != nil {
+		return PairQuote{}, err
+	}
+	// 10001 is Bybit's parameter error, which it returns for unknown
+	// symbols.
+	if resp.RetCode == 10001 || resp.RetCode == 0 && len(resp.Result.List) == 0 {
+		return PairQuote{}, errPairNotFound
+	}
+	if resp.RetCode != 0 {
+		return PairQuote{}, fmt.Errorf("bybit: %s", resp.RetMsg)
+	}
+	t := resp.Result.List[0]
+	return PairQuote{
+		Last: parseFloat(t.LastPrice), Bid: parseFloat(t.Bid1Price), Ask: parseFloat(t.Ask1Price),
+		Volume: parseFloat(t.Volume24h), QuoteVolume: parseFloat(t.Turnover24h), Timestamp: time.UnixMilli(resp.Time),
+	}, nil
+}
+
+func pairExchangeNames() []string {
+	names := make([]string, 0, len(pairExchanges))
+	for name := range pairExchanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func fetchPair(ctx context.Context, exchange, pair string) (PairQuote, error) {
+	base, quote, err := parsePair(pair)
+	if err != nil {
+		return PairQuote{}, err
+	}
+	api := pairExchanges[exchange]
+	q, err := api.fetch(ctx, base, quote)
+	if errors.Is(err, errPairNotFound) {
+		return q, withExitCode(exitCoinNotFound, fmt.Errorf(pairNotFoundTemplate, api.label, base, quote))
+	}
+	if err != nil {
+		return q, withExitCode(exitAllProvidersFailed, err)
+	}
+	if q.Last <= 0 {
+		return q, withExitCode(exitCoinNotFound, fmt.Errorf("%s has no recent trades for %s/%s", api.label, base, quote))
+	}
+	q.Exchange, q.Pair, q.Base, q.Quote = api.label, base+"/"+quote, base, quote
+	if q.Bid > 0 && q.Ask > 0 {
+		q.Spread = (q.Ask - q.Bid) / ((q.Ask + q.Bid) / 2) * 100
+	}
+	return q, nil
+}
+
+func printPairQuote(q PairQuote) {
+	color := useColor(os.Stdout)
+	quote := strings.ToLower(q.Quote)
+	fmt.Printf("%s on %s\n", q.Pair, q.Exchange)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  Last\t%s\n", formatPrice(q.Last, quote))
+	if q.Bid > 0 && q.Ask > 0 {
+		fmt.Fprintf(w, "  Bid\t%s\n", formatPrice(q.Bid, quote))
+		fmt.Fprintf(w, "  Ask\t%s\n", formatPrice(q.Ask, quote))
+		fmt.Fprintf(w, "  Spread\t%s (%.4f%%)\n", formatPrice(q.Ask-q.Bid, quote), q.Spread)
+	}
+	if q.Volume > 0 {
+		fmt.Fprintf(w, "  24h volume\t%s %s (%s)\n", formatVolume(q.Volume), q.Base, formatMarketValue(q.QuoteVolume, quote))
+	}
+	if !q.Timestamp.IsZero() {
+		fmt.Fprintf(w, "  Updated\t%s\n", dim(q.Timestamp.Local().Format("2006-01-02 15:04:05"), color))
+	}
+	w.Flush()
+}
+
+func printPairCSV(quotes []PairQuote) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"exchange", "pair", "last", "bid", "ask", "spread_pct", "volume_24h", "quote_volume_24h", "timestamp"})
+	for _, q := range quotes {
+		w.Write([]string{
+			q.Exchange,
+			q.Pair,
+			strconv.FormatFloat(q.Last, 'f', -1, 64),
+			strconv.FormatFloat(q.Bid, 'f', -1, 64),
+			strconv.FormatFloat(q.Ask, 'f', -1, 64),
+			strconv.FormatFloat(q.Spread, 'f', 4, 64),
+			strconv.FormatFloat(q.Volume, 'f', -1, 64),
+			strconv.FormatFloat(q.QuoteVolume, 'f', 2, 64),
+			q.Timestamp.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var pairCmd = &cobra.Command{
+	Use:   "pair <BASE/QUOTE...>",
+	Short: "Show one exchange's last price, bid/ask and 24h volume for trading pairs",
+	Long: `Show a trading pair's ticker on one exchange, rather than a price
+aggregated across providers: the last trade, best bid and ask, their
+spread, and 24h volume in the base asset and the quote asset. Pairs use
+the exchange's tickers, e.g. BTC/USDT or ETH/BTC.`,
+	Example: `  crypto-cli pair BTC/USDT --exchange binance
+  crypto-cli pair ETH/BTC --exchange kraken
+  crypto-cli pair BTC/USD ETH/USD --exchange coinbase -o json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exchange := strings.ToLower(pairExchange)
+		if _, ok := pairExchanges[exchange]; !ok {
+			return fmt.Errorf("invalid --exchange %q: must be %s", pairExchange, pairExchangeNamesHelp)
+		}
+		if offline {
+			return errOffline
+		}
+		var quotes []PairQuote
+		var failed error
+		for _, arg := range args {
+			q, err := fetchPair(cmd.Context(), exchange, arg)
+			if err != nil {
+				if len(args) == 1 {
+					return err
+				}
+				fmt.Fprintln(os.Stderr, tr("Error", "Error: %v", err))
+				failed = err
+				continue
+			}
+			quotes = append(quotes, q)
+		}
+		switch outputFormat {
+		case "json":
+			if len(args) == 1 {
+				return printJSON(quotes[0])
+			}
+			if err := printJSON(quotes); err != nil {
+				return err
+			}
+		case "csv":
+			if err := printPairCSV(quotes); err != nil {
+				return err
+			}
+		default:
+			for i, q := range quotes {
+				if i > 0 {
+					fmt.Println()
+				}
+				printPairQuote(q)
+			}
+		}
+		if failed != nil {
+			return exitSilently(exitCode(failed))
+		}
+		return nil
+	},
+}
+
+func init() {
+	pairCmd.Flags().StringVarP(&pairExchange, "exchange", "e", "binance", "exchange to quote: "+pairExchangeNamesHelp)
+	pairCmd.RegisterFlagCompletionFunc("exchange", cobra.FixedCompletions(pairExchangeNames(), cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.AddCommand(pairCmd)
+}
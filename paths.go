@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+const appName = "crypto-cli"
+
+// xdgDir returns $<env>/crypto-cli when the XDG variable is set, on any
+// platform, and otherwise the platform convention from fallback.
+func xdgDir(env string, fallback func() (string, error)) string {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+	dir, err := fallback()
+	if err != nil {
+		return filepath.Join(os.TempDir(), appName)
+	}
+	return filepath.Join(dir, appName)
+}
+
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", os.UserConfigDir)
+}
+
+func cacheDir() string {
+	return xdgDir("XDG_CACHE_HOME", os.UserCacheDir)
+}
+
+func dataDir() string {
+	return xdgDir("XDG_DATA_HOME", userDataDir)
+}
+
+func pluginDir() string {
+	return filepath.Join(configDir(), "providers")
+}
+
+func databasePath() string {
+	return filepath.Join(dataDir(), "crypto-cli.db")
+}
+
+func userDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LocalAppData"); dir != "" {
+			return dir, nil
+		}
+		return "", fmt.Errorf("%%LocalAppData%% is not set")
+	case "darwin", "ios":
+		return os.UserConfigDir()
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+var pathsCmd = &cobra.Command{
+	Use:   "paths",
+	Short: "Show where configuration, cache and data are stored",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("Config file: %s\n", configPath())
+		fmt.Printf("Config dir:  %s\n", configDir())
+		fmt.Printf("Cache dir:   %s\n", cacheDir())
+		fmt.Printf("Data dir:    %s\n", dataDir())
+		fmt.Printf("Database:    %s\n", databasePath())
+		fmt.Printf("Plugins:     %s\n", pluginDir())
+		fmt.Printf("Watchlists:  %s\n", watchlistsPath())
+		fmt.Printf("Coin map:    %s\n", coinMapPath())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pathsCmd)
+}
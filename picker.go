@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const pickerRows = 10
+
+var errNoSelection = errors.New("no coin selected")
+
+type pickerItem struct {
+	coin  Coin
+	label string
+	key   string
+}
+
+// fuzzyScore reports whether every rune of query appears in s in order and
+// scores the match, rewarding consecutive runs and an early first match.
+func fuzzyScore(query, s string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	score, qi, run := 0, 0, 0
+	first := -1
+	for i := 0; i < len(s) && qi < len(query); i++ {
+		if s[i] != query[qi] {
+			run = 0
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		run++
+		score += 1 + 2*run
+		qi++
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score - first, true
+}
+
+func filterItems(items []pickerItem, query string) []pickerItem {
+	type scored struct {
+		item  pickerItem
+		score int
+	}
+	var matches []scored
+	for _, item := range items {
+		if score, ok := fuzzyScore(query, item.key); ok {
+			matches = append(matches, scored{item, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].item.coin.ID) < len(matches[j].item.coin.ID)
+	})
+	out := make([]pickerItem, 0, pickerRows)
+	for i := 0; i < len(matches) && i < pickerRows; i++ {
+		out = append(out, matches[i].item)
+	}
+	return out
+}
+
+// pickCoinInteractively runs an inline fuzzy finder over the registry on
+// the terminal and returns the chosen coin ID.
+func pickCoinInteractively(coins []Coin) (string, error) {
+	items := make([]pickerItem, len(coins))
+	for i, c := range coins {
+		items[i] = pickerItem{
+			coin:  c,
+			label: fmt.Sprintf("%s  %s (%s)", c.ID, c.Name, strings.ToUpper(c.Symbol)),
+			key:   strings.ToLower(c.ID + " " + c.Symbol + " " + c.Name),
+		}
+	}
+
+	if accessible {
+		return pickCoinLinear(items)
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, state)
+
+	width := 80
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 4 {
+		width = w
+	}
+
+	query := ""
+	selected := 0
+	buf := make([]byte, 16)
+	for {
+		matches := filterItems(items, strings.ToLower(query))
+		selected = min(selected, max(len(matches)-1, 0))
+		renderPicker(query, matches, selected, width)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			fmt.Print("\r\x1b[J")
+			return "", err
+		}
+		input := buf[:n]
+		switch {
+		case len(input) >= 3 && input[0] == 0x1b && input[1] == '[':
+			switch input[2] {
+			case 'A':
+				selected = max(selected-1, 0)
+			case 'B':
+				selected++
+			}
+		case input[0] == 0x1b || input[0] == 3:
+			fmt.Print("\r\x1b[J")
+			return "", errNoSelection
+		case input[0] == '\r' || input[0] == '\n':
+			fmt.Print("\r\x1b[J")
+			if len(matches) == 0 {
+				return "", errNoSelection
+			}
+			return matches[selected].coin.ID, nil
+		case input[0] == 127 || input[0] == 8:
+			if query != "" {
+				query = query[:len(query)-1]
+				selected = 0
+			}
+		case input[0] == 21:
+			query, selected = "", 0
+		case input[0] == 16:
+			selected = max(selected-1, 0)
+		case input[0] == 14:
+			selected++
+		default:
+			for _, b := range input {
+				if b >= 32 && b < 127 {
+					query += string(b)
+					selected = 0
+				}
+			}
+		}
+	}
+}
+
+func renderPicker(query string, matches []pickerItem, selected, width int) {
+	var b strings.Builder
+	b.WriteString("\r\x1b[J> " + query)
+	for i, m := range matches {
+		marker := "  "
+		if i == selected {
+			marker = "> "
+		}
+		label := marker + m.label
+		if len(label) > width-1 {
+			label = label[:width-1]
+		}
+		b.WriteString("\r\n" + label)
+	}
+	if len(matches) > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", len(matches))
+	}
+	fmt.Fprintf(&b, "\r\x1b[%dC", 2+len(query))
+	fmt.Print(b.String())
+}
+
+// pickCoinLinear is the --accessible variant of the picker: it reads a
+// search line, prints the numbered matches and reads a choice, without any
+// cursor movement or redraw.
+func pickCoinLinear(items []pickerItem) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(tr("PickerSearch", "Search for a coin (empty to cancel): "))
+		line, err := reader.ReadString('\n')
+		query := strings.ToLower(strings.TrimSpace(line))
+		if query == "" {
+			if err != nil && err != io.EOF {
+				return "", err
+			}
+			return "", errNoSelection
+		}
+
+		matches := filterItems(items, query)
+		if len(matches) == 0 {
+			fmt.Println(tr("PickerNoMatches", "No matching coins."))
+			continue
+		}
+		for i, m := range matches {
+			fmt.Printf("  %d) %s\n", i+1, m.label)
+		}
+		fmt.Print(tr("PickerChoice", "Pick a coin [1-%d, default 1, 0 to search again]: ", len(matches)))
+		answer, _ := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return matches[0].coin.ID, nil
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 0 || n > len(matches) {
+			fmt.Println(tr("InvalidChoice", "invalid choice %q", answer))
+			continue
+		}
+		if n > 0 {
+			return matches[n-1].coin.ID, nil
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// pluginPaths maps each plugin provider's name to its executable.
+var pluginPaths = make(map[string]string)
+
+// loadPlugins registers every executable in the plugin directory as a
+// provider named after the file, less any extension. Files starting with
+// a dot and names taken by built-in providers are skipped.
+func loadPlugins() {
+	builtin := providers[:0:0]
+	for _, p := range providers {
+		if _, ok := pluginPaths[p.name]; !ok {
+			builtin = append(builtin, p)
+		}
+	}
+	providers = builtin
+	clear(pluginPaths)
+
+	entries, err := os.ReadDir(pluginDir())
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		path := filepath.Join(pluginDir(), e.Name())
+		if strings.HasPrefix(e.Name(), ".") || !isExecutable(path) {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+		if knownProvider(name) == nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring plugin %s: a provider named %q already exists\n", path, name)
+			continue
+		}
+		pluginPaths[name] = path
+		providers = append(providers, provider{name, name, false})
+	}
+}
+
+// isExecutable reports whether path is a file that can be run: one with an
+// execute bit, or on Windows one with an extension from PATHEXT.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS != "windows" {
+		return info.Mode()&0o111 != 0
+	}
+	exts := os.Getenv("PATHEXT")
+	if exts == "" {
+		exts = ".com;.exe;.bat;.cmd"
+	}
+	for _, ext := range strings.Split(strings.ToLower(exts), ";") {
+		if ext != "" && strings.EqualFold(filepath.Ext(path), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginEnv passes the plugin its API key, if one is set for it in any of
+// the usual places, and the timeout it has to answer in.
+func pluginEnv(p provider) []string {
+	var env []string
+	if key := providerKey(p.name); key != "" {
+		env = append(env, "CRYPTO_CLI_API_KEY="+key)
+	}
+	if requestTimeout > 0 {
+		env = append(env, "CRYPTO_CLI_TIMEOUT="+requestTimeout.String())
+	}
+	return env
+}
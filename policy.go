@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+var (
+	selectionPolicy string
+	quorumSize      int
+	quorumTolerance float64
+	preferredGrace  time.Duration
+)
+
+// validatePolicy checks --policy, which picks a single provider's price and
+// so only combines with the priority and first aggregate modes. fastest is
+// another name for --aggregate first.
+func validatePolicy() error {
+	switch selectionPolicy {
+	case "":
+		return nil
+	case "fastest", "quorum", "preferred":
+	default:
+		return fmt.Errorf("unknown policy %q (expected fastest, quorum or preferred)", selectionPolicy)
+	}
+	if aggregateMode != "first" && aggregateMode != "priority" {
+		return fmt.Errorf("--policy %s cannot be combined with --aggregate %s", selectionPolicy, aggregateMode)
+	}
+	if selectionPolicy == "fastest" {
+		aggregateMode = "first"
+	}
+	if selectionPolicy == "quorum" && quorumSize < 2 {
+		return fmt.Errorf("--quorum must be at least 2, got %d", quorumSize)
+	}
+	if selectionPolicy == "quorum" && quorumTolerance < 0 {
+		return fmt.Errorf("--quorum-tolerance must not be negative, got %g", quorumTolerance)
+	}
+	return nil
+}
+
+// selectionMode names how the price was picked, for selectionReason.
+func selectionMode() string {
+	if selectionPolicy == "quorum" || selectionPolicy == "preferred" {
+		return selectionPolicy
+	}
+	return aggregateMode
+}
+
+func quorumReason(selected pricefeed.Result, results []pricefeed.Result) string {
+	_, group, _ := pricefeed.FindQuorum(results, quorumSize, quorumTolerance)
+	names := make([]string, len(group))
+	for i, r := range group {
+		names[i] = r.Source
+	}
+	return tr("ReasonQuorum", "%d providers agree within %.2f%% (%s); %s has the middle price", len(group), quorumTolerance, strings.Join(names, ", "), selected.Source)
+}
+
+func preferredReason(selected pricefeed.Result) string {
+	preferred := "None"
+	if active := activeProviders(); len(active) > 0 {
+		preferred = active[0].label
+	}
+	if selected.Source == preferred {
+		return tr("ReasonPreferred", "%s is the preferred provider", selected.Source)
+	}
+	return tr("ReasonPreferredFallback", "%s is the highest-priority provider with a usable price; %s gave none within %s", selected.Source, preferred, preferredGrace)
+}
+
+// quorumFailure reports a quorum that was not reached although some
+// providers returned a price.
+func quorumFailure(crypto string, results []pricefeed.Result) error {
+	n := countUsable(results)
+	if n == 0 {
+		return fetchFailure(crypto, results)
+	}
+	return withExitCode(exitAllProvidersFailed, errors.New(tr("FetchFailedQuorum", "no %d providers agree on the price of %s within %.2f%% (%d usable prices)", quorumSize, crypto, quorumTolerance, n)))
+}
+
+func init() {
+	rootCmd.Flags().StringVar(&selectionPolicy, "policy", "", "Which provider price to accept: fastest (first to answer), quorum (once --quorum providers agree within --quorum-tolerance) or preferred (wait up to --grace for the top-priority provider); default is --aggregate")
+	rootCmd.Flags().IntVar(&quorumSize, "quorum", 2, "Providers that must agree under --policy quorum")
+	rootCmd.Flags().Float64Var(&quorumTolerance, "quorum-tolerance", 0.5, "Percentage within which --policy quorum prices agree")
+	rootCmd.Flags().DurationVar(&preferredGrace, "grace", 2*time.Second, "How long --policy preferred waits for the top-priority provider before falling back")
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	portfolioCost     float64
+	portfolioCurrency string
+)
+
+// Position is a holding in the portfolio file. Cost is the average price
+// paid per coin, in the portfolio's currency; zero means unknown.
+type Position struct {
+	Coin   string  `yaml:"coin" json:"coin"`
+	Amount float64 `yaml:"amount" json:"amount"`
+	Cost   float64 `yaml:"cost,omitempty" json:"cost,omitempty"`
+}
+
+type Portfolio struct {
+	Currency  string     `yaml:"currency"`
+	Positions []Position `yaml:"holdings"`
+}
+
+// portfolioPath is portfolio.yaml in the config dir, or
+// portfolio-<profile>.yaml when a profile is selected.
+func portfolioPath() string {
+	if path := config.GetString("portfolio.file"); path != "" {
+		return path
+	}
+	name := "portfolio.yaml"
+	if profileName != "" {
+		name = "portfolio-" + profileName + ".yaml"
+	}
+	return filepath.Join(configDir(), name)
+}
+
+func loadPortfolio() (*Portfolio, error) {
+	p := &Portfolio{Currency: "usd"}
+	data, err := os.ReadFile(portfolioPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, trError("ReadingFile", "reading %s: %w", portfolioPath(), err)
+	}
+	return p, nil
+}
+
+func (p *Portfolio) save() error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	path := portfolioPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// add records amount more of the coin, averaging the cost with what is
+// already held.
+func (p *Portfolio) add(coin string, amount, cost float64) {
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.Coin != coin {
+			continue
+		}
+		held, added := pricefeed.NewDecimal(pos.Amount), pricefeed.NewDecimal(amount)
+		total := held.Add(added)
+		switch {
+		case cost > 0 && pos.Cost > 0:
+			paid := pricefeed.NewDecimal(pos.Cost).Mul(held).Add(pricefeed.NewDecimal(cost).Mul(added))
+			pos.Cost = paid.Quo(total).Float64()
+		case cost > 0:
+			pos.Cost = cost
+		}
+		pos.Amount = total.Float64()
+		return
+	}
+	p.Positions = append(p.Positions, Position{Coin: coin, Amount: amount, Cost: cost})
+}
+
+// remove takes amount of the coin out of the portfolio, or all of it when
+// amount is zero.
+func (p *Portfolio) remove(coin string, amount float64) error {
+	for i, pos := range p.Positions {
+		if pos.Coin != coin {
+			continue
+		}
+		if amount <= 0 || amount >= pos.Amount {
+			p.Positions = append(p.Positions[:i], p.Positions[i+1:]...)
+		} else {
+			p.Positions[i].Amount = pricefeed.NewDecimal(pos.Amount).Sub(pricefeed.NewDecimal(amount)).Float64()
+		}
+		return nil
+	}
+	return errors.New(tr("PortfolioMissingCoin", "%s is not in the portfolio", coin))
+}
+
+type PositionValue struct {
+	Position
+	Price      float64 `json:"price"`
+	Value      float64 `json:"value"`
+	PnL        float64 `json:"pnl,omitempty"`
+	PnLPercent float64 `json:"pnl_pct,omitempty"`
+	Allocation float64 `json:"allocation_pct"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type PortfolioValuation struct {
+	Currency   string          `json:"currency"`
+	Positions  []PositionValue `json:"holdings"`
+	Value      float64         `json:"value"`
+	CostBasis  float64         `json:"cost_basis,omitempty"`
+	PnL        float64         `json:"pnl,omitempty"`
+	PnLPercent float64         `json:"pnl_pct,omitempty"`
+}
+
+// valuePortfolio prices every position concurrently. P&L covers only the
+// positions with a known cost.
+func valuePortfolio(ctx context.Context, p *Portfolio) PortfolioValuation {
+	coins := make([]string, len(p.Positions))
+	for i, pos := range p.Positions {
+		coins[i] = pos.Coin
+	}
+	prices := make(map[string]CoinQuote)
+	for _, q := range quoteCoins(ctx, coins, []string{p.Currency}) {
+		prices[q.Coin] = q
+	}
+
+	v := PortfolioValuation{Currency: p.Currency}
+	var total, costBasis, pnl pricefeed.Decimal
+	values := make([]pricefeed.Decimal, len(p.Positions))
+	for i, pos := range p.Positions {
+		pv := PositionValue{Position: pos}
+		q := prices[pos.Coin]
+		if q.err != nil || q.Price <= 0 {
+			pv.Error = "no price"
+			if q.err != nil {
+				pv.Error = q.err.Error()
+			}
+			v.Positions = append(v.Positions, pv)
+			continue
+		}
+		amount := pricefeed.NewDecimal(pos.Amount)
+		values[i] = amount.Mul(pricefeed.NewDecimal(q.Price))
+		total = total.Add(values[i])
+		pv.Price = q.Price
+		pv.Value = values[i].Float64()
+		if pos.Cost > 0 {
+			cost := amount.Mul(pricefeed.NewDecimal(pos.Cost))
+			gain := values[i].Sub(cost)
+			pv.PnL = gain.Float64()
+			pv.PnLPercent = percentOf(gain, cost)
+			costBasis = costBasis.Add(cost)
+			pnl = pnl.Add(gain)
+		}
+		v.Positions = append(v.Positions, pv)
+	}
+	for i := range v.Positions {
+		v.Positions[i].Allocation = percentOf(values[i], total)
+	}
+	v.Value, v.CostBasis, v.PnL = total.Float64(), costBasis.Float64(), pnl.Float64()
+	v.PnLPercent = percentOf(pnl, costBasis)
+	return v
+}
+
+// percentOf returns part as a percentage of whole, or zero when whole is
+// not positive.
+func percentOf(part, whole pricefeed.Decimal) float64 {
+	if whole.Sign() <= 0 {
+		return 0
+	}
+	return part.Quo(whole).Mul(pricefeed.NewDecimal(100)).Float64()
+}
+
+func formatPnL(pnl, percent float64, currency string) string {
+	sign := "+"
+	if pnl < 0 {
+		sign = "-"
+	}
+	return fmt.Sprintf("%s%s (%+.2f%%)", sign, formatPrice(math.Abs(pnl), currency), percent)
+}
+
+func printPortfolioTable(v PortfolioValuation) error {
+	color := useColor(os.Stdout)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("PortfolioTableHeader", "COIN\tAMOUNT\tPRICE\tVALUE\tP&L\tALLOCATION"))
+	for _, pv := range v.Positions {
+		amount := strconv.FormatFloat(pv.Amount, 'f', -1, 64)
+		if pv.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\t-\t-\t%s\n", pv.Coin, amount, tr("TableError", "error: %v", pv.Error))
+			continue
+		}
+		pnl := "-"
+		if pv.Cost > 0 {
+			pnl = colored(formatPnL(pv.PnL, pv.PnLPercent, v.Currency), pv.PnL >= 0, color)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.1f%%\n", pv.Coin, amount, formatPrice(pv.Price, v.Currency), formatPrice(pv.Value, v.Currency), pnl, pv.Allocation)
+	}
+	pnl := "-"
+	if v.CostBasis > 0 {
+		pnl = colored(formatPnL(v.PnL, v.PnLPercent, v.Currency), v.PnL >= 0, color)
+	}
+	fmt.Fprintf(w, "%s\t\t\t%s\t%s\t\n", tr("TableTotal", "TOTAL"), formatPrice(v.Value, v.Currency), pnl)
+	return w.Flush()
+}
+
+// portfolioFields are the columns --columns can pick for portfolio show.
+// They cover the holdings only, not the total.
+func portfolioFields(v PortfolioValuation) []field {
+	color := useColor(os.Stdout)
+	pos := v.Positions
+	priced := func(i int, s string) string {
+		if pos[i].Error != "" {
+			return "-"
+		}
+		return s
+	}
+	return []field{
+		{"coin", "COIN", func(i int) string { return pos[i].Coin }, func(i int) interface{} { return pos[i].Coin }},
+		{"amount", "AMOUNT", func(i int) string { return strconv.FormatFloat(pos[i].Amount, 'f', -1, 64) }, func(i int) interface{} { return pos[i].Amount }},
+		{"cost", "COST", func(i int) string {
+			if pos[i].Cost <= 0 {
+				return "-"
+			}
+			return formatPrice(pos[i].Cost, v.Currency)
+		}, func(i int) interface{} { return pos[i].Cost }},
+		{"price", "PRICE", func(i int) string { return priced(i, formatPrice(pos[i].Price, v.Currency)) }, func(i int) interface{} { return pos[i].Price }},
+		{"value", "VALUE", func(i int) string { return priced(i, formatPrice(pos[i].Value, v.Currency)) }, func(i int) interface{} { return pos[i].Value }},
+		{"pnl", "P&L", func(i int) string {
+			if pos[i].Error != "" || pos[i].Cost <= 0 {
+				return "-"
+			}
+			return colored(formatPnL(pos[i].PnL, pos[i].PnLPercent, v.Currency), pos[i].PnL >= 0, color)
+		}, func(i int) interface{} { return pos[i].PnL }},
+		{"pnl_pct", "P&L %", func(i int) string {
+			if pos[i].Error != "" || pos[i].Cost <= 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%+.2f%%", pos[i].PnLPercent)
+		}, func(i int) interface{} { return pos[i].PnLPercent }},
+		{"allocation_pct", "ALLOCATION", func(i int) string { return fmt.Sprintf("%.1f%%", pos[i].Allocation) }, func(i int) interface{} { return pos[i].Allocation }},
+		{"currency", "CURRENCY", func(i int) string { return strings.ToUpper(v.Currency) }, func(i int) interface{} { return v.Currency }},
+		{"error", "ERROR", func(i int) string { return dashIfEmpty(pos[i].Error) }, func(i int) interface{} { return pos[i].Error }},
+	}
+}
+
+func printPortfolioCSV(v PortfolioValuation) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"coin", "amount", "cost", "price", "value", "pnl", "pnl_pct", "allocation_pct", "currency"})
+	for _, pv := range v.Positions {
+		w.Write([]string{
+			pv.Coin,
+			strconv.FormatFloat(pv.Amount, 'f', -1, 64),
+			strconv.FormatFloat(pv.Cost, 'f', -1, 64),
+			strconv.FormatFloat(pv.Price, 'f', -1, 64),
+			strconv.FormatFloat(pv.Value, 'f', 2, 64),
+			strconv.FormatFloat(pv.PnL, 'f', 2, 64),
+			strconv.FormatFloat(pv.PnLPercent, 'f', 2, 64),
+			strconv.FormatFloat(pv.Allocation, 'f', 2, 64),
+			v.Currency,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var portfolioCmd = &cobra.Command{
+	Use:   "portfolio",
+	Short: "Track your holdings and their value",
+	Long: `Record holdings in a local portfolio file and value them with live prices.
+The file is portfolio.yaml in the config dir (portfolio-<profile>.yaml with
+--profile), or the path set as portfolio.file in the config.`,
+}
+
+var portfolioAddCmd = &cobra.Command{
+	Use:     "add <coin> <amount>",
+	Short:   "Add an amount of a coin, optionally with the price paid per coin",
+	Example: `  crypto-cli portfolio add btc 0.3 --cost 25000`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		amount, err := strconv.ParseFloat(args[1], 64)
+		if err != nil || amount <= 0 {
+			return errors.New(tr("InvalidAmount", "invalid amount %q", args[1]))
+		}
+		if portfolioCost < 0 {
+			return errors.New(tr("PortfolioNegativeCost", "--cost must not be negative"))
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		p, err := loadPortfolio()
+		if err != nil {
+			return err
+		}
+		p.add(coin, amount, portfolioCost)
+		if err := p.save(); err != nil {
+			return err
+		}
+		fmt.Print(tr("PortfolioAdded", "Added %s %s to %s\n", args[1], coin, portfolioPath()))
+		return nil
+	},
+}
+
+var portfolioRemoveCmd = &cobra.Command{
+	Use:   "remove <coin> [amount]",
+	Short: "Remove a coin, or an amount of it, from the portfolio",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var amount float64
+		if len(args) == 2 {
+			var err error
+			if amount, err = strconv.ParseFloat(args[1], 64); err != nil || amount <= 0 {
+				return errors.New(tr("InvalidAmount", "invalid amount %q", args[1]))
+			}
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		p, err := loadPortfolio()
+		if err != nil {
+			return err
+		}
+		if err := p.remove(coin, amount); err != nil {
+			return err
+		}
+		return p.save()
+	},
+}
+
+var portfolioShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Value the portfolio with live prices, with P&L and allocation",
+	Long: `Price every holding at once, batching the requests to providers that
+support it, and show each holding's value, P&L and share of the total.
+Each valuation is stored for "crypto-cli portfolio chart" unless
+--no-snapshot is given or a holding could not be priced.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, err := loadPortfolio()
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("vs-currency") {
+			p.Currency = strings.ToLower(portfolioCurrency)
+			for i := range p.Positions {
+				// Costs are recorded in the file's currency.
+				p.Positions[i].Cost = 0
+			}
+		}
+		if len(p.Positions) == 0 {
+			fmt.Println(tr("PortfolioEmpty", "The portfolio is empty. Add holdings with %q.", "crypto-cli portfolio add <coin> <amount>"))
+			return nil
+		}
+		v := valuePortfolio(cmd.Context(), p)
+		if !portfolioNoSnapshot && !mockMode && priceAt.IsZero() {
+			if err := saveSnapshot(v); err != nil {
+				fmt.Fprint(os.Stderr, tr("PortfolioSnapshotWarning", "Warning: could not store the portfolio snapshot: %v\n", err))
+			}
+		}
+		if len(selectedColumns) > 0 {
+			return printColumns(portfolioFields(v), len(v.Positions))
+		}
+		switch outputFormat {
+		case "json":
+			return printJSON(v)
+		case "csv":
+			return printPortfolioCSV(v)
+		}
+		return printPortfolioTable(v)
+	},
+}
+
+func init() {
+	portfolioAddCmd.Flags().Float64Var(&portfolioCost, "cost", 0, "price paid per coin, in the portfolio's currency")
+	portfolioShowCmd.Flags().StringSliceVar(&selectedColumns, "columns", nil, "columns to show, in order, without the total: coin, amount, cost, price, value, pnl, pnl_pct, allocation_pct, currency, error")
+	portfolioShowCmd.Flags().StringVarP(&portfolioCurrency, "vs-currency", "c", "usd", "value the portfolio in this currency instead (P&L needs the portfolio's own currency)")
+	for _, c := range []*cobra.Command{portfolioAddCmd, portfolioRemoveCmd} {
+		c.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	}
+	portfolioCmd.AddCommand(portfolioAddCmd, portfolioRemoveCmd, portfolioShowCmd)
+	rootCmd.AddCommand(portfolioCmd)
+}
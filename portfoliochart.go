@@ -0,0 +1,135 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	portfolioNoSnapshot bool
+	portfolioChartDays  int
+	portfolioChartStyle string
+	portfolioChartPnL   bool
+)
+
+// portfolioKey tells the snapshots of one portfolio file from another's,
+// such as those of different profiles.
+func portfolioKey() string {
+	if path, err := filepath.Abs(portfolioPath()); err == nil {
+		return path
+	}
+	return portfolioPath()
+}
+
+// saveSnapshot stores the valuation for portfolio chart. Valuations with a
+// position left unpriced are skipped, as their total would show a drop that
+// never happened.
+func saveSnapshot(v PortfolioValuation) error {
+	for _, pv := range v.Positions {
+		if pv.Error != "" {
+			return nil
+		}
+	}
+	db, err := openDB(databasePath())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = db.Exec(`INSERT OR REPLACE INTO portfolio_snapshots (time, portfolio, currency, value, cost_basis, pnl) VALUES (?, ?, ?, ?, ?, ?)`,
+		time.Now().Unix(), portfolioKey(), v.Currency, v.Value, v.CostBasis, v.PnL)
+	return err
+}
+
+// loadSnapshots returns the portfolio's value, or its P&L, in currency
+// since the given time, oldest first.
+func loadSnapshots(db *sql.DB, currency string, since time.Time, pnl bool) ([]PricePoint, error) {
+	column := "value"
+	if pnl {
+		column = "pnl"
+	}
+	query := `SELECT time, ` + column + ` FROM portfolio_snapshots WHERE portfolio = ? AND currency = ? AND time >= ?`
+	if pnl {
+		query += ` AND cost_basis > 0`
+	}
+	rows, err := db.Query(query+` ORDER BY time`, portfolioKey(), currency, since.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []PricePoint
+	for rows.Next() {
+		var unix int64
+		var p PricePoint
+		if err := rows.Scan(&unix, &p.Price); err != nil {
+			return nil, err
+		}
+		p.Time = time.Unix(unix, 0)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+var portfolioChartCmd = &cobra.Command{
+	Use:   "chart",
+	Short: "Chart the portfolio's value over time from past valuations",
+	Long: `Draw the portfolio's total value over the last --days from the snapshots
+"crypto-cli portfolio show" stores each time it values the portfolio, or
+with --pnl its profit and loss over the holdings with a known cost. Run
+portfolio show from cron to fill in the history.`,
+	Example: `  crypto-cli portfolio chart --days 30
+  crypto-cli portfolio chart --days 7 --pnl --style spark
+  0 * * * * crypto-cli portfolio show > /dev/null`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if portfolioChartDays < 1 {
+			return errors.New("--days must be at least 1")
+		}
+		p, err := loadPortfolio()
+		if err != nil {
+			return err
+		}
+		currency := p.Currency
+		if cmd.Flags().Changed("vs-currency") {
+			currency = strings.ToLower(portfolioCurrency)
+		}
+		db, err := openDB(databasePath())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		points, err := loadSnapshots(db, currency, time.Now().AddDate(0, 0, -portfolioChartDays), portfolioChartPnL)
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			if points == nil {
+				points = []PricePoint{}
+			}
+			return printJSON(points)
+		}
+		if len(points) < 2 {
+			return fmt.Errorf("%d %s snapshots of %s in the last %d days; at least 2 are needed, and portfolio show stores one each time it runs",
+				len(points), strings.ToUpper(currency), portfolioPath(), portfolioChartDays)
+		}
+		label := "portfolio"
+		if portfolioChartPnL {
+			label = "portfolio P&L"
+		}
+		return printChart(label, currency, portfolioChartStyle, points)
+	},
+}
+
+func init() {
+	portfolioShowCmd.Flags().BoolVar(&portfolioNoSnapshot, "no-snapshot", false, "don't store this valuation for portfolio chart")
+	portfolioChartCmd.Flags().IntVar(&portfolioChartDays, "days", 30, "number of days to chart")
+	portfolioChartCmd.Flags().StringVar(&portfolioChartStyle, "style", "candle", "chart style: candle or spark")
+	portfolioChartCmd.Flags().BoolVar(&portfolioChartPnL, "pnl", false, "chart profit and loss instead of the total value")
+	portfolioChartCmd.Flags().StringVarP(&portfolioCurrency, "vs-currency", "c", "usd", "chart the valuations made in this currency instead of the portfolio's")
+	portfolioCmd.AddCommand(portfolioChartCmd)
+}
@@ -0,0 +1,107 @@
+package pricefeed
+
+import (
+	"math"
+	"sort"
+)
+
+// Aggregation combines the usable results of several providers.
+type Aggregation struct {
+	Price    float64  `json:"price"`
+	Median   float64  `json:"median"`
+	Sources  []Result `json:"sources"`
+	Excluded []Result `json:"excluded,omitempty"`
+	Stale    []Result `json:"stale,omitempty"`
+
+	VolumeWeighted bool    `json:"volume_weighted"`
+	Spread         float64 `json:"spread_pct"`
+}
+
+// Median returns the median of values, or zero when there are none.
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return NewDecimal(sorted[mid-1]).Add(NewDecimal(sorted[mid])).Quo(NewDecimal(2)).Float64()
+	}
+	return sorted[mid]
+}
+
+// Aggregate computes a trimmed mean over the usable results, dropping any
+// source that deviates from the median by more than maxDeviation percent.
+// With volumeWeighted set, the mean is weighted by each source's reported
+// 24h volume; sources without volume are ignored unless none report it.
+// Failed results are ignored and stale ones are listed separately. Price
+// is zero when no source is usable or every one is excluded.
+func Aggregate(results []Result, maxDeviation float64, volumeWeighted bool) Aggregation {
+	var agg Aggregation
+	var prices []float64
+	for _, r := range results {
+		if r.Stale {
+			agg.Stale = append(agg.Stale, r)
+		} else if r.Usable() {
+			prices = append(prices, r.Price)
+		}
+	}
+	agg.Median = Median(prices)
+	if agg.Median == 0 {
+		return agg
+	}
+	agg.Spread = Spread(prices, agg.Median)
+
+	for _, r := range results {
+		if !r.Usable() {
+			continue
+		}
+		if maxDeviation > 0 && Deviation(r.Price, agg.Median) > maxDeviation {
+			agg.Excluded = append(agg.Excluded, r)
+			continue
+		}
+		agg.Sources = append(agg.Sources, r)
+	}
+	if len(agg.Sources) == 0 {
+		// Every source was an outlier: leave Price zero rather than
+		// averaging nothing.
+		return agg
+	}
+
+	if volumeWeighted {
+		var weighted, totalVolume Decimal
+		for _, r := range agg.Sources {
+			volume := NewDecimal(r.Volume)
+			weighted = weighted.Add(NewDecimal(r.Price).Mul(volume))
+			totalVolume = totalVolume.Add(volume)
+		}
+		if totalVolume.Sign() > 0 {
+			agg.Price = weighted.Quo(totalVolume).Float64()
+			agg.VolumeWeighted = true
+			return agg
+		}
+	}
+
+	var sum Decimal
+	for _, r := range agg.Sources {
+		sum = sum.Add(NewDecimal(r.Price))
+	}
+	agg.Price = sum.Quo(NewDecimal(float64(len(agg.Sources)))).Float64()
+	return agg
+}
+
+// Spread is the range of prices as a percentage of reference.
+func Spread(prices []float64, reference float64) float64 {
+	low, high := prices[0], prices[0]
+	for _, p := range prices[1:] {
+		low = math.Min(low, p)
+		high = math.Max(high, p)
+	}
+	return (high - low) / reference * 100
+}
+
+// Deviation is how far price is from reference, as a percentage.
+func Deviation(price, reference float64) float64 {
+	return math.Abs(price-reference) / reference * 100
+}
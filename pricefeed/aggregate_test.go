@@ -0,0 +1,148 @@
+package pricefeed_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"cli-crypto-price/pricefeed"
+	"cli-crypto-price/pricefeed/pricefeedtest"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		values []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{5}, 5},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+		{[]float64{100, 101, 99, 1000}, 100.5},
+	}
+	for _, tt := range tests {
+		if got := pricefeed.Median(tt.values); got != tt.want {
+			t.Errorf("Median(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	quote := func(source string, price, volume float64) pricefeed.Result {
+		return pricefeed.Result{Source: source, Price: price, Volume: volume}
+	}
+	tests := []struct {
+		name           string
+		results        []pricefeed.Result
+		maxDeviation   float64
+		volumeWeighted bool
+		price          float64
+		median         float64
+		excluded       []string
+	}{
+		{
+			name:    "mean of agreeing sources",
+			results: []pricefeed.Result{quote("a", 100, 0), quote("b", 102, 0), quote("c", 101, 0)},
+			price:   101, median: 101,
+		},
+		{
+			name:         "outlier excluded",
+			results:      []pricefeed.Result{quote("a", 100, 0), quote("b", 102, 0), quote("c", 150, 0)},
+			maxDeviation: 5,
+			price:        101, median: 102,
+			excluded: []string{"c"},
+		},
+		{
+			name:    "outlier kept without a max deviation",
+			results: []pricefeed.Result{quote("a", 100, 0), quote("b", 102, 0), quote("c", 150, 0)},
+			price:   352.0 / 3, median: 102,
+		},
+		{
+			name: "failed and stale results ignored",
+			results: []pricefeed.Result{
+				quote("a", 100, 0),
+				{Source: "b", Error: "rate limited", Kind: pricefeed.KindRateLimited},
+				{Source: "c", Price: 50, Stale: true},
+				quote("d", 104, 0),
+			},
+			maxDeviation: 5,
+			price:        102, median: 102,
+		},
+		{
+			name:           "volume weighted",
+			results:        []pricefeed.Result{quote("a", 100, 3), quote("b", 104, 1)},
+			volumeWeighted: true,
+			price:          101, median: 102,
+		},
+		{
+			name:           "volume weighted without volumes",
+			results:        []pricefeed.Result{quote("a", 100, 0), quote("b", 104, 0)},
+			volumeWeighted: true,
+			price:          102, median: 102,
+		},
+		{
+			name:         "every source excluded",
+			results:      []pricefeed.Result{quote("a", 100, 0), quote("b", 104, 0)},
+			maxDeviation: 0.1,
+			price:        0, median: 102,
+			excluded: []string{"a", "b"},
+		},
+		{
+			name:    "nothing usable",
+			results: []pricefeed.Result{{Source: "a", Error: "unreachable"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agg := pricefeed.Aggregate(tt.results, tt.maxDeviation, tt.volumeWeighted)
+			if math.Abs(agg.Price-tt.price) > 1e-9 {
+				t.Errorf("price = %v, want %v", agg.Price, tt.price)
+			}
+			if agg.Median != tt.median {
+				t.Errorf("median = %v, want %v", agg.Median, tt.median)
+			}
+			if got := sources(agg.Excluded); !equal(got, tt.excluded) {
+				t.Errorf("excluded = %v, want %v", got, tt.excluded)
+			}
+		})
+	}
+}
+
+func TestAggregateProviders(t *testing.T) {
+	srv := pricefeedtest.NewServer()
+	defer srv.Close()
+	srv.SetProviderPrice("kraken", "bitcoin", "usd", 80000)
+	srv.Fail("coinbase", pricefeedtest.RateLimited)
+	client := pricefeed.NewClient(srv.Providers(srv.Client())...)
+
+	agg := pricefeed.Aggregate(client.All(context.Background(), "bitcoin", "usd"), 5, false)
+	if agg.Price != 67000 || agg.Median != 67000 {
+		t.Errorf("price = %v, median = %v, want 67000", agg.Price, agg.Median)
+	}
+	if got := sources(agg.Excluded); !equal(got, []string{"Kraken"}) {
+		t.Errorf("excluded = %v, want [Kraken]", got)
+	}
+	if len(agg.Sources) != 4 {
+		t.Errorf("%d sources, want 4: %v", len(agg.Sources), sources(agg.Sources))
+	}
+}
+
+func sources(results []pricefeed.Result) []string {
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Source)
+	}
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
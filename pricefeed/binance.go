@@ -0,0 +1,102 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	BinanceBaseURL = "https://api.binance.com"
+
+	binanceAPI = "/api/v3/ticker/price?symbol=%s"
+)
+
+type binanceResponse struct {
+	Symbol string `json:"symbol"`
+	Price  string `json:"price"`
+}
+
+// Binance quotes spot prices from the Binance ticker API. Binance trades
+// symbols rather than coin IDs: Symbol maps a coin ID such as "bitcoin" to
+// its ticker "BTC", and defaults to a table of common coins. USD is quoted
+// through the USDT market. An empty BaseURL uses BinanceBaseURL and a nil
+// HTTPClient uses http.DefaultClient.
+type Binance struct {
+	BaseURL    string
+	Symbol     func(coin string) string
+	HTTPClient *http.Client
+}
+
+func (p *Binance) Name() string { return "Binance" }
+
+func (p *Binance) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = BinanceBaseURL
+	}
+	symbol := commonSymbol(coin)
+	if p.Symbol != nil {
+		symbol = p.Symbol(coin)
+	}
+	pair := strings.ToUpper(symbol) + binanceQuoteAsset(currency)
+
+	var result binanceResponse
+	err := getJSON(ctx, p.HTTPClient, base+fmt.Sprintf(binanceAPI, pair), "", "", &result)
+	var status *StatusError
+	if errors.As(err, &status) && status.Code == http.StatusBadRequest {
+		return Quote{}, noMarket(pair, coin)
+	}
+	if err != nil {
+		return Quote{}, err
+	}
+
+	price, err := strconv.ParseFloat(result.Price, 64)
+	if err != nil {
+		return Quote{}, invalidPrice(result.Price)
+	}
+	if price <= 0 {
+		return Quote{}, noPrice(coin, currency)
+	}
+	return Quote{Coin: coin, Currency: currency, Price: price, Source: p.Name()}, nil
+}
+
+func binanceQuoteAsset(currency string) string {
+	if currency == "usd" {
+		return "USDT"
+	}
+	return strings.ToUpper(currency)
+}
+
+var commonSymbols = map[string]string{
+	"bitcoin":      "BTC",
+	"ethereum":     "ETH",
+	"tether":       "USDT",
+	"binancecoin":  "BNB",
+	"solana":       "SOL",
+	"ripple":       "XRP",
+	"usd-coin":     "USDC",
+	"cardano":      "ADA",
+	"dogecoin":     "DOGE",
+	"tron":         "TRX",
+	"avalanche-2":  "AVAX",
+	"polkadot":     "DOT",
+	"chainlink":    "LINK",
+	"litecoin":     "LTC",
+	"bitcoin-cash": "BCH",
+	"stellar":      "XLM",
+	"uniswap":      "UNI",
+	"cosmos":       "ATOM",
+}
+
+// commonSymbol returns the ticker for well-known coin IDs, and otherwise
+// the ID itself in upper case.
+func commonSymbol(coin string) string {
+	if symbol, ok := commonSymbols[coin]; ok {
+		return symbol
+	}
+	return strings.ToUpper(coin)
+}
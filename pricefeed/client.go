@@ -0,0 +1,156 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Client queries several providers concurrently. Providers are listed in
+// order of preference, which ByPriority and All use to rank results.
+type Client struct {
+	Providers []Provider
+
+	// MaxAge marks quotes last updated longer ago than this as stale;
+	// zero accepts any age.
+	MaxAge time.Duration
+
+	// OnResult, if set, is called with every provider's result as it
+	// arrives, from the fetching goroutine. Requests abandoned because
+	// the context was canceled are not reported.
+	OnResult func(Result)
+}
+
+// NewClient returns a Client over the providers, in order of preference.
+func NewClient(providers ...Provider) *Client {
+	return &Client{Providers: providers}
+}
+
+// Stream starts a fetch from every provider and returns a channel that
+// receives each result as it arrives and is closed once all have answered.
+// The channel is buffered, so callers may stop reading early; canceling
+// ctx abandons the requests still in flight.
+func (c *Client) Stream(ctx context.Context, coin, currency string) <-chan Result {
+	out := make(chan Result, len(c.Providers))
+	var wg sync.WaitGroup
+	wg.Add(len(c.Providers))
+	for _, p := range c.Providers {
+		go func(p Provider) {
+			defer wg.Done()
+			out <- c.fetch(ctx, p, coin, currency)
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+func (c *Client) fetch(ctx context.Context, p Provider, coin, currency string) Result {
+	start := time.Now()
+	q, err := p.Fetch(ctx, coin, currency)
+	r := Result{Source: p.Name(), Duration: time.Since(start)}
+	switch {
+	case err == nil:
+		r.Price, r.Volume, r.Timestamp = q.Price, q.Volume, q.Timestamp
+	case errors.Is(ctx.Err(), context.Canceled):
+		r.Error = "canceled"
+		return r
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		r.Error, r.Kind = "timed out", KindUnreachable
+	default:
+		r.Error = err.Error()
+		r.Kind = Classify(err)
+	}
+	if c.MaxAge > 0 && r.Age() > c.MaxAge {
+		r.Stale = true
+	}
+	if c.OnResult != nil {
+		c.OnResult(r)
+	}
+	return r
+}
+
+// First returns the first usable result to arrive, along with every result
+// seen until then, and cancels the remaining requests.
+func (c *Client) First(ctx context.Context, coin, currency string) (Result, []Result) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var seen []Result
+	for r := range c.Stream(ctx, coin, currency) {
+		seen = append(seen, r)
+		if r.Usable() {
+			return r, seen
+		}
+	}
+	return Result{Source: "None"}, seen
+}
+
+// ByPriority returns the usable result from the most preferred provider.
+// It returns as soon as every provider ranked above the current best has
+// answered, so a fast low-priority source never wins over a slower
+// preferred one, canceling the remaining requests. The results seen are
+// returned in order of preference.
+func (c *Client) ByPriority(ctx context.Context, coin, currency string) (Result, []Result) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	best := Result{Source: "None"}
+	bestRank := len(c.Providers)
+	answered := make([]bool, len(c.Providers))
+	var seen []Result
+
+	for r := range c.Stream(ctx, coin, currency) {
+		seen = append(seen, r)
+		rank := c.rank(r.Source)
+		if rank < len(answered) {
+			answered[rank] = true
+		}
+		if r.Usable() && rank < bestRank {
+			best, bestRank = r, rank
+		}
+		if bestRank < len(c.Providers) && allTrue(answered[:bestRank]) {
+			break
+		}
+	}
+
+	c.sortByRank(seen)
+	return best, seen
+}
+
+// All waits for every provider and returns the results in order of
+// preference.
+func (c *Client) All(ctx context.Context, coin, currency string) []Result {
+	var results []Result
+	for r := range c.Stream(ctx, coin, currency) {
+		results = append(results, r)
+	}
+	c.sortByRank(results)
+	return results
+}
+
+func (c *Client) rank(source string) int {
+	for i, p := range c.Providers {
+		if p.Name() == source {
+			return i
+		}
+	}
+	return len(c.Providers)
+}
+
+func (c *Client) sortByRank(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return c.rank(results[i].Source) < c.rank(results[j].Source)
+	})
+}
+
+func allTrue(values []bool) bool {
+	for _, v := range values {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
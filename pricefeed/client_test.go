@@ -0,0 +1,99 @@
+package pricefeed_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"cli-crypto-price/pricefeed/pricefeedtest"
+)
+
+func TestFetchErrorKinds(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure pricefeedtest.Failure
+		kind    pricefeed.ErrorKind
+	}{
+		{"ok", pricefeedtest.OK, ""},
+		{"rate limited", pricefeedtest.RateLimited, pricefeed.KindRateLimited},
+		{"server error", pricefeedtest.ServerError, pricefeed.KindUnreachable},
+		{"malformed JSON", pricefeedtest.Malformed, pricefeed.KindInvalidResponse},
+		{"not found", pricefeedtest.NotFound, pricefeed.KindNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := pricefeedtest.NewServer()
+			defer srv.Close()
+			for _, name := range pricefeedtest.Providers {
+				srv.Fail(name, tt.failure)
+			}
+			client := pricefeed.NewClient(srv.Providers(srv.Client())...)
+			for _, r := range client.All(context.Background(), "bitcoin", "usd") {
+				if r.Kind != tt.kind {
+					t.Errorf("%s: kind = %q (%s), want %q", r.Source, r.Kind, r.Error, tt.kind)
+				}
+				if tt.kind == "" && r.Price != 67000 {
+					t.Errorf("%s: price = %v, want 67000", r.Source, r.Price)
+				}
+				if r.RateLimited() != (tt.kind == pricefeed.KindRateLimited) {
+					t.Errorf("%s: RateLimited() = %v", r.Source, r.RateLimited())
+				}
+			}
+		})
+	}
+}
+
+func TestByPriority(t *testing.T) {
+	tests := []struct {
+		name   string
+		failed []string
+		want   string
+	}{
+		{"preferred answers", nil, "CoinGecko"},
+		{"preferred fails", []string{"coingecko"}, "CoinMarketCap"},
+		{"first two fail", []string{"coingecko", "coinmarketcap"}, "CryptoCompare"},
+		{"all fail", pricefeedtest.Providers, "None"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := pricefeedtest.NewServer()
+			defer srv.Close()
+			for _, name := range tt.failed {
+				srv.Fail(name, pricefeedtest.ServerError)
+			}
+			client := pricefeed.NewClient(srv.Providers(srv.Client())...)
+			best, seen := client.ByPriority(context.Background(), "bitcoin", "usd")
+			if best.Source != tt.want {
+				t.Errorf("source = %q, want %q", best.Source, tt.want)
+			}
+			for i := 1; i < len(seen); i++ {
+				if rank(seen[i].Source) < rank(seen[i-1].Source) {
+					t.Errorf("results out of order: %s before %s", seen[i-1].Source, seen[i].Source)
+				}
+			}
+		})
+	}
+}
+
+func TestStaleQuotes(t *testing.T) {
+	srv := pricefeedtest.NewServer()
+	defer srv.Close()
+	client := pricefeed.NewClient(srv.Providers(srv.Client())[0])
+	client.MaxAge = 10 * time.Second
+
+	r := client.All(context.Background(), "bitcoin", "usd")[0]
+	if !r.Stale || r.Usable() {
+		t.Errorf("30s old quote with a 10s MaxAge: stale = %v, usable = %v", r.Stale, r.Usable())
+	}
+}
+
+// rank is the position of the source in pricefeedtest.Providers.
+func rank(source string) int {
+	for i, name := range []string{"CoinGecko", "CoinMarketCap", "CryptoCompare", "Binance", "Kraken", "Coinbase"} {
+		if name == source {
+			return i
+		}
+	}
+	return -1
+}
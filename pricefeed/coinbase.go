@@ -0,0 +1,66 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	CoinbaseBaseURL = "https://api.coinbase.com"
+
+	coinbaseAPI = "/v2/prices/%s/spot"
+)
+
+type coinbaseResponse struct {
+	Data struct {
+		Amount   string `json:"amount"`
+		Base     string `json:"base"`
+		Currency string `json:"currency"`
+	} `json:"data"`
+}
+
+// Coinbase quotes spot prices from the Coinbase price API. Symbol maps a
+// coin ID to its ticker, as for Binance. An empty BaseURL uses
+// CoinbaseBaseURL and a nil HTTPClient uses http.DefaultClient.
+type Coinbase struct {
+	BaseURL    string
+	Symbol     func(coin string) string
+	HTTPClient *http.Client
+}
+
+func (p *Coinbase) Name() string { return "Coinbase" }
+
+func (p *Coinbase) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = CoinbaseBaseURL
+	}
+	symbol := commonSymbol(coin)
+	if p.Symbol != nil {
+		symbol = p.Symbol(coin)
+	}
+	pair := strings.ToUpper(symbol) + "-" + strings.ToUpper(currency)
+
+	var result coinbaseResponse
+	err := getJSON(ctx, p.HTTPClient, base+fmt.Sprintf(coinbaseAPI, pair), "", "", &result)
+	var status *StatusError
+	if errors.As(err, &status) && (status.Code == http.StatusNotFound || status.Code == http.StatusBadRequest) {
+		return Quote{}, noMarket(pair, coin)
+	}
+	if err != nil {
+		return Quote{}, err
+	}
+
+	price, err := strconv.ParseFloat(result.Data.Amount, 64)
+	if err != nil {
+		return Quote{}, invalidPrice(result.Data.Amount)
+	}
+	if price <= 0 {
+		return Quote{}, noPrice(coin, currency)
+	}
+	return Quote{Coin: coin, Currency: currency, Price: price, Source: p.Name()}, nil
+}
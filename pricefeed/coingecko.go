@@ -0,0 +1,83 @@
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	CoinGeckoBaseURL    = "https://api.coingecko.com/api/v3"
+	CoinGeckoProBaseURL = "https://pro-api.coingecko.com/api/v3"
+
+	coingeckoAPI = "/simple/price?ids=%s&vs_currencies=%s&include_24hr_vol=true&include_last_updated_at=true"
+)
+
+// CoinGecko keys its fields by the lowercase currency, e.g. "eur" and
+// "eur_24h_vol".
+type coinGeckoResponse map[string]map[string]float64
+
+// CoinGecko quotes prices from the CoinGecko simple price API. APIKey is a
+// demo key, or a paid plan's key when Pro is set. An empty BaseURL uses
+// CoinGeckoBaseURL, or CoinGeckoProBaseURL with Pro, and a nil HTTPClient
+// uses http.DefaultClient.
+type CoinGecko struct {
+	BaseURL    string
+	APIKey     string
+	Pro        bool
+	HTTPClient *http.Client
+}
+
+func (p *CoinGecko) Name() string { return "CoinGecko" }
+
+func (p *CoinGecko) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	quotes, err := p.FetchBatch(ctx, []string{coin}, []string{currency})
+	if err != nil {
+		return Quote{}, err
+	}
+	if len(quotes) == 0 {
+		return Quote{}, noPrice(coin, currency)
+	}
+	return quotes[0], nil
+}
+
+// FetchBatch prices all the coins in all the currencies with one request,
+// as the simple price API takes comma-separated lists of both.
+func (p *CoinGecko) FetchBatch(ctx context.Context, coins, currencies []string) ([]Quote, error) {
+	base, header := p.BaseURL, "x-cg-demo-api-key"
+	if p.Pro {
+		header = "x-cg-pro-api-key"
+	}
+	if base == "" {
+		base = CoinGeckoBaseURL
+		if p.Pro {
+			base = CoinGeckoProBaseURL
+		}
+	}
+	var result coinGeckoResponse
+	url := base + fmt.Sprintf(coingeckoAPI, strings.Join(coins, ","), strings.Join(currencies, ","))
+	if err := getJSON(ctx, p.HTTPClient, url, header, p.APIKey, &result); err != nil {
+		return nil, err
+	}
+
+	var quotes []Quote
+	for _, coin := range coins {
+		fields := result[coin]
+		for _, currency := range currencies {
+			price, ok := fields[currency]
+			if !ok {
+				continue
+			}
+			quotes = append(quotes, Quote{
+				Coin:      coin,
+				Currency:  currency,
+				Price:     price,
+				Source:    p.Name(),
+				Volume:    fields[currency+"_24h_vol"],
+				Timestamp: unixTime(int64(fields["last_updated_at"])),
+			})
+		}
+	}
+	return quotes, nil
+}
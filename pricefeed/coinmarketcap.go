@@ -0,0 +1,87 @@
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	CoinMarketCapBaseURL = "https://pro-api.coinmarketcap.com"
+
+	coinmarketcapAPI = "/v2/cryptocurrency/quotes/latest?slug=%s&convert=%s"
+)
+
+// CoinMarketCap keys its data by numeric coin ID and each quote by the
+// uppercase currency.
+type coinMarketCapResponse struct {
+	Status struct {
+		ErrorCode    int    `json:"error_code"`
+		ErrorMessage string `json:"error_message"`
+	} `json:"status"`
+	Data map[string]struct {
+		Slug  string `json:"slug"`
+		Quote map[string]struct {
+			Price       float64   `json:"price"`
+			Volume24h   float64   `json:"volume_24h"`
+			LastUpdated time.Time `json:"last_updated"`
+		} `json:"quote"`
+	} `json:"data"`
+}
+
+// CoinMarketCap quotes prices from the CoinMarketCap API, which requires
+// an API key. An empty BaseURL uses CoinMarketCapBaseURL and a nil
+// HTTPClient uses http.DefaultClient.
+type CoinMarketCap struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+func (p *CoinMarketCap) Name() string { return "CoinMarketCap" }
+
+func (p *CoinMarketCap) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	if p.APIKey == "" {
+		return Quote{}, ErrNoAPIKey
+	}
+	base := p.BaseURL
+	if base == "" {
+		base = CoinMarketCapBaseURL
+	}
+	var result coinMarketCapResponse
+	err := getJSON(ctx, p.HTTPClient, base+fmt.Sprintf(coinmarketcapAPI, url.QueryEscape(coin), strings.ToUpper(currency)), "X-CMC_PRO_API_KEY", p.APIKey, &result)
+	var status *StatusError
+	if errors.As(err, &status) && status.Code == http.StatusBadRequest {
+		// CoinMarketCap rejects unknown slugs as invalid values.
+		return Quote{}, noPrice(coin, currency)
+	}
+	if err != nil {
+		return Quote{}, err
+	}
+	if result.Status.ErrorCode != 0 {
+		return Quote{}, fmt.Errorf("error %d: %s", result.Status.ErrorCode, result.Status.ErrorMessage)
+	}
+
+	for _, data := range result.Data {
+		if data.Slug != coin {
+			continue
+		}
+		quote, ok := data.Quote[strings.ToUpper(currency)]
+		if !ok || quote.Price <= 0 {
+			break
+		}
+		return Quote{
+			Coin:      coin,
+			Currency:  currency,
+			Price:     quote.Price,
+			Source:    p.Name(),
+			Volume:    quote.Volume24h,
+			Timestamp: quote.LastUpdated,
+		}, nil
+	}
+	return Quote{}, noPrice(coin, currency)
+}
@@ -0,0 +1,49 @@
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	CryptoCompareBaseURL = "https://min-api.cryptocompare.com"
+
+	cryptocompareAPI = "/data/price?fsym=%s&tsyms=%s"
+)
+
+// CryptoCompare returns {"EUR": price}.
+type cryptoCompareResponse map[string]interface{}
+
+// CryptoCompare quotes prices from the CryptoCompare price API. An empty
+// BaseURL uses CryptoCompareBaseURL and a nil HTTPClient uses
+// http.DefaultClient.
+type CryptoCompare struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+func (p *CryptoCompare) Name() string { return "CryptoCompare" }
+
+func (p *CryptoCompare) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = CryptoCompareBaseURL
+	}
+	key := ""
+	if p.APIKey != "" {
+		key = "Apikey " + p.APIKey
+	}
+	var result cryptoCompareResponse
+	if err := getJSON(ctx, p.HTTPClient, base+fmt.Sprintf(cryptocompareAPI, coin, strings.ToUpper(currency)), "authorization", key, &result); err != nil {
+		return Quote{}, err
+	}
+
+	price, _ := result[strings.ToUpper(currency)].(float64)
+	if price == 0 {
+		return Quote{}, noPrice(coin, currency)
+	}
+	return Quote{Coin: coin, Currency: currency, Price: price, Source: p.Name()}, nil
+}
@@ -0,0 +1,99 @@
+package pricefeed
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an exact decimal number, for sums and products of prices and
+// amounts where float64 arithmetic would pile up binary rounding errors
+// (0.1 + 0.2 is 0.3, not 0.30000000000000004). The zero value is 0.
+// Decimals are immutable: every operation returns a new value.
+type Decimal struct {
+	r *big.Rat
+}
+
+// NewDecimal returns the decimal f prints as, so NewDecimal(0.1) is
+// exactly one tenth. NaN and infinities become 0.
+func NewDecimal(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}
+	}
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return Decimal{r}
+}
+
+// ParseDecimal parses a number in decimal or exponent notation, such as
+// "0.0000241" or "2.41e-5".
+func ParseDecimal(s string) (Decimal, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.Contains(s, "/") {
+		return Decimal{}, fmt.Errorf("invalid number %q", s)
+	}
+	return Decimal{r}, nil
+}
+
+func (d Decimal) rat() *big.Rat {
+	if d.r == nil {
+		return new(big.Rat)
+	}
+	return d.r
+}
+
+// Add returns d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	return Decimal{new(big.Rat).Add(d.rat(), e.rat())}
+}
+
+// Sub returns d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	return Decimal{new(big.Rat).Sub(d.rat(), e.rat())}
+}
+
+// Mul returns d * e.
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{new(big.Rat).Mul(d.rat(), e.rat())}
+}
+
+// Quo returns d / e, or 0 when e is 0.
+func (d Decimal) Quo(e Decimal) Decimal {
+	if e.Sign() == 0 {
+		return Decimal{}
+	}
+	return Decimal{new(big.Rat).Quo(d.rat(), e.rat())}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{new(big.Rat).Neg(d.rat())}
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.rat().Sign()
+}
+
+// Cmp returns -1, 0 or 1 as d is less than, equal to or greater than e.
+func (d Decimal) Cmp(e Decimal) int {
+	return d.rat().Cmp(e.rat())
+}
+
+// Float64 returns the float64 nearest to d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.rat().Float64()
+	return f
+}
+
+// StringFixed formats d with the given number of decimals, rounding half
+// away from zero.
+func (d Decimal) StringFixed(decimals int) string {
+	return d.rat().FloatString(decimals)
+}
+
+// String formats d to 20 significant digits.
+func (d Decimal) String() string {
+	return new(big.Float).SetPrec(128).SetRat(d.rat()).Text('g', 20)
+}
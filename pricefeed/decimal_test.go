@@ -0,0 +1,62 @@
+package pricefeed_test
+
+import (
+	"math"
+	"testing"
+
+	"cli-crypto-price/pricefeed"
+)
+
+func TestDecimal(t *testing.T) {
+	d := pricefeed.NewDecimal
+	tests := []struct {
+		name  string
+		got   pricefeed.Decimal
+		fixed string
+		str   string
+	}{
+		{"exact sum", d(0.1).Add(d(0.2)), "0.30", "0.3"},
+		{"exact difference", d(1.1).Sub(d(0.7)), "0.40", "0.4"},
+		{"small product", d(0.0000241).Mul(d(1e6)), "24.10", "24.1"},
+		{"repeating quotient", d(1).Quo(d(3)), "0.33", "0.33333333333333333333"},
+		{"division by zero", d(5).Quo(d(0)), "0.00", "0"},
+		{"negation", d(2.5).Neg(), "-2.50", "-2.5"},
+		{"NaN", d(math.NaN()), "0.00", "0"},
+		{"zero value", pricefeed.Decimal{}, "0.00", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.got.StringFixed(2); got != tt.fixed {
+				t.Errorf("StringFixed(2) = %s, want %s", got, tt.fixed)
+			}
+			if got := tt.got.String(); got != tt.str {
+				t.Errorf("String() = %s, want %s", got, tt.str)
+			}
+		})
+	}
+}
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"0.0000241", "0.0000241", true},
+		{"2.41e-5", "0.0000241", true},
+		{"-3", "-3.0000000", true},
+		{"1/3", "", false},
+		{"abc", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := pricefeed.ParseDecimal(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseDecimal(%q): err = %v, want ok = %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && got.StringFixed(7) != tt.want {
+			t.Errorf("ParseDecimal(%q) = %s, want %s", tt.in, got.StringFixed(7), tt.want)
+		}
+	}
+}
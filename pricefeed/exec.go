@@ -0,0 +1,90 @@
+package pricefeed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// execResponse is what an Exec command prints on stdout. Timestamp is in
+// Unix seconds. On failure it prints an error instead of a price, with
+// ErrorKind set to one of the ErrorKind values when it knows why.
+type execResponse struct {
+	Price     *float64  `json:"price"`
+	Volume    float64   `json:"volume"`
+	Timestamp int64     `json:"timestamp"`
+	Error     string    `json:"error"`
+	Kind      ErrorKind `json:"error_kind"`
+}
+
+var execKinds = map[ErrorKind]error{
+	KindNotFound:        ErrNotFound,
+	KindRateLimited:     ErrRateLimited,
+	KindUnreachable:     ErrUnreachable,
+	KindNoAPIKey:        ErrNoAPIKey,
+	KindInvalidResponse: ErrInvalidResponse,
+}
+
+// Exec quotes prices by running an external command as
+// "<Path> <coin> <currency>" and reading a JSON object such as
+// {"price": 67000.5} from its stdout. Env is added to the command's
+// environment. A command that exits with an error fails with the last
+// line of its stderr.
+type Exec struct {
+	Label string
+	Path  string
+	Env   []string
+}
+
+func (p *Exec) Name() string { return p.Label }
+
+func (p *Exec) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	cmd := exec.CommandContext(ctx, p.Path, coin, currency)
+	cmd.Env = append(os.Environ(), p.Env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return Quote{}, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return Quote{}, err
+		}
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		if msg := lines[len(lines)-1]; msg != "" {
+			return Quote{}, fmt.Errorf("%s (exit status %d)", msg, exitErr.ExitCode())
+		}
+		return Quote{}, err
+	}
+
+	var result execResponse
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return Quote{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	if result.Error != "" {
+		if kind, ok := execKinds[result.Kind]; ok {
+			return Quote{}, &kindError{kind, result.Error}
+		}
+		return Quote{}, errors.New(result.Error)
+	}
+	if result.Price == nil {
+		return Quote{}, &kindError{ErrInvalidResponse, "invalid response: no price"}
+	}
+	if *result.Price <= 0 {
+		return Quote{}, noPrice(coin, currency)
+	}
+	return Quote{
+		Coin:      coin,
+		Currency:  currency,
+		Price:     *result.Price,
+		Source:    p.Name(),
+		Volume:    result.Volume,
+		Timestamp: unixTime(result.Timestamp),
+	}, nil
+}
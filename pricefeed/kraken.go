@@ -0,0 +1,97 @@
+package pricefeed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	KrakenBaseURL = "https://api.kraken.com"
+
+	krakenAPI = "/0/public/Ticker?pair=%s"
+)
+
+// Kraken answers with its own name for the pair, such as XXBTZUSD for
+// XBTUSD. Each ticker field is an array of strings: c is the last trade
+// [price, lot volume], and v and p hold [today, last 24 hours] volume in
+// the base asset and volume-weighted average price.
+type krakenResponse struct {
+	Error  []string `json:"error"`
+	Result map[string]struct {
+		Close  []string `json:"c"`
+		Volume []string `json:"v"`
+		VWAP   []string `json:"p"`
+	} `json:"result"`
+}
+
+// krakenAssets are the tickers Kraken uses for assets it names differently.
+var krakenAssets = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+// Kraken quotes prices from Kraken's public Ticker API. Symbol maps a coin
+// ID to its ticker, as for Binance. An empty BaseURL uses KrakenBaseURL and
+// a nil HTTPClient uses http.DefaultClient.
+type Kraken struct {
+	BaseURL    string
+	Symbol     func(coin string) string
+	HTTPClient *http.Client
+}
+
+func (p *Kraken) Name() string { return "Kraken" }
+
+func (p *Kraken) Fetch(ctx context.Context, coin, currency string) (Quote, error) {
+	base := p.BaseURL
+	if base == "" {
+		base = KrakenBaseURL
+	}
+	symbol := commonSymbol(coin)
+	if p.Symbol != nil {
+		symbol = p.Symbol(coin)
+	}
+	pair := krakenAsset(symbol) + krakenAsset(currency)
+
+	var result krakenResponse
+	if err := getJSON(ctx, p.HTTPClient, base+fmt.Sprintf(krakenAPI, pair), "", "", &result); err != nil {
+		return Quote{}, err
+	}
+	if len(result.Error) > 0 {
+		if strings.Contains(result.Error[0], "Unknown asset pair") {
+			return Quote{}, noMarket(pair, coin)
+		}
+		return Quote{}, fmt.Errorf("error: %s", strings.Join(result.Error, "; "))
+	}
+
+	for _, ticker := range result.Result {
+		if len(ticker.Close) == 0 {
+			break
+		}
+		price, err := strconv.ParseFloat(ticker.Close[0], 64)
+		if err != nil {
+			return Quote{}, invalidPrice(ticker.Close[0])
+		}
+		if price <= 0 {
+			break
+		}
+		q := Quote{Coin: coin, Currency: currency, Price: price, Source: p.Name()}
+		if len(ticker.Volume) > 1 && len(ticker.VWAP) > 1 {
+			volume, _ := strconv.ParseFloat(ticker.Volume[1], 64)
+			vwap, _ := strconv.ParseFloat(ticker.VWAP[1], 64)
+			q.Volume = volume * vwap
+		}
+		return q, nil
+	}
+	return Quote{}, noPrice(coin, currency)
+}
+
+func krakenAsset(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	if asset, ok := krakenAssets[symbol]; ok {
+		return asset
+	}
+	return symbol
+}
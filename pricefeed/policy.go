@@ -0,0 +1,99 @@
+package pricefeed
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// FindQuorum looks for at least n usable results whose prices all lie
+// within tolerance percent of the lowest of them. It returns the middle
+// result of the largest such group and the group's members, or false when
+// no n results agree.
+func FindQuorum(results []Result, n int, tolerance float64) (Result, []Result, bool) {
+	var usable []Result
+	for _, r := range results {
+		if r.Usable() {
+			usable = append(usable, r)
+		}
+	}
+	sort.SliceStable(usable, func(i, j int) bool { return usable[i].Price < usable[j].Price })
+
+	var best []Result
+	for lo, hi := 0, 0; hi < len(usable); hi++ {
+		for lo < hi && (usable[hi].Price-usable[lo].Price)/usable[lo].Price*100 > tolerance {
+			lo++
+		}
+		if hi-lo+1 > len(best) {
+			best = usable[lo : hi+1]
+		}
+	}
+	if n < 1 || len(best) < n {
+		return Result{Source: "None"}, nil, false
+	}
+	return best[(len(best)-1)/2], best, true
+}
+
+// Quorum returns as soon as n providers agree on the price within
+// tolerance percent, canceling the remaining requests. The selected result
+// is the middle one of the agreeing group; see FindQuorum. The results seen
+// are returned in order of preference.
+func (c *Client) Quorum(ctx context.Context, coin, currency string, n int, tolerance float64) (Result, []Result) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var seen []Result
+	best := Result{Source: "None"}
+	for r := range c.Stream(ctx, coin, currency) {
+		seen = append(seen, r)
+		if !r.Usable() {
+			continue
+		}
+		if agreed, _, ok := FindQuorum(seen, n, tolerance); ok {
+			best = agreed
+			break
+		}
+	}
+	c.sortByRank(seen)
+	return best, seen
+}
+
+// Preferred waits up to grace for the most preferred provider. If it
+// answers with a usable price in time, that price wins; otherwise the
+// best-ranked usable result seen by then is used, or failing that the next
+// usable result to arrive. The results seen are returned in order of
+// preference.
+func (c *Client) Preferred(ctx context.Context, coin, currency string, grace time.Duration) (Result, []Result) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	var seen []Result
+	best := Result{Source: "None"}
+	bestRank := len(c.Providers)
+	waiting := true
+	results := c.Stream(ctx, coin, currency)
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				c.sortByRank(seen)
+				return best, seen
+			}
+			seen = append(seen, r)
+			rank := c.rank(r.Source)
+			if r.Usable() && rank < bestRank {
+				best, bestRank = r, rank
+			}
+			if rank == 0 {
+				waiting = false
+			}
+		case <-timer.C:
+			waiting = false
+		}
+		if !waiting && best.Usable() {
+			c.sortByRank(seen)
+			return best, seen
+		}
+	}
+}
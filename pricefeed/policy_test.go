@@ -0,0 +1,148 @@
+package pricefeed_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"cli-crypto-price/pricefeed/pricefeedtest"
+)
+
+func TestFindQuorum(t *testing.T) {
+	prices := func(values ...float64) []pricefeed.Result {
+		results := make([]pricefeed.Result, len(values))
+		for i, v := range values {
+			results[i] = pricefeed.Result{Source: string(rune('a' + i)), Price: v}
+		}
+		return results
+	}
+	tests := []struct {
+		name      string
+		results   []pricefeed.Result
+		n         int
+		tolerance float64
+		ok        bool
+		price     float64
+		agreed    int
+	}{
+		{"all agree", prices(100, 100.5, 101), 2, 1, true, 100.5, 3},
+		{"outlier left out", prices(100, 130, 100.5), 2, 1, true, 100, 2},
+		{"largest group wins", prices(100, 100.2, 200, 200.5, 201), 2, 1, true, 200.5, 3},
+		{"too few agree", prices(100, 110, 120), 2, 1, false, 0, 0},
+		{"wider tolerance", prices(100, 110, 120), 2, 25, true, 110, 3},
+		{"n larger than results", prices(100), 2, 1, false, 0, 0},
+		{"n of zero", prices(100, 100), 0, 1, false, 0, 0},
+		{"negative tolerance", prices(100, 101), 2, -1, false, 0, 0},
+		{"negative tolerance, one needed", prices(100, 101), 1, -1, true, 100, 1},
+		{
+			name: "unusable results ignored",
+			results: []pricefeed.Result{
+				{Source: "a", Price: 100},
+				{Source: "b", Error: "rate limited", Kind: pricefeed.KindRateLimited},
+				{Source: "c", Price: 100, Stale: true},
+			},
+			n: 2, tolerance: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			best, agreed, ok := pricefeed.FindQuorum(tt.results, tt.n, tt.tolerance)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				if best.Source != "None" || agreed != nil {
+					t.Errorf("no quorum: got %+v and %v", best, agreed)
+				}
+				return
+			}
+			if best.Price != tt.price {
+				t.Errorf("price = %v, want %v", best.Price, tt.price)
+			}
+			if len(agreed) != tt.agreed {
+				t.Errorf("%d agreed, want %d", len(agreed), tt.agreed)
+			}
+		})
+	}
+}
+
+func TestQuorum(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]float64
+		failed    []string
+		n         int
+		price     float64
+	}{
+		{"all agree", nil, nil, 3, 67000},
+		{"outliers outvoted", map[string]float64{"kraken": 70000, "coinbase": 60000}, nil, 3, 67000},
+		{"failures ignored", nil, []string{"coingecko", "binance"}, 4, 67000},
+		{"no quorum", map[string]float64{"coingecko": 50000, "coinmarketcap": 60000, "cryptocompare": 70000}, nil, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := pricefeedtest.NewServer()
+			defer srv.Close()
+			for name, price := range tt.overrides {
+				srv.SetProviderPrice(name, "bitcoin", "usd", price)
+			}
+			for _, name := range tt.failed {
+				srv.Fail(name, pricefeedtest.ServerError)
+			}
+			client := pricefeed.NewClient(srv.Providers(srv.Client())...)
+			best, seen := client.Quorum(context.Background(), "bitcoin", "usd", tt.n, 1)
+			if best.Price != tt.price {
+				t.Errorf("price = %v (%s), want %v", best.Price, best.Source, tt.price)
+			}
+			if tt.price == 0 && (best.Source != "None" || len(seen) != len(pricefeedtest.Providers)) {
+				t.Errorf("no quorum: source = %q after %d results", best.Source, len(seen))
+			}
+		})
+	}
+}
+
+// slowProvider delays another provider's answers.
+type slowProvider struct {
+	pricefeed.Provider
+	delay time.Duration
+}
+
+func (p slowProvider) Fetch(ctx context.Context, coin, currency string) (pricefeed.Quote, error) {
+	select {
+	case <-time.After(p.delay):
+		return p.Provider.Fetch(ctx, coin, currency)
+	case <-ctx.Done():
+		return pricefeed.Quote{}, ctx.Err()
+	}
+}
+
+func TestPreferred(t *testing.T) {
+	tests := []struct {
+		name   string
+		delay  time.Duration
+		failed bool
+		grace  time.Duration
+		want   string
+	}{
+		{"preferred within grace", 20 * time.Millisecond, false, time.Second, "CoinGecko"},
+		{"preferred after grace", time.Second, false, 20 * time.Millisecond, "CoinMarketCap"},
+		{"preferred fails", 0, true, time.Second, "CoinMarketCap"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := pricefeedtest.NewServer()
+			defer srv.Close()
+			if tt.failed {
+				srv.Fail("coingecko", pricefeedtest.ServerError)
+			}
+			providers := srv.Providers(srv.Client())[:3]
+			providers[0] = slowProvider{providers[0], tt.delay}
+			client := pricefeed.NewClient(providers...)
+			best, _ := client.Preferred(context.Background(), "bitcoin", "usd", tt.grace)
+			if best.Source != tt.want {
+				t.Errorf("source = %q, want %q", best.Source, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+// Package pricefeed fetches cryptocurrency prices from public price APIs
+// and combines the answers of several providers:
+//
+//	client := pricefeed.NewClient(&pricefeed.CoinGecko{}, &pricefeed.CryptoCompare{})
+//	best, _ := client.ByPriority(ctx, "bitcoin", "usd")
+//	median := pricefeed.Aggregate(client.All(ctx, "bitcoin", "usd"), 5, false).Median
+package pricefeed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrRateLimited is returned, possibly wrapped, when a provider rejects a
+// request for exceeding its rate limit.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrNoAPIKey is returned by providers that need an API key when none is
+// set.
+var ErrNoAPIKey = errors.New("no API key configured")
+
+// ErrUnreachable is wrapped by errors from providers that could not be
+// reached or answered with a server error.
+var ErrUnreachable = errors.New("provider unreachable")
+
+// ErrNotFound is wrapped by errors from providers that do not know the coin
+// or have no price for it in the currency.
+var ErrNotFound = errors.New("coin not found")
+
+// ErrInvalidResponse is wrapped by errors from providers whose answer could
+// not be parsed.
+var ErrInvalidResponse = errors.New("invalid response")
+
+// ErrorKind classifies why a provider returned no price.
+type ErrorKind string
+
+const (
+	KindUnreachable     ErrorKind = "unreachable"
+	KindNotFound        ErrorKind = "not_found"
+	KindInvalidResponse ErrorKind = "invalid_response"
+	KindRateLimited     ErrorKind = "rate_limited"
+	KindNoAPIKey        ErrorKind = "no_api_key"
+	KindOther           ErrorKind = "error"
+)
+
+// Classify returns the kind of a Fetch error, or "" for nil.
+func Classify(err error) ErrorKind {
+	var status *StatusError
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrRateLimited):
+		return KindRateLimited
+	case errors.Is(err, ErrNoAPIKey):
+		return KindNoAPIKey
+	case errors.Is(err, ErrNotFound):
+		return KindNotFound
+	case errors.Is(err, ErrInvalidResponse):
+		return KindInvalidResponse
+	case errors.Is(err, ErrUnreachable), errors.Is(err, context.DeadlineExceeded):
+		return KindUnreachable
+	case errors.As(err, &status) && status.Code >= 500:
+		return KindUnreachable
+	}
+	return KindOther
+}
+
+// kindError carries its own message while matching its kind with
+// errors.Is.
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+func (e *kindError) Unwrap() error { return e.kind }
+
+// StatusError is returned when a provider answers with an HTTP status
+// other than 200 OK.
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string { return e.Status }
+
+// Quote is a single provider's price for a coin.
+type Quote struct {
+	Coin      string
+	Currency  string
+	Price     float64
+	Source    string
+	Volume    float64
+	Timestamp time.Time
+}
+
+// Provider is a price source. Fetch returns the coin's price in the
+// currency, both given as lowercase IDs such as "bitcoin" and "usd".
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context, coin, currency string) (Quote, error)
+}
+
+// BatchProvider is a Provider that can price many coins in many currencies
+// with one request. FetchBatch leaves out the pairs it has no price for.
+type BatchProvider interface {
+	Provider
+	FetchBatch(ctx context.Context, coins, currencies []string) ([]Quote, error)
+}
+
+// Result is one provider's answer to a Client request: its quote, or the
+// error that kept it from returning one.
+type Result struct {
+	Price     float64
+	Source    string
+	Duration  time.Duration
+	Volume    float64
+	Timestamp time.Time
+	Stale     bool
+
+	// Error is set, and Kind says why, when the provider returned no
+	// price.
+	Error string
+	Kind  ErrorKind
+}
+
+// RateLimited reports whether the provider refused the request for
+// exceeding its rate limit.
+func (r Result) RateLimited() bool { return r.Kind == KindRateLimited }
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	var timestamp *time.Time
+	if !r.Timestamp.IsZero() {
+		t := r.Timestamp.UTC()
+		timestamp = &t
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		Price       float64    `json:"price"`
+		Source      string     `json:"source"`
+		Duration    float64    `json:"duration_ms"`
+		Volume      float64    `json:"volume,omitempty"`
+		Timestamp   *time.Time `json:"timestamp,omitempty"`
+		Stale       bool       `json:"stale,omitempty"`
+		Error       string     `json:"error,omitempty"`
+		Kind        ErrorKind  `json:"error_kind,omitempty"`
+		RateLimited bool       `json:"rate_limited,omitempty"`
+	}{r.Price, r.Source, float64(r.Duration.Microseconds()) / 1000, r.Volume, timestamp, r.Stale, r.Error, r.Kind, r.RateLimited()})
+	return bytes.TrimRight(buf.Bytes(), "\n"), err
+}
+
+// Usable reports whether the result carries a price that is not stale.
+func (r Result) Usable() bool {
+	return r.Error == "" && r.Price > 0 && !r.Stale
+}
+
+// Age is how long ago the provider last updated the price, or zero when it
+// does not say.
+func (r Result) Age() time.Duration {
+	if r.Timestamp.IsZero() {
+		return 0
+	}
+	return time.Since(r.Timestamp).Truncate(time.Second)
+}
+
+func unixTime(sec int64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// getJSON decodes the response to a GET request into v, sending key in
+// header when both are set.
+func getJSON(ctx context.Context, client *http.Client, url, header, key string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if header != "" && key != "" {
+		req.Header.Set(header, key)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrRateLimited):
+			// The client's transport is holding off the provider; drop
+			// the *url.Error around its reason.
+			return errors.Unwrap(err)
+		}
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{resp.StatusCode, resp.Status}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return nil
+}
+
+func noPrice(coin, currency string) error {
+	return &kindError{ErrNotFound, fmt.Sprintf("no %s price for %s", currency, coin)}
+}
+
+func noMarket(pair, coin string) error {
+	return &kindError{ErrNotFound, fmt.Sprintf("no %s market for %s", pair, coin)}
+}
+
+func invalidPrice(value string) error {
+	return &kindError{ErrInvalidResponse, fmt.Sprintf("invalid response: price %q", value)}
+}
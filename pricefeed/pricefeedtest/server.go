@@ -0,0 +1,366 @@
+// Package pricefeedtest serves fake versions of the price APIs pricefeed
+// talks to, so providers, clients and aggregation can be exercised without
+// the network:
+//
+//	srv := pricefeedtest.NewServer()
+//	defer srv.Close()
+//	srv.Fail("kraken", pricefeedtest.RateLimited)
+//	client := pricefeed.NewClient(srv.Providers(srv.Client())...)
+//
+// Each provider's API is served under its own path, /<provider>, which is
+// the provider's base URL.
+package pricefeedtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+// Providers are the names the server serves APIs for, as used in paths.
+var Providers = []string{"coingecko", "coinmarketcap", "cryptocompare", "binance", "kraken", "coinbase"}
+
+// Failure is how a provider answers instead of with a price.
+type Failure int
+
+const (
+	// OK answers normally.
+	OK Failure = iota
+	// RateLimited answers 429 Too Many Requests with a Retry-After of one
+	// second.
+	RateLimited
+	// ServerError answers 500 Internal Server Error.
+	ServerError
+	// Malformed answers 200 with a body that is not valid JSON.
+	Malformed
+	// NotFound answers the way the provider does for a coin it does not
+	// know.
+	NotFound
+)
+
+var failureNames = map[string]Failure{
+	"ok":           OK,
+	"rate-limited": RateLimited,
+	"429":          RateLimited,
+	"server-error": ServerError,
+	"500":          ServerError,
+	"malformed":    Malformed,
+	"not-found":    NotFound,
+	"404":          NotFound,
+}
+
+// ParseFailure parses a failure name: ok, rate-limited (429),
+// server-error (500), malformed or not-found (404).
+func ParseFailure(s string) (Failure, error) {
+	if f, ok := failureNames[strings.ToLower(s)]; ok {
+		return f, nil
+	}
+	return OK, fmt.Errorf("unknown failure %q (expected ok, rate-limited, server-error, malformed or not-found)", s)
+}
+
+type coin struct {
+	id     string
+	symbol string
+}
+
+// Handler answers price requests for every provider in Providers from the
+// prices it has been given. It is safe for concurrent use.
+type Handler struct {
+	mu        sync.Mutex
+	coins     []coin
+	prices    map[string]map[string]float64
+	overrides map[string]map[string]map[string]float64
+	failures  map[string]Failure
+	requests  map[string]int
+	updated   time.Time
+}
+
+// NewHandler returns a handler that knows Bitcoin and Ethereum in USD and
+// EUR.
+func NewHandler() *Handler {
+	h := &Handler{
+		prices:    make(map[string]map[string]float64),
+		overrides: make(map[string]map[string]map[string]float64),
+		failures:  make(map[string]Failure),
+		requests:  make(map[string]int),
+		updated:   time.Now().Add(-30 * time.Second).Truncate(time.Second),
+	}
+	h.SetPrice("bitcoin", "BTC", "usd", 67000)
+	h.SetPrice("bitcoin", "BTC", "eur", 62000)
+	h.SetPrice("ethereum", "ETH", "usd", 3500)
+	h.SetPrice("ethereum", "ETH", "eur", 3240)
+	return h
+}
+
+// SetPrice sets the price every provider quotes for the coin, which the
+// exchanges know by symbol.
+func (h *Handler) SetPrice(id, symbol, currency string, price float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.prices[id] == nil {
+		h.coins = append(h.coins, coin{id, strings.ToUpper(symbol)})
+		h.prices[id] = make(map[string]float64)
+	}
+	h.prices[id][strings.ToLower(currency)] = price
+}
+
+// SetProviderPrice makes one provider quote a different price for a coin
+// the handler already knows, e.g. to test how outliers are aggregated.
+func (h *Handler) SetProviderPrice(provider, id, currency string, price float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.overrides[provider] == nil {
+		h.overrides[provider] = make(map[string]map[string]float64)
+	}
+	if h.overrides[provider][id] == nil {
+		h.overrides[provider][id] = make(map[string]float64)
+	}
+	h.overrides[provider][id][strings.ToLower(currency)] = price
+}
+
+// Fail makes the provider answer every request with f until it is set
+// back to OK.
+func (h *Handler) Fail(provider string, f Failure) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[provider] = f
+}
+
+// Requests is how many requests the provider has been sent.
+func (h *Handler) Requests(provider string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.requests[provider]
+}
+
+func (h *Handler) price(provider, id, currency string) (float64, bool) {
+	if p, ok := h.overrides[provider][id][currency]; ok {
+		return p, true
+	}
+	p, ok := h.prices[id][currency]
+	return p, ok
+}
+
+func (h *Handler) bySymbol(symbol string) (string, bool) {
+	for _, c := range h.coins {
+		if c.symbol == symbol {
+			return c.id, true
+		}
+	}
+	return "", false
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	provider, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests[provider]++
+
+	switch h.failures[provider] {
+	case RateLimited:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+		return
+	case ServerError:
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	case Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"price": `)
+		return
+	}
+	notFound := h.failures[provider] == NotFound
+
+	q := r.URL.Query()
+	switch provider + " /" + path {
+	case "coingecko /coins/list":
+		h.coingeckoList(w)
+	case "coingecko /simple/price":
+		h.coingecko(w, strings.Split(q.Get("ids"), ","), strings.Split(q.Get("vs_currencies"), ","), notFound)
+	case "coinmarketcap /v2/cryptocurrency/quotes/latest":
+		h.coinmarketcap(w, r, q.Get("slug"), strings.ToLower(q.Get("convert")), notFound)
+	case "cryptocompare /data/price":
+		h.cryptocompare(w, q.Get("fsym"), strings.ToLower(q.Get("tsyms")), notFound)
+	case "binance /api/v3/ticker/price":
+		h.binance(w, q.Get("symbol"), notFound)
+	case "kraken /0/public/Ticker":
+		h.kraken(w, q.Get("pair"), notFound)
+	default:
+		if provider == "coinbase" && strings.HasPrefix(path, "v2/prices/") && strings.HasSuffix(path, "/spot") {
+			h.coinbase(w, strings.TrimSuffix(strings.TrimPrefix(path, "v2/prices/"), "/spot"), notFound)
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) coingecko(w http.ResponseWriter, ids, currencies []string, notFound bool) {
+	out := make(map[string]map[string]float64)
+	for _, id := range ids {
+		if notFound {
+			break
+		}
+		fields := make(map[string]float64)
+		for _, cur := range currencies {
+			if p, ok := h.price("coingecko", id, cur); ok {
+				fields[cur] = p
+				fields[cur+"_24h_vol"] = 1e9
+			}
+		}
+		if len(fields) > 0 {
+			fields["last_updated_at"] = float64(h.updated.Unix())
+			out[id] = fields
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// coingeckoList lets clients map coin IDs to the symbols the exchanges
+// are asked for.
+func (h *Handler) coingeckoList(w http.ResponseWriter) {
+	list := make([]map[string]string, 0, len(h.coins))
+	for _, c := range h.coins {
+		list = append(list, map[string]string{"id": c.id, "symbol": strings.ToLower(c.symbol), "name": c.id})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *Handler) coinmarketcap(w http.ResponseWriter, r *http.Request, slug, currency string, notFound bool) {
+	if r.Header.Get("X-CMC_PRO_API_KEY") == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"status": map[string]interface{}{"error_code": 1002, "error_message": "API key missing."}})
+		return
+	}
+	p, ok := h.price("coinmarketcap", slug, currency)
+	if !ok || notFound {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"status": map[string]interface{}{"error_code": 400, "error_message": `Invalid value for "slug"`}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": map[string]interface{}{"error_code": 0},
+		"data": map[string]interface{}{"1": map[string]interface{}{
+			"slug": slug,
+			"quote": map[string]interface{}{strings.ToUpper(currency): map[string]interface{}{
+				"price": p, "volume_24h": 5e8, "last_updated": h.updated.UTC().Format(time.RFC3339),
+			}},
+		}},
+	})
+}
+
+// cryptocompare is asked by coin ID and answers {"Response": "Error"} for
+// coins it does not know.
+func (h *Handler) cryptocompare(w http.ResponseWriter, id, currency string, notFound bool) {
+	p, ok := h.price("cryptocompare", id, currency)
+	if !ok || notFound {
+		writeJSON(w, http.StatusOK, map[string]string{"Response": "Error", "Message": "There is no data for the symbol"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]float64{strings.ToUpper(currency): p})
+}
+
+// market resolves an exchange pair such as BTCUSDT or XBTUSD to a coin and
+// currency; quotes lists the currency codes the exchange uses.
+func (h *Handler) market(provider, pair string, quotes map[string]string, base func(string) string) (float64, bool) {
+	for code, currency := range quotes {
+		symbol, ok := strings.CutSuffix(pair, code)
+		if !ok {
+			continue
+		}
+		if id, ok := h.bySymbol(base(symbol)); ok {
+			return h.price(provider, id, currency)
+		}
+	}
+	return 0, false
+}
+
+func identity(s string) string { return s }
+
+func (h *Handler) binance(w http.ResponseWriter, pair string, notFound bool) {
+	p, ok := h.market("binance", pair, map[string]string{"USDT": "usd", "EUR": "eur"}, identity)
+	if !ok || notFound {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"code": -1121, "msg": "Invalid symbol."})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"symbol": pair, "price": fmt.Sprintf("%.8f", p)})
+}
+
+func (h *Handler) kraken(w http.ResponseWriter, pair string, notFound bool) {
+	fromKraken := func(s string) string {
+		switch s {
+		case "XBT":
+			return "BTC"
+		case "XDG":
+			return "DOGE"
+		}
+		return s
+	}
+	p, ok := h.market("kraken", pair, map[string]string{"USD": "usd", "EUR": "eur"}, fromKraken)
+	if !ok || notFound {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": []string{"EQuery:Unknown asset pair"}, "result": map[string]interface{}{}})
+		return
+	}
+	price := fmt.Sprintf("%.5f", p)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"error": []string{},
+		"result": map[string]interface{}{"X" + pair: map[string][]string{
+			"c": {price, "0.01"},
+			"v": {"100", "2500"},
+			"p": {price, price},
+		}},
+	})
+}
+
+func (h *Handler) coinbase(w http.ResponseWriter, pair string, notFound bool) {
+	symbol, currency, _ := strings.Cut(pair, "-")
+	var p float64
+	id, ok := h.bySymbol(symbol)
+	if ok {
+		p, ok = h.price("coinbase", id, strings.ToLower(currency))
+	}
+	if !ok || notFound {
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{"errors": []map[string]string{{"id": "not_found", "message": "Invalid base currency"}}})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]string{"amount": fmt.Sprintf("%.2f", p), "base": symbol, "currency": currency}})
+}
+
+// Server is a Handler listening on a loopback port.
+type Server struct {
+	*httptest.Server
+	*Handler
+}
+
+// NewServer starts a server with NewHandler's prices. Close it when done.
+func NewServer() *Server {
+	h := NewHandler()
+	return &Server{httptest.NewServer(h), h}
+}
+
+// BaseURL is the base URL to give the provider's pricefeed client.
+func (s *Server) BaseURL(provider string) string {
+	return s.URL + "/" + provider
+}
+
+// Providers returns a client for every provider in Providers, in that
+// order, all pointed at the server and sending their requests with client.
+func (s *Server) Providers(client *http.Client) []pricefeed.Provider {
+	return []pricefeed.Provider{
+		&pricefeed.CoinGecko{BaseURL: s.BaseURL("coingecko"), HTTPClient: client},
+		&pricefeed.CoinMarketCap{BaseURL: s.BaseURL("coinmarketcap"), APIKey: "test", HTTPClient: client},
+		&pricefeed.CryptoCompare{BaseURL: s.BaseURL("cryptocompare"), HTTPClient: client},
+		&pricefeed.Binance{BaseURL: s.BaseURL("binance"), HTTPClient: client},
+		&pricefeed.Kraken{BaseURL: s.BaseURL("kraken"), HTTPClient: client},
+		&pricefeed.Coinbase{BaseURL: s.BaseURL("coinbase"), HTTPClient: client},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
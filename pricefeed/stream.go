@@ -0,0 +1,240 @@
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	BinanceStreamURL  = "wss://stream.binance.com:9443"
+	CoinbaseStreamURL = "wss://ws-feed.exchange.coinbase.com"
+)
+
+// Tick is a real-time price update from a streaming feed.
+type Tick struct {
+	Coin     string    `json:"coin"`
+	Currency string    `json:"currency"`
+	Price    float64   `json:"price"`
+	Volume   float64   `json:"volume,omitempty"`
+	Source   string    `json:"source"`
+	Time     time.Time `json:"time"`
+}
+
+// Streamer pushes ticks for the coins over a single connection until ctx is
+// canceled or the connection fails. It returns nil only when ctx is done;
+// reconnecting is up to the caller.
+type Streamer interface {
+	Name() string
+	Stream(ctx context.Context, coins []string, currency string, ticks chan<- Tick) error
+}
+
+// ErrStreamClosed is returned when the feed closes the connection.
+var ErrStreamClosed = errors.New("stream closed by the server")
+
+func tickerSymbol(coin string, symbol func(string) string) string {
+	if symbol == nil {
+		symbol = commonSymbol
+	}
+	return strings.ToUpper(symbol(coin))
+}
+
+// readLoop reads JSON messages until ctx is done or the connection fails,
+// handing each one to handle.
+func readLoop(ctx context.Context, conn *websocket.Conn, handle func([]byte) error) error {
+	for {
+		_, data, err := conn.Read(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if websocket.CloseStatus(err) != -1 {
+			return ErrStreamClosed
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrUnreachable, err)
+		}
+		if err := handle(data); err != nil {
+			return err
+		}
+	}
+}
+
+func dial(ctx context.Context, client *http.Client, url string) (*websocket.Conn, error) {
+	conn, _, err := websocket.Dial(ctx, url, &websocket.DialOptions{HTTPClient: client})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	conn.SetReadLimit(1 << 20)
+	return conn, nil
+}
+
+type binanceMiniTicker struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		EventTime int64  `json:"E"`
+		Symbol    string `json:"s"`
+		Close     string `json:"c"`
+		Volume    string `json:"q"`
+	} `json:"data"`
+}
+
+// BinanceStream streams Binance's per-symbol mini tickers, about one
+// update a second per coin. USD is streamed from the USDT market, as with
+// Binance. An empty URL uses BinanceStreamURL and a nil HTTPClient uses
+// http.DefaultClient for the handshake.
+type BinanceStream struct {
+	URL        string
+	Symbol     func(coin string) string
+	HTTPClient *http.Client
+}
+
+func (s *BinanceStream) Name() string { return "Binance" }
+
+func (s *BinanceStream) Stream(ctx context.Context, coins []string, currency string, ticks chan<- Tick) error {
+	base := s.URL
+	if base == "" {
+		base = BinanceStreamURL
+	}
+	quote := binanceQuoteAsset(currency)
+	pairs := make(map[string]string, len(coins))
+	streams := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		pair := tickerSymbol(coin, s.Symbol) + quote
+		pairs[pair] = coin
+		streams = append(streams, strings.ToLower(pair)+"@miniTicker")
+	}
+
+	conn, err := dial(ctx, s.HTTPClient, base+"/stream?streams="+strings.Join(streams, "/"))
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+
+	return readLoop(ctx, conn, func(data []byte) error {
+		var msg binanceMiniTicker
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		coin, ok := pairs[msg.Data.Symbol]
+		if !ok {
+			return nil
+		}
+		price, err := strconv.ParseFloat(msg.Data.Close, 64)
+		if err != nil {
+			return invalidPrice(msg.Data.Close)
+		}
+		volume, _ := strconv.ParseFloat(msg.Data.Volume, 64)
+		return send(ctx, ticks, Tick{
+			Coin:     coin,
+			Currency: currency,
+			Price:    price,
+			Volume:   volume,
+			Source:   s.Name(),
+			Time:     time.UnixMilli(msg.Data.EventTime),
+		})
+	})
+}
+
+type coinbaseMessage struct {
+	Type      string    `json:"type"`
+	ProductID string    `json:"product_id"`
+	Price     string    `json:"price"`
+	Volume    string    `json:"volume_24h"`
+	Time      time.Time `json:"time"`
+	Message   string    `json:"message"`
+	Reason    string    `json:"reason"`
+}
+
+// CoinbaseStream streams the Coinbase Exchange ticker channel, which sends
+// an update for every trade. An empty URL uses CoinbaseStreamURL and a nil
+// HTTPClient uses http.DefaultClient for the handshake.
+type CoinbaseStream struct {
+	URL        string
+	Symbol     func(coin string) string
+	HTTPClient *http.Client
+}
+
+func (s *CoinbaseStream) Name() string { return "Coinbase" }
+
+func (s *CoinbaseStream) Stream(ctx context.Context, coins []string, currency string, ticks chan<- Tick) error {
+	url := s.URL
+	if url == "" {
+		url = CoinbaseStreamURL
+	}
+	products := make(map[string]string, len(coins))
+	ids := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		product := tickerSymbol(coin, s.Symbol) + "-" + strings.ToUpper(currency)
+		products[product] = coin
+		ids = append(ids, product)
+	}
+
+	conn, err := dial(ctx, s.HTTPClient, url)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+	subscribe, _ := json.Marshal(map[string]interface{}{
+		"type":        "subscribe",
+		"product_ids": ids,
+		"channels":    []string{"ticker"},
+	})
+	if err := conn.Write(ctx, websocket.MessageText, subscribe); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+
+	return readLoop(ctx, conn, func(data []byte) error {
+		var msg coinbaseMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+		switch msg.Type {
+		case "error":
+			return fmt.Errorf("coinbase: %s %s", msg.Message, msg.Reason)
+		case "ticker":
+		default:
+			return nil
+		}
+		coin, ok := products[msg.ProductID]
+		if !ok {
+			return nil
+		}
+		price, err := strconv.ParseFloat(msg.Price, 64)
+		if err != nil {
+			return invalidPrice(msg.Price)
+		}
+		// volume_24h is in coins; Volume is in the quote currency.
+		volume, _ := strconv.ParseFloat(msg.Volume, 64)
+		volume *= price
+		if msg.Time.IsZero() {
+			msg.Time = time.Now()
+		}
+		return send(ctx, ticks, Tick{
+			Coin:     coin,
+			Currency: currency,
+			Price:    price,
+			Volume:   volume,
+			Source:   s.Name(),
+			Time:     msg.Time,
+		})
+	})
+}
+
+func send(ctx context.Context, ticks chan<- Tick, t Tick) error {
+	select {
+	case ticks <- t:
+		return nil
+	case <-ctx.Done():
+		return nil
+	}
+}
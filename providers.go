@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+var benchmarkRuns int
+
+// fetchFrom queries a single provider synchronously.
+func fetchFrom(ctx context.Context, p provider, crypto, currency string) pricefeed.Result {
+	client := pricefeed.NewClient(feedProvider(p))
+	return client.All(ctx, crypto, currency)[0]
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
+
+type ProviderBenchmark struct {
+	Provider    string        `json:"provider"`
+	Runs        int           `json:"runs"`
+	Successes   int           `json:"successes"`
+	Errors      int           `json:"errors"`
+	SuccessRate float64       `json:"success_rate"`
+	P50         time.Duration `json:"p50_ns"`
+	P90         time.Duration `json:"p90_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	P99         time.Duration `json:"p99_ns"`
+	Price       float64       `json:"price,omitempty"`
+	Deviation   float64       `json:"deviation_pct"`
+	LastError   string        `json:"last_error,omitempty"`
+
+	name      string
+	latencies []time.Duration
+}
+
+func benchmarkProviders(ctx context.Context, crypto string, runs int) []ProviderBenchmark {
+	active := enabledProviders()
+	results := make([]ProviderBenchmark, len(active))
+	var wg sync.WaitGroup
+	for i, p := range active {
+		wg.Add(1)
+		go func(b *ProviderBenchmark, p provider) {
+			defer wg.Done()
+			b.name, b.Provider, b.Runs = p.name, p.label, runs
+			var prices []float64
+			for n := 0; n < runs; n++ {
+				r := fetchFrom(ctx, p, crypto, "usd")
+				if !r.Usable() {
+					b.Errors++
+					b.LastError = r.Error
+					continue
+				}
+				b.Successes++
+				b.latencies = append(b.latencies, r.Duration)
+				prices = append(prices, r.Price)
+			}
+			sort.Slice(b.latencies, func(i, j int) bool { return b.latencies[i] < b.latencies[j] })
+			b.SuccessRate = float64(b.Successes) / float64(runs) * 100
+			b.P50 = percentile(b.latencies, 50)
+			b.P90 = percentile(b.latencies, 90)
+			b.P95 = percentile(b.latencies, 95)
+			b.P99 = percentile(b.latencies, 99)
+			b.Price = pricefeed.Median(prices)
+		}(&results[i], p)
+	}
+	wg.Wait()
+
+	var prices []float64
+	for _, b := range results {
+		if b.Price > 0 {
+			prices = append(prices, b.Price)
+		}
+	}
+	if reference := pricefeed.Median(prices); reference > 0 {
+		for i := range results {
+			if results[i].Price > 0 {
+				results[i].Deviation = pricefeed.Deviation(results[i].Price, reference)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].SuccessRate != results[j].SuccessRate {
+			return results[i].SuccessRate > results[j].SuccessRate
+		}
+		return results[i].P50 < results[j].P50
+	})
+	return results
+}
+
+// statusProbes are cheap requests used to check each provider's
+// reachability and key.
+var statusProbes = map[string]string{
+	"coingecko":     "/simple/price?ids=bitcoin&vs_currencies=usd",
+	"coinmarketcap": "/v2/cryptocurrency/quotes/latest?slug=bitcoin&convert=USD",
+	"cryptocompare": "/data/price?fsym=BTC&tsyms=USD",
+	"binance":       "/api/v3/ticker/price?symbol=BTCUSDT",
+	"kraken":        "/0/public/Ticker?pair=XBTUSD",
+	"coinbase":      "/v2/prices/BTC-USD/spot",
+}
+
+type ProviderStatus struct {
+	Provider  string        `json:"provider"`
+	Enabled   bool          `json:"enabled"`
+	Reachable bool          `json:"reachable"`
+	Latency   time.Duration `json:"latency_ns,omitempty"`
+	Auth      string        `json:"auth"`
+	RateLimit string        `json:"rate_limit,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+}
+
+// rateLimitHeadroom reads the remaining request quota from the common
+// X-RateLimit-Remaining style headers, if the provider sends any.
+func rateLimitHeadroom(h http.Header) string {
+	for name, values := range h {
+		lower := strings.ToLower(name)
+		if strings.Contains(lower, "ratelimit-remaining") || strings.Contains(lower, "rate-limit-remaining") {
+			return values[0] + " remaining"
+		}
+	}
+	return ""
+}
+
+func checkProvider(ctx context.Context, p provider) ProviderStatus {
+	settings := settingsFor(p.name)
+	st := ProviderStatus{Provider: p.label, Enabled: settings.enabled(), Auth: "no key"}
+	hasKey := providerKey(p.name) != ""
+	if hasKey {
+		st.Auth = "key present"
+	}
+	if _, ok := pluginPaths[p.name]; ok {
+		return checkPlugin(ctx, p, st)
+	}
+	if settings.RateLimit == 0 {
+		settings.RateLimit = defaultRateLimit(p.name)
+	}
+	if settings.RateLimit > 0 {
+		st.RateLimit = fmt.Sprintf("%d/min", settings.RateLimit)
+	}
+
+	start := time.Now()
+	resp, err := httpGet(ctx, providerURL(p.name)+statusProbes[p.name], p.name)
+	st.Latency = time.Since(start)
+	if err != nil {
+		st.LastError = err.Error()
+		return st
+	}
+	resp.Body.Close()
+	st.Reachable = true
+	if headroom := rateLimitHeadroom(resp.Header); headroom != "" {
+		st.RateLimit = strings.TrimSpace(headroom + " " + st.RateLimit)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		st.Auth = "key rejected"
+		if !hasKey {
+			st.Auth = "key required"
+		}
+		st.LastError = resp.Status
+	case resp.StatusCode == http.StatusTooManyRequests:
+		st.LastError = "rate limited"
+	case resp.StatusCode != http.StatusOK:
+		st.LastError = resp.Status
+	case hasKey:
+		st.Auth = "key valid"
+	}
+	return st
+}
+
+// checkPlugin runs a plugin for bitcoin in usd, as there is no URL to probe.
+func checkPlugin(ctx context.Context, p provider, st ProviderStatus) ProviderStatus {
+	r := fetchFrom(ctx, p, "bitcoin", "usd")
+	st.Latency = r.Duration
+	switch r.Kind {
+	case "":
+		st.Reachable = true
+		if st.Auth == "key present" {
+			st.Auth = "key valid"
+		}
+	case pricefeed.KindNoAPIKey:
+		st.Reachable, st.Auth, st.LastError = true, "key required", r.Error
+	case pricefeed.KindRateLimited, pricefeed.KindNotFound:
+		st.Reachable, st.LastError = true, r.Error
+	default:
+		st.LastError = r.Error
+	}
+	return st
+}
+
+func providerStatuses(ctx context.Context) []ProviderStatus {
+	statuses := make([]ProviderStatus, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p provider) {
+			defer wg.Done()
+			statuses[i] = checkProvider(ctx, p)
+		}(i, p)
+	}
+	wg.Wait()
+	return statuses
+}
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "Inspect and compare price providers",
+	Long: `Inspect and compare price providers.
+
+Any executable in the plugins directory shown by "crypto-cli paths" is used
+as another provider, named after the file without its extension. It is
+run as "<plugin> <coin> <currency>", e.g. "bitstamp bitcoin usd", and
+prints a JSON object on stdout:
+
+  {"price": 67012.5, "volume": 1234.5, "timestamp": 1718000000}
+
+Only price is required; timestamp is in Unix seconds. On failure it prints
+{"error": "no such market", "error_kind": "not_found"}, with error_kind one
+of not_found, rate_limited, unreachable or no_api_key, or exits non-zero
+with a message on stderr. The key set with "crypto-cli keys set <name>" or
+providers.<name>.key is passed in CRYPTO_CLI_API_KEY, and the time left to
+answer in CRYPTO_CLI_TIMEOUT.
+
+Plugins take part in --providers, --priority and the providers.<name>
+settings like the built-in providers.`,
+}
+
+var providersBenchmarkCmd = &cobra.Command{
+	Use:   "benchmark <coin>",
+	Short: "Query every provider repeatedly and report latency, success rate and price deviation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBenchmark(cmd.Context(), args[0], benchmarkRuns)
+	},
+}
+
+var providersStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show each provider's reachability, key state, rate-limit headroom and last error",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statuses := providerStatuses(cmd.Context())
+		if outputFormat == "json" {
+			return printJSON(statuses)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROVIDER\tENABLED\tREACHABLE\tLATENCY\tAUTH\tRATE LIMIT\tLAST ERROR")
+		for _, st := range statuses {
+			enabled, reachable, latency := "yes", "no", "-"
+			if !st.Enabled {
+				enabled = "no"
+			}
+			if st.Reachable {
+				reachable, latency = "yes", st.Latency.Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", st.Provider, enabled, reachable, latency, st.Auth, dashIfEmpty(st.RateLimit), dashIfEmpty(st.LastError))
+		}
+		return w.Flush()
+	},
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	providersCmd.AddCommand(providersStatusCmd)
+	providersBenchmarkCmd.Flags().IntVar(&benchmarkRuns, "runs", 5, "number of requests per provider")
+	providersBenchmarkCmd.Flags().BoolVar(&benchmarkSave, "save", false, "write the suggested priority order to the config file")
+	providersCmd.AddCommand(providersBenchmarkCmd)
+	rootCmd.AddCommand(providersCmd)
+}
@@ -0,0 +1,634 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+type CoinQuote struct {
+	Coin      string
+	Price     float64
+	Currency  string
+	Source    string
+	Duration  time.Duration
+	Timestamp time.Time
+	Fetched   time.Time
+	Changes   map[string]float64
+	Compare   *Comparison
+	Amount    float64
+	Aggregate *AggregateResult
+	Providers []pricefeed.Result
+	Reason    string
+	Error     string
+
+	results []pricefeed.Result
+	err     error
+}
+
+// MarshalJSON reports the duration in milliseconds and leaves out the
+// timestamp when the provider did not send one.
+func (q CoinQuote) MarshalJSON() ([]byte, error) {
+	return marshalJSON(struct {
+		Coin      string             `json:"coin"`
+		Price     float64            `json:"price,omitempty"`
+		Currency  string             `json:"currency"`
+		Source    string             `json:"source,omitempty"`
+		Duration  float64            `json:"duration_ms,omitempty"`
+		Timestamp *time.Time         `json:"timestamp,omitempty"`
+		Fetched   *time.Time         `json:"fetched_at,omitempty"`
+		Changes   map[string]float64 `json:"change_pct,omitempty"`
+		Compare   *Comparison        `json:"compare,omitempty"`
+		Amount    float64            `json:"amount,omitempty"`
+		Value     float64            `json:"value,omitempty"`
+		Aggregate *AggregateResult   `json:"aggregate,omitempty"`
+		Providers []pricefeed.Result `json:"providers,omitempty"`
+		Reason    string             `json:"reason,omitempty"`
+		Error     string             `json:"error,omitempty"`
+	}{q.Coin, q.Price, q.Currency, q.Source, milliseconds(q.Duration), timestampOrNil(q.Timestamp), fetchedOrNil(q.Fetched), q.Changes, q.Compare, q.Amount, q.Value(), q.Aggregate, q.Providers, q.Reason, q.Error})
+}
+
+// Value is what the input amount is worth at the quoted price, or zero
+// without an amount or a price.
+func (q CoinQuote) Value() float64 {
+	if q.err != nil {
+		return 0
+	}
+	return q.Amount * q.Price
+}
+
+func hasAmounts(quotes []CoinQuote) bool {
+	for _, q := range quotes {
+		if q.Amount != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func fetchedOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = machineTime(t).Truncate(time.Millisecond)
+	return &t
+}
+
+func timestampOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+// writeQuotesCSV writes one row per quote, preceded by a header row when
+// header is set.
+func writeQuotesCSV(out io.Writer, quotes []CoinQuote, header bool) error {
+	w := csv.NewWriter(out)
+	amounts := hasAmounts(quotes)
+	if header {
+		row := []string{"coin", "price", "currency", "source", "duration_ms", "timestamp", "fetched_at", "error"}
+		for _, p := range changePeriods {
+			row = append(row, "change_"+p+"_pct")
+		}
+		if comparing() {
+			row = append(row, "baseline", "diff", "diff_pct")
+		}
+		if amounts {
+			row = append(row, "amount", "value")
+		}
+		w.Write(row)
+	}
+	for _, q := range quotes {
+		var price, timestamp, fetched, duration string
+		if q.err == nil {
+			price = strconv.FormatFloat(q.Price, 'f', -1, 64)
+			duration = strconv.FormatFloat(milliseconds(q.Duration), 'f', 3, 64)
+		}
+		if !q.Timestamp.IsZero() {
+			timestamp = q.Timestamp.UTC().Format(time.RFC3339)
+		}
+		if !q.Fetched.IsZero() {
+			fetched = machineTime(q.Fetched).Format(time.RFC3339)
+		}
+		row := []string{q.Coin, price, q.Currency, q.Source, duration, timestamp, fetched, q.Error}
+		for _, p := range changePeriods {
+			var change string
+			if v, ok := q.Changes[p]; ok {
+				change = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+			row = append(row, change)
+		}
+		if comparing() {
+			var baseline, diff, diffPct string
+			if q.Compare != nil {
+				baseline = strconv.FormatFloat(q.Compare.Baseline, 'f', -1, 64)
+				diff = strconv.FormatFloat(q.Compare.Diff, 'f', -1, 64)
+				diffPct = strconv.FormatFloat(q.Compare.DiffPct, 'f', 4, 64)
+			}
+			row = append(row, baseline, diff, diffPct)
+		}
+		if amounts {
+			var amount, value string
+			if q.Amount != 0 {
+				amount = strconv.FormatFloat(q.Amount, 'f', -1, 64)
+			}
+			if q.Amount != 0 && q.err == nil {
+				value = strconv.FormatFloat(q.Value(), 'f', -1, 64)
+			}
+			row = append(row, amount, value)
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func validateAggregateMode() error {
+	switch aggregateMode {
+	case "first", "priority", "mean", "median", "vwap", "all":
+		return nil
+	}
+	return fmt.Errorf("unknown aggregate mode %q (expected priority, first, mean, median, vwap or all)", aggregateMode)
+}
+
+// quoteCoins resolves every coin up front, since resolution may prompt,
+// and then fetches each coin in each currency, keeping the input order.
+// Providers that batch are asked once for all the coins; the rest are asked
+// per coin by --concurrency workers. Arguments that resolve to the same
+// coin, such as "btc" and "bitcoin", are quoted once, with their input
+// amounts added up.
+func quoteCoins(ctx context.Context, coins, currencies []string) []CoinQuote {
+	quotes := make([]CoinQuote, 0, len(coins)*len(currencies))
+	seen := make(map[string]bool)
+	seenArg := make(map[string]bool)
+	amounts := make(map[string]float64)
+	for _, coin := range coins {
+		id, err := resolveCoin(ctx, coin, exactID)
+		if err != nil {
+			quotes = append(quotes, CoinQuote{Coin: coin, Amount: inputAmounts[coin], Error: err.Error(), err: err})
+			continue
+		}
+		if !seenArg[coin] {
+			seenArg[coin] = true
+			amounts[id] += inputAmounts[coin]
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		for _, currency := range currencies {
+			quotes = append(quotes, CoinQuote{Coin: id, Currency: currency})
+		}
+	}
+
+	ids := make([]string, 0, len(seen))
+	for _, q := range quotes {
+		if q.err == nil && (len(ids) == 0 || ids[len(ids)-1] != q.Coin) {
+			ids = append(ids, q.Coin)
+		}
+	}
+	if priceAt.IsZero() {
+		ctx = prefetchBatches(ctx, ids, currencies)
+	}
+
+	jobs := make(chan *CoinQuote)
+	var wg sync.WaitGroup
+	for n := max(concurrency, 1); n > 0; n-- {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := range jobs {
+				*q = quoteCoin(ctx, q.Coin, q.Currency)
+				q.Amount = amounts[q.Coin]
+				if q.err != nil {
+					q.Error = q.err.Error()
+				}
+			}
+		}()
+	}
+	for i := range quotes {
+		if quotes[i].err == nil {
+			jobs <- &quotes[i]
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	attachChanges(ctx, quotes)
+	attachComparisons(ctx, quotes)
+	return quotes
+}
+
+// quoteCoin prices the coin, giving up on providers that have not
+// answered within --timeout.
+func quoteCoin(ctx context.Context, crypto, currency string) CoinQuote {
+	if !priceAt.IsZero() {
+		return quoteCoinAt(ctx, crypto, currency, priceAt)
+	}
+	if requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+	q := CoinQuote{Coin: crypto, Currency: currency, Fetched: time.Now()}
+	defer func() { logQuote(q) }()
+	if minSources > 1 || (aggregateMode != "first" && aggregateMode != "priority") {
+		q.results = priceClient(crypto).All(ctx, crypto, currency)
+		if n := countUsable(q.results); n < minSources {
+			q.err = withExitCode(exitAllProvidersFailed, fmt.Errorf("only %d of %d providers returned a usable price for %s, at least %d required", n, len(q.results), crypto, minSources))
+			return q
+		}
+	}
+
+	switch aggregateMode {
+	case "first", "priority":
+		var result pricefeed.Result
+		switch {
+		case selectionPolicy == "quorum" && q.results != nil:
+			result, _, _ = pricefeed.FindQuorum(q.results, quorumSize, quorumTolerance)
+		case selectionPolicy == "quorum":
+			result, q.results = priceClient(crypto).Quorum(ctx, crypto, currency, quorumSize, quorumTolerance)
+		case selectionPolicy == "preferred" && q.results != nil:
+			result = selectByPriority(q.results)
+		case selectionPolicy == "preferred":
+			result, q.results = priceClient(crypto).Preferred(ctx, crypto, currency, preferredGrace)
+		case aggregateMode == "first" && q.results != nil:
+			result = firstUsable(q.results)
+		case aggregateMode == "first":
+			result, q.results = priceClient(crypto).First(ctx, crypto, currency)
+		case q.results != nil:
+			result = selectByPriority(q.results)
+		default:
+			result, q.results = priceClient(crypto).ByPriority(ctx, crypto, currency)
+		}
+		q.Reason = selectionReason(selectionMode(), result, q.results)
+		if !result.Usable() && selectionPolicy == "quorum" {
+			q.err = quorumFailure(crypto, q.results)
+			return q
+		}
+		if !result.Usable() {
+			q.err = fetchFailure(crypto, q.results)
+			return q
+		}
+		q.Price, q.Source, q.Duration, q.Timestamp = result.Price, result.Source, result.Duration, result.Timestamp
+	case "mean", "median", "vwap", "all":
+		agg := aggregatePrices(q.results, maxDeviation, aggregateMode == "vwap")
+		if len(agg.Sources) == 0 && len(agg.Excluded) > 0 {
+			q.err = withExitCode(exitAllProvidersFailed, errors.New(tr("FetchFailedDeviation", "all %d prices of %s deviate more than %.2f%% from their median", len(agg.Excluded), crypto, maxDeviation)))
+			return q
+		}
+		if aggregateMode == "median" || aggregateMode == "all" {
+			agg.Price = agg.Median
+		}
+		if agg.Price <= 0 {
+			q.err = fetchFailure(crypto, q.results)
+			return q
+		}
+		q.Aggregate = &agg
+		q.Price = agg.Price
+		q.Source = tr("AggregateSource", "%s of %d sources", aggregateLabel(agg), len(agg.Sources))
+		for _, r := range agg.Sources {
+			q.Duration = max(q.Duration, r.Duration)
+		}
+		if aggregateMode == "all" {
+			q.Providers = q.results
+		}
+	}
+	return q
+}
+
+func aggregateLabel(agg AggregateResult) string {
+	switch {
+	case aggregateMode == "median" || aggregateMode == "all":
+		return tr("Median", "Median")
+	case agg.VolumeWeighted:
+		return tr("VolumeWeightedMean", "Volume-weighted mean")
+	}
+	return tr("TrimmedMean", "Trimmed mean")
+}
+
+// normalizeCurrencies lowercases the --vs-currency values and drops
+// duplicates.
+func normalizeCurrencies() error {
+	currencies, err := parseCurrencies(vsCurrencies)
+	if err != nil {
+		return err
+	}
+	vsCurrencies = currencies
+	return nil
+}
+
+// parseCurrencies lowercases and dedupes currency codes, rejecting any that
+// are not plain letters, and defaults to USD when none are given. Requests
+// to serve go through it as well as flags, since the codes end up in
+// provider URLs and cache keys.
+func parseCurrencies(values []string) ([]string, error) {
+	var currencies []string
+	seen := make(map[string]bool)
+	for _, c := range values {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "" || seen[c] {
+			continue
+		}
+		for _, r := range c {
+			if r < 'a' || r > 'z' {
+				return nil, fmt.Errorf("invalid currency %q", c)
+			}
+		}
+		seen[c] = true
+		currencies = append(currencies, c)
+	}
+	if len(currencies) == 0 {
+		currencies = []string{"usd"}
+	}
+	return currencies, nil
+}
+
+var currencySymbols = map[string]string{
+	"usd": "$",
+	"eur": "€",
+	"gbp": "£",
+	"jpy": "¥",
+}
+
+// pricePrecision is --precision; -1 picks the decimal places per price.
+var pricePrecision = -1
+
+// autoDecimals stands in for --decimals when it was not given, so --quiet
+// follows priceDecimals.
+const autoDecimals = -2
+
+// autoInt is an int flag whose unset value, auto, shows as "auto" in the
+// help rather than as the number standing in for it.
+type autoInt struct {
+	p    *int
+	auto int
+}
+
+func (v autoInt) String() string {
+	if v.p == nil || *v.p == v.auto {
+		return "auto"
+	}
+	return strconv.Itoa(*v.p)
+}
+
+func (v autoInt) Set(s string) error {
+	if s == "auto" {
+		*v.p = v.auto
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return errors.New("expected a number or auto")
+	}
+	*v.p = n
+	return nil
+}
+
+func (v autoInt) Type() string { return "int" }
+
+// priceDecimals is the number of decimal places shown for a price:
+// --precision if set, otherwise two, or enough for four significant digits
+// below 1, so that $0.00002412 does not show as $0.00.
+func priceDecimals(price float64) int {
+	if pricePrecision >= 0 {
+		return pricePrecision
+	}
+	abs := math.Abs(price)
+	if abs >= 1 || abs == 0 {
+		return 2
+	}
+	return min(int(-math.Floor(math.Log10(abs)))+3, 16)
+}
+
+// formatPrice renders a price with the currency's symbol, or with its code
+// and enough significant digits for crypto quote currencies like btc.
+func formatPrice(price float64, currency string) string {
+	if humanizeNumbers {
+		if s, ok := humanizeNumber(price); ok {
+			return withCurrency(s, currency)
+		}
+	}
+	if _, ok := currencySymbol(currency); ok || pricePrecision >= 0 {
+		return withCurrency(localizeNumber(price, priceDecimals(price)), currency)
+	}
+	scale := math.Pow10(max(priceDecimals(price), 8))
+	return withCurrency(localizeNumber(math.Round(price*scale)/scale, -1), currency)
+}
+
+// withChanges appends the quote's --change periods, --compare difference
+// and --show-time fetch time to a price line.
+func withChanges(line string, q CoinQuote, color bool) string {
+	for _, extra := range []string{formatChanges(q, color), formatComparison(q, color), formatFetched(q, color)} {
+		if extra != "" {
+			line = strings.TrimSuffix(line, "\n") + " " + extra + "\n"
+		}
+	}
+	return line
+}
+
+// fetchedTimeLayout shows the zone, so pasted output says which one.
+const fetchedTimeLayout = "2006-01-02 15:04:05 MST"
+
+func formatFetched(q CoinQuote, color bool) string {
+	if !showTime || q.Fetched.IsZero() {
+		return ""
+	}
+	return dim("at "+q.Fetched.Local().Format(fetchedTimeLayout), color)
+}
+
+func printHolding(q CoinQuote) {
+	if q.Amount != 0 {
+		fmt.Print(tr("HoldingValue", "  %s %s is worth %s\n", strconv.FormatFloat(q.Amount, 'f', -1, 64), strings.ToUpper(coinSymbol(q.Coin)), formatPrice(q.Value(), q.Currency)))
+	}
+}
+
+func printQuote(q CoinQuote) {
+	if !priceAt.IsZero() {
+		printQuoteAt(q)
+		return
+	}
+	color := useColor(os.Stdout)
+	if q.Aggregate != nil {
+		fmt.Print(withChanges(tr("AggregatePriceLine", "The current price of %s is %s (%s)\n", q.Coin, formatPrice(q.Price, q.Currency), q.Source), q, color))
+		printHolding(q)
+		warnOnDivergence(*q.Aggregate)
+		if q.Providers != nil {
+			printProviderComparison(q)
+		} else if verbose {
+			printAggregateDetails(*q.Aggregate, q.Currency)
+		}
+		return
+	}
+
+	fmt.Print(withChanges(tr("PriceLine", "The current price of %s is %s (Source: %s, Duration: %s%s)\n", q.Coin, formatPrice(q.Price, q.Currency), dim(q.Source, color), dim(q.Duration.String(), color), ageSuffix(pricefeed.Result{Timestamp: q.Timestamp})), q, color))
+	printHolding(q)
+	if verbose {
+		fmt.Print(tr("SelectedReason", "  Selected: %s\n", q.Reason))
+		fmt.Print(fxNote(q))
+		for _, r := range q.results {
+			if r.Stale {
+				fmt.Print(tr("RejectedStale", "  %s: %s rejected as stale (Age: %s)\n", r.Source, formatPrice(r.Price, q.Currency), r.Age()))
+			}
+		}
+		printBreakerStates()
+	}
+}
+
+// printQuiet prints each price as a bare number for shell arithmetic and
+// status bars. A failed quote leaves its line empty, keeping the lines in
+// the order of the arguments, and only shows in the exit code.
+func printQuiet(quotes []CoinQuote) error {
+	for _, q := range quotes {
+		if q.err == nil {
+			decimals := quietDecimals
+			if decimals == autoDecimals {
+				decimals = priceDecimals(q.Price)
+			}
+			fmt.Print(strconv.FormatFloat(q.Price, 'f', decimals, 64))
+		}
+		fmt.Println()
+	}
+	if err := batchError(quotes); err != nil {
+		return exitSilently(exitCode(err))
+	}
+	return nil
+}
+
+func printQuoteTable(quotes []CoinQuote) {
+	color := useColor(os.Stdout)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header, painted := tr("QuoteTableHeader", "COIN\tPRICE\tSOURCE\tDURATION"), []int{2, 3}
+	for i, p := range changePeriods {
+		header += "\t" + strings.ToUpper(p)
+		painted = append(painted, 4+i)
+	}
+	if showTime {
+		header += tr("QuoteTableTimeHeader", "\tFETCHED")
+	}
+	if comparing() {
+		header += "\t" + compareLabel()
+		painted = append(painted, 4+len(changePeriods))
+	}
+	amounts := hasAmounts(quotes)
+	if amounts {
+		header += tr("QuoteTableAmountHeader", "\tAMOUNT\tVALUE")
+	}
+	fmt.Fprintln(w, paintHeader(header, color, painted...))
+	for _, q := range quotes {
+		switch {
+		case q.err != nil:
+			fmt.Fprintf(w, "%s\t-\t%s\t%s", q.Coin, paint(tr("TableError", "error: %v", q.err), "31", color), dim("", color))
+		default:
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s", q.Coin, formatPrice(q.Price, q.Currency), dim(q.Source, color), dim(q.Duration.String(), color))
+		}
+		for _, p := range changePeriods {
+			change := paint("-", "39", color)
+			if v, ok := q.Changes[p]; ok {
+				change = colored(fmt.Sprintf("%+.2f%%", v), v >= 0, color)
+			}
+			fmt.Fprintf(w, "\t%s", change)
+		}
+		if showTime {
+			fetched := "-"
+			if !q.Fetched.IsZero() {
+				fetched = q.Fetched.Local().Format(fetchedTimeLayout)
+			}
+			fmt.Fprintf(w, "\t%s", fetched)
+		}
+		if comparing() {
+			diff := paint("-", "39", color)
+			if q.Compare != nil {
+				diff = formatDiff(q.Compare, q.Currency, color)
+				if q.Compare.Diff == 0 && color {
+					diff = paint(diff, "39", color)
+				}
+			}
+			fmt.Fprintf(w, "\t%s", diff)
+		}
+		if amounts {
+			amount, value := "-", "-"
+			if q.Amount != 0 {
+				amount = strconv.FormatFloat(q.Amount, 'f', -1, 64)
+			}
+			if q.Amount != 0 && q.err == nil {
+				value = formatPrice(q.Value(), q.Currency)
+			}
+			fmt.Fprintf(w, "\t%s\t%s", amount, value)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	for _, q := range quotes {
+		if q.Providers != nil {
+			fmt.Printf("\n%s (%s):\n", q.Coin, strings.ToUpper(q.Currency))
+			printProviderComparison(q)
+		}
+	}
+	for _, q := range quotes {
+		if q.Aggregate != nil && q.Aggregate.Spread > divergenceThreshold {
+			fmt.Fprintf(os.Stderr, "%s: ", q.Coin)
+			warnOnDivergence(*q.Aggregate)
+		}
+	}
+	if verbose {
+		for _, q := range quotes {
+			if note := fxNote(q); note != "" {
+				fmt.Printf("%s %s:\n%s", q.Coin, strings.ToUpper(q.Currency), note)
+			}
+		}
+		printBreakerStates()
+	}
+}
+
+// printProviderComparison lists every provider's answer for the quote side
+// by side, with each price's difference from the median, so stale or
+// divergent feeds stand out.
+func printProviderComparison(q CoinQuote) {
+	color := useColor(os.Stdout)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, paintHeader(tr("ProviderComparisonHeader", "  PROVIDER\tPRICE\tVS MEDIAN\tAGE\tDURATION\tSTATUS"), color, 2, 4))
+	excluded := make(map[string]bool)
+	for _, r := range q.Aggregate.Excluded {
+		excluded[r.Source] = true
+	}
+	for _, r := range q.Providers {
+		price, diff, age := "-", paint("-", "39", color), "-"
+		if r.Price > 0 {
+			price = formatPrice(r.Price, q.Currency)
+			delta := r.Price - q.Aggregate.Median
+			diff = colored(fmt.Sprintf("%+.2f%%", delta/q.Aggregate.Median*100), delta >= 0, color)
+		}
+		if !r.Timestamp.IsZero() {
+			age = r.Age().String()
+		}
+		status := paint(tr("StatusOK", "ok"), "32", color)
+		switch {
+		case r.Error != "":
+			status = paint(r.Error, "31", color)
+		case r.Stale:
+			status = paint(tr("StatusStale", "stale"), "33", color)
+		case excluded[r.Source]:
+			status = dim(tr("StatusExcluded", "excluded"), color)
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", r.Source, price, diff, age, dim(r.Duration.String(), color), status)
+	}
+	w.Flush()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPriceDecimals(t *testing.T) {
+	tests := []struct {
+		price     float64
+		precision int
+		want      int
+	}{
+		{67000, -1, 2},
+		{1, -1, 2},
+		{0, -1, 2},
+		{0.5, -1, 4},
+		{0.0000241, -1, 8},
+		{-0.0000241, -1, 8},
+		{1e-20, -1, 16},
+		{0.0000241, 4, 4},
+		{67000, 0, 0},
+	}
+	defer func(p int) { pricePrecision = p }(pricePrecision)
+	for _, tt := range tests {
+		pricePrecision = tt.precision
+		if got := priceDecimals(tt.price); got != tt.want {
+			t.Errorf("priceDecimals(%v) with --precision %d = %d, want %d", tt.price, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		price     float64
+		currency  string
+		precision int
+		want      string
+	}{
+		{67000, "usd", -1, "$67000.00"},
+		{0.0000241, "usd", -1, "$0.00002410"},
+		{0.1234, "eur", -1, "€0.1234"},
+		{1.5, "usd", 4, "$1.5000"},
+		{0.05229, "btc", -1, "0.05229 BTC"},
+		{0.000012345678912, "btc", -1, "0.00001235 BTC"},
+		{2, "sek", -1, "2 SEK"},
+	}
+	defer func(p int) { pricePrecision = p }(pricePrecision)
+	for _, tt := range tests {
+		pricePrecision = tt.precision
+		if got := formatPrice(tt.price, tt.currency); got != tt.want {
+			t.Errorf("formatPrice(%v, %q) with --precision %d = %q, want %q", tt.price, tt.currency, tt.precision, got, tt.want)
+		}
+	}
+}
+
+func TestParseCurrencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+		err    bool
+	}{
+		{"default", nil, "usd", false},
+		{"lowercased and trimmed", []string{" EUR", "usd "}, "eur,usd", false},
+		{"duplicates dropped", []string{"usd", "USD", "eur", "usd"}, "usd,eur", false},
+		{"empty entries skipped", []string{"", "gbp", " "}, "gbp", false},
+		{"crypto quote currency", []string{"btc"}, "btc", false},
+		{"digits rejected", []string{"usd1"}, "", true},
+		{"punctuation rejected", []string{"us-d"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCurrencies(tt.values)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error: %v", err, tt.err)
+			}
+			if s := strings.Join(got, ","); !tt.err && s != tt.want {
+				t.Errorf("currencies = %s, want %s", s, tt.want)
+			}
+		})
+	}
+}
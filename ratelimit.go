@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+const (
+	defaultBurst            = 5
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = time.Minute
+)
+
+// defaultRateLimits are the requests per minute allowed to providers with
+// a documented free-tier limit, unless rate_limit overrides them.
+var defaultRateLimits = map[string]int{
+	"coingecko": 30,
+}
+
+// coingeckoProRateLimit is the lowest paid CoinGecko plan's limit.
+const coingeckoProRateLimit = 500
+
+func defaultRateLimit(provider string) int {
+	if provider == "coingecko" && coingeckoPro() {
+		return coingeckoProRateLimit
+	}
+	return defaultRateLimits[provider]
+}
+
+// tokenBucket holds up to burst tokens and gains perMinute of them a
+// minute; every request takes one. Tokens may go negative, which queues the
+// request until its token has been earned.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var (
+	rateMu  sync.Mutex
+	buckets = make(map[string]*tokenBucket)
+)
+
+// waitForRateLimit takes a token from the provider's bucket, waiting until
+// one is available or ctx is done.
+func waitForRateLimit(ctx context.Context, provider string, perMinute, burst int) error {
+	if perMinute <= 0 {
+		return nil
+	}
+	burst = min(max(burst, 1), perMinute)
+	perSecond := float64(perMinute) / 60
+
+	rateMu.Lock()
+	now := time.Now()
+	b := buckets[provider]
+	if b == nil {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		buckets[provider] = b
+	}
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*perSecond, float64(burst))
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens / perSecond * float64(time.Second))
+	rateMu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		rateMu.Lock()
+		b.tokens++
+		rateMu.Unlock()
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// rateLimitedTransport applies the provider's rate limit to every request.
+type rateLimitedTransport struct {
+	provider  string
+	perMinute int
+	burst     int
+}
+
+func (t rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if offline {
+		return nil, errOffline
+	}
+	if err := waitForRateLimit(req.Context(), t.provider, t.perMinute, t.burst); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := baseTransport().RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	logRequest(t.provider, req.URL, status, time.Since(start), err)
+	return resp, err
+}
+
+// circuitBreaker tracks a provider's consecutive failures. Once they reach
+// the threshold the circuit opens and requests fail at once until the
+// cooldown has passed; then a single trial request is let through, which
+// closes the circuit on success and reopens it on failure.
+type circuitBreaker struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+	// limited is set while the circuit is open because the provider asked
+	// for a pause with Retry-After, rather than for failures.
+	limited bool
+}
+
+var (
+	breakerMu sync.Mutex
+	breakers  = make(map[string]*circuitBreaker)
+)
+
+type breakerTransport struct {
+	provider  string
+	threshold int
+	cooldown  time.Duration
+	next      http.RoundTripper
+}
+
+func (t breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.threshold <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	if err := t.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if errors.Is(req.Context().Err(), context.Canceled) {
+		t.record(false, true)
+		return resp, err
+	}
+	failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	t.record(failed, false)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		if after, ok := retryAfter(resp); ok && after > 0 {
+			t.holdOff(after)
+		}
+	}
+	return resp, err
+}
+
+// holdOff opens the circuit for as long as a rate-limited provider asked,
+// so later requests go straight to the other providers instead of adding
+// to the limit.
+func (t breakerTransport) holdOff(after time.Duration) {
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	b := breakers[t.provider]
+	if until := time.Now().Add(after); until.After(b.openUntil) {
+		b.openUntil, b.limited = until, true
+		logger.Warn("provider rate limited", "provider", t.provider, "retry_after", after)
+	}
+}
+
+func (t breakerTransport) allow() error {
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	b := breakers[t.provider]
+	if b == nil || b.openUntil.IsZero() {
+		return nil
+	}
+	wait := time.Until(b.openUntil)
+	if b.limited && wait > 0 {
+		return fmt.Errorf("%w: asked to retry in %s", pricefeed.ErrRateLimited, wait.Round(time.Second))
+	}
+	if wait > 0 || b.trial {
+		return fmt.Errorf("%w: circuit breaker open after %d consecutive failures, retrying in %s",
+			pricefeed.ErrUnreachable, b.failures, max(wait, 0).Round(time.Second))
+	}
+	b.trial = true
+	return nil
+}
+
+// record counts the outcome of a request let through. A canceled request
+// proves nothing either way, but frees the trial slot.
+func (t breakerTransport) record(failed, canceled bool) {
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	b := breakers[t.provider]
+	if b == nil {
+		b = &circuitBreaker{}
+		breakers[t.provider] = b
+	}
+	trial := b.trial
+	b.trial = false
+	switch {
+	case canceled:
+	case !failed:
+		b.failures, b.openUntil, b.limited = 0, time.Time{}, false
+	default:
+		b.failures++
+		if trial || b.failures >= t.threshold {
+			b.openUntil, b.limited = time.Now().Add(t.cooldown), false
+			logger.Warn("circuit breaker open", "provider", t.provider, "failures", b.failures, "cooldown", t.cooldown)
+		}
+	}
+}
+
+// breakerStates describes every provider with failures on record, for
+// --verbose output.
+func breakerStates() []string {
+	breakerMu.Lock()
+	defer breakerMu.Unlock()
+	var states []string
+	for name, b := range breakers {
+		switch {
+		case b.failures == 0:
+			continue
+		case b.openUntil.IsZero():
+			states = append(states, fmt.Sprintf("%s closed (%d consecutive failures)", name, b.failures))
+		case time.Now().Before(b.openUntil):
+			states = append(states, fmt.Sprintf("%s open (%d consecutive failures, retrying in %s)", name, b.failures, time.Until(b.openUntil).Round(time.Second)))
+		default:
+			states = append(states, fmt.Sprintf("%s half-open (%d consecutive failures, next request is a trial)", name, b.failures))
+		}
+	}
+	sort.Strings(states)
+	return states
+}
+
+func printBreakerStates() {
+	if states := breakerStates(); len(states) > 0 {
+		fmt.Printf("  Circuit breakers: %s\n", strings.Join(states, "; "))
+	}
+}
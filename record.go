@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordInterval   time.Duration
+	recordDB         string
+	recordCurrencies []string
+	recordFrom       string
+	recordTo         string
+	backfillGrain    string
+)
+
+// backfillGrains maps --granularity to the bucket each backfilled price
+// stands for.
+var backfillGrains = map[string]time.Duration{
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+type PriceRecord struct {
+	Time     time.Time `json:"time"`
+	Coin     string    `json:"coin"`
+	Currency string    `json:"currency"`
+	Price    float64   `json:"price"`
+	Source   string    `json:"source"`
+}
+
+func recordPath() string {
+	if recordDB != "" {
+		return recordDB
+	}
+	return databasePath()
+}
+
+func insertQuotes(db *sql.DB, at time.Time, quotes []CoinQuote) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	n := 0
+	for _, q := range quotes {
+		if q.err != nil {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO prices (time, coin, currency, price, source) VALUES (?, ?, ?, ?, ?)`,
+			at.Unix(), q.Coin, q.Currency, q.Price, q.Source); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, tx.Commit()
+}
+
+// runRecord samples the coins every interval until interrupted, logging
+// failed rounds and carrying on. SIGHUP reloads the config.
+func runRecord(ctx context.Context, cmd *cobra.Command, db *sql.DB, coins []string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := hangups(ctx)
+	allowPrompt = false
+	noCache = true
+
+	fmt.Fprintf(os.Stderr, "Recording %d coins every %s into %s (Ctrl-C to stop)\n", len(coins), recordInterval, recordPath())
+	for {
+		at := time.Now().Truncate(time.Second)
+		quotes := quoteCoins(ctx, coins, recordCurrencies)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if _, err := insertQuotes(db, at, quotes); err != nil {
+			return fmt.Errorf("writing to %s: %w", recordPath(), err)
+		}
+		if err := batchError(quotes); err != nil {
+			log.Print(tr("Error", "Error: %v", err))
+		}
+
+		next := time.After(time.Until(at.Add(recordInterval)))
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-hup:
+				logReload(cmd)
+			case <-next:
+				break wait
+			}
+		}
+	}
+}
+
+func queryRecords(db *sql.DB, coins []string, from, to time.Time) ([]PriceRecord, error) {
+	query := `SELECT time, coin, currency, price, source FROM prices WHERE time >= ? AND time <= ?`
+	args := []interface{}{from.Unix(), to.Unix()}
+	if len(coins) > 0 {
+		query += ` AND coin IN (?` + strings.Repeat(`, ?`, len(coins)-1) + `)`
+		for _, c := range coins {
+			args = append(args, c)
+		}
+	}
+	rows, err := db.Query(query+` ORDER BY time, coin, currency`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []PriceRecord
+	for rows.Next() {
+		var r PriceRecord
+		var unix int64
+		if err := rows.Scan(&unix, &r.Coin, &r.Currency, &r.Price, &r.Source); err != nil {
+			return nil, err
+		}
+		r.Time = time.Unix(unix, 0).UTC()
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// recordedBuckets returns the start of every grain-sized bucket between
+// from and to that already holds a sample of coin in currency.
+func recordedBuckets(db *sql.DB, coin, currency string, from, to time.Time, grain time.Duration) (map[int64]bool, error) {
+	rows, err := db.Query(`SELECT time FROM prices WHERE coin = ? AND currency = ? AND time >= ? AND time <= ?`,
+		coin, currency, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	buckets := make(map[int64]bool)
+	for rows.Next() {
+		var unix int64
+		if err := rows.Scan(&unix); err != nil {
+			return nil, err
+		}
+		buckets[time.Unix(unix, 0).Truncate(grain).Unix()] = true
+	}
+	return buckets, rows.Err()
+}
+
+// backfill fetches coin's price history from CoinGecko and stores one
+// price per grain-sized bucket, the last of each, at the start of the
+// bucket. Buckets that already hold a sample are left alone, so it fills
+// gaps and running it again adds nothing. Hourly prices are fetched in
+// ranges CoinGecko answers hourly.
+func backfill(ctx context.Context, db *sql.DB, coin, currency string, from, to time.Time, grain time.Duration) (fetched, added int, err error) {
+	recorded, err := recordedBuckets(db, coin, currency, from, to, grain)
+	if err != nil {
+		return 0, 0, err
+	}
+	window := to.Sub(from)
+	if grain < 24*time.Hour {
+		window = maxHourlyRange
+	}
+	last := make(map[int64]float64)
+	for start := from; start.Before(to); start = start.Add(window) {
+		end := start.Add(window)
+		if end.After(to) {
+			end = to
+		}
+		points, err := fetchHistory(ctx, coin, currency, start, end)
+		if err != nil {
+			return 0, 0, withExitCode(exitAllProvidersFailed, err)
+		}
+		for _, p := range points {
+			if !p.Time.Before(from) {
+				last[p.Time.Truncate(grain).Unix()] = p.Price
+			}
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+	for bucket, price := range last {
+		if recorded[bucket] {
+			continue
+		}
+		res, err := tx.Exec(`INSERT OR IGNORE INTO prices (time, coin, currency, price, source) VALUES (?, ?, ?, ?, ?)`,
+			bucket, coin, currency, price, "CoinGecko")
+		if err != nil {
+			return 0, 0, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			added++
+		}
+	}
+	return len(last), added, tx.Commit()
+}
+
+func printRecordsCSV(records []PriceRecord) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"time", "coin", "currency", "price", "source"})
+	for _, r := range records {
+		w.Write([]string{r.Time.Format(time.RFC3339), r.Coin, r.Currency, strconv.FormatFloat(r.Price, 'f', -1, 64), r.Source})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var recordCmd = &cobra.Command{
+	Use:   "record <coin...>",
+	Short: "Sample prices into a local SQLite database until interrupted",
+	Long: `Sample the coins' prices every --interval and store them in a SQLite
+database, by default the one shown by "crypto-cli paths". Use "record
+export" to read them back. SIGHUP reloads the config file without
+missing a sample; SIGINT and SIGTERM close the database and exit.`,
+	Example: `  crypto-cli record bitcoin ethereum --interval 1m
+  crypto-cli record btc --interval 5m --db prices.db`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if recordInterval < time.Second {
+			return errors.New(tr("IntervalTooShort", "--interval must be at least %s", time.Second))
+		}
+		coins, err := resolveCoinList(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		db, err := openDB(recordPath())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return runRecord(cmd.Context(), cmd, db, coins)
+	},
+}
+
+var recordExportCmd = &cobra.Command{
+	Use:   "export [coin...]",
+	Short: "Print recorded prices as CSV or JSON",
+	Example: `  crypto-cli record export --from 2024-06-01 --to 2024-06-30
+  crypto-cli record export bitcoin --from 2024-06-01T12:00:00Z -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from, to := time.Unix(0, 0), time.Now()
+		var err error
+		if recordFrom != "" {
+			if from, err = parseDate(recordFrom); err != nil {
+				return err
+			}
+		}
+		if recordTo != "" {
+			if to, err = parseDate(recordTo); err != nil {
+				return err
+			}
+		}
+		coins, err := resolveCoinList(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(recordPath()); err != nil {
+			return fmt.Errorf("no recorded prices: %w", err)
+		}
+		db, err := openDB(recordPath())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		records, err := queryRecords(db, coins, from, to)
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			if records == nil {
+				records = []PriceRecord{}
+			}
+			return printJSON(records)
+		}
+		return printRecordsCSV(records)
+	},
+}
+
+var recordBackfillCmd = &cobra.Command{
+	Use:   "backfill <coin...>",
+	Short: "Fill gaps in the recorded prices from CoinGecko's price history",
+	Long: `Fetch the coins' price history from CoinGecko between --from and --to and
+store one price per --granularity period, hourly (1h) or daily (1d), in
+the periods that have no recorded sample yet. Samples already recorded
+are kept, so it can be run again safely, for example after the recorder
+was down. Hourly history is fetched 90 days at a time; how far back it
+goes depends on the CoinGecko plan.`,
+	Example: `  crypto-cli record backfill bitcoin --from 2022-01-01 --granularity 1d
+  crypto-cli record backfill btc eth --from 2024-06-01 --granularity 1h -c usd,eur`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		grain, ok := backfillGrains[backfillGrain]
+		if !ok {
+			return fmt.Errorf("invalid --granularity %q: use 1h or 1d", backfillGrain)
+		}
+		from, err := parseDate(recordFrom)
+		if err != nil {
+			return err
+		}
+		to := time.Now()
+		if recordTo != "" {
+			if to, err = parseDate(recordTo); err != nil {
+				return err
+			}
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("--from must be before --to")
+		}
+		if offline {
+			return errOffline
+		}
+		coins, err := resolveCoinList(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		db, err := openDB(recordPath())
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		for _, coin := range coins {
+			for _, currency := range recordCurrencies {
+				currency = strings.ToLower(currency)
+				fetched, added, err := backfill(cmd.Context(), db, coin, currency, from, to, grain)
+				if err != nil {
+					return fmt.Errorf("backfilling %s in %s: %w", coin, strings.ToUpper(currency), err)
+				}
+				fmt.Printf("%s %s: %d periods fetched, %d added, %d already recorded\n", coin, strings.ToUpper(currency), fetched, added, fetched-added)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	recordCmd.Flags().DurationVar(&recordInterval, "interval", time.Minute, "time between samples")
+	recordCmd.Flags().StringSliceVarP(&recordCurrencies, "vs-currency", "c", []string{"usd"}, "currencies to record prices in")
+	recordCmd.PersistentFlags().StringVar(&recordDB, "db", "", "SQLite database file (default is the data dir's crypto-cli.db)")
+	recordCmd.PersistentFlags().BoolVar(&exactID, "exact-id", false, "treat arguments as CoinGecko coin IDs and skip symbol resolution")
+	recordExportCmd.Flags().StringVar(&recordFrom, "from", "", "start date (YYYY-MM-DD or RFC 3339, default the first sample)")
+	recordExportCmd.Flags().StringVar(&recordTo, "to", "", "end date (YYYY-MM-DD or RFC 3339, default now)")
+	recordCmd.AddCommand(recordExportCmd)
+	recordBackfillCmd.Flags().StringVar(&recordFrom, "from", "", "start date (YYYY-MM-DD or RFC 3339)")
+	recordBackfillCmd.Flags().StringVar(&recordTo, "to", "", "end date (YYYY-MM-DD or RFC 3339, default now)")
+	recordBackfillCmd.Flags().StringVar(&backfillGrain, "granularity", "1d", "one price per hour (1h) or day (1d)")
+	recordBackfillCmd.Flags().StringSliceVarP(&recordCurrencies, "vs-currency", "c", []string{"usd"}, "currencies to backfill prices in")
+	recordBackfillCmd.MarkFlagRequired("from")
+	recordCmd.AddCommand(recordBackfillCmd)
+	rootCmd.AddCommand(recordCmd)
+}
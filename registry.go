@@ -0,0 +1,417 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	coingeckoCoinsListAPI = "/coins/list"
+	coingeckoMarketsAPI   = "/coins/markets?vs_currency=%s&ids=%s"
+	registryTTL           = 24 * time.Hour
+)
+
+type Coin struct {
+	ID     string `json:"id"`
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+}
+
+type coinMarket struct {
+	ID                string  `json:"id"`
+	Symbol            string  `json:"symbol"`
+	Name              string  `json:"name"`
+	Price             float64 `json:"current_price"`
+	MarketCap         float64 `json:"market_cap"`
+	Volume            float64 `json:"total_volume"`
+	CirculatingSupply float64 `json:"circulating_supply"`
+	PriceChange24h    float64 `json:"price_change_percentage_24h"`
+	Rank              int     `json:"market_cap_rank"`
+
+	// Changes holds the price_change_percentage_<period>_in_currency
+	// fields CoinGecko adds when asked for them, keyed by period.
+	Changes map[string]float64 `json:"-"`
+}
+
+func (m *coinMarket) UnmarshalJSON(data []byte) error {
+	type plain coinMarket
+	if err := json.Unmarshal(data, (*plain)(m)); err != nil {
+		return err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for key, v := range fields {
+		period, ok := strings.CutPrefix(key, "price_change_percentage_")
+		if period, ok = strings.CutSuffix(period, "_in_currency"); !ok {
+			continue
+		}
+		if pct, ok := v.(float64); ok {
+			if m.Changes == nil {
+				m.Changes = make(map[string]float64)
+			}
+			m.Changes[period] = pct
+		}
+	}
+	return nil
+}
+
+// allowPrompt is cleared by non-interactive modes so an ambiguous symbol
+// is reported instead of blocking on a prompt.
+var allowPrompt = true
+
+type coinRegistry struct {
+	coins   []Coin
+	fetched time.Time
+}
+
+func registryPath() string {
+	return filepath.Join(cacheDir(), "coins.json")
+}
+
+var (
+	registryMu     sync.Mutex
+	loadedRegistry *coinRegistry
+)
+
+// loadRegistry returns the CoinGecko coin list, served from memory or the
+// on-disk cache while it is younger than registryTTL. A stale cache is
+// still used when the refresh fails.
+func loadRegistry(forceRefresh bool) (*coinRegistry, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if r := loadedRegistry; r != nil && !forceRefresh && time.Since(r.fetched) < registryTTL {
+		return r, nil
+	}
+	r, err := readOrFetchRegistry(forceRefresh)
+	if err == nil {
+		loadedRegistry = r
+	}
+	return r, err
+}
+
+func readOrFetchRegistry(forceRefresh bool) (*coinRegistry, error) {
+	path := registryPath()
+	cached, cacheErr := readRegistry(path)
+	if cacheErr == nil && !forceRefresh && time.Since(cached.fetched) < registryTTL {
+		return cached, nil
+	}
+
+	// The list is shared by every command and request, so one giving up
+	// must not cancel its refresh for the others.
+	fresh, err := fetchRegistry(context.Background())
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+	if data, err := json.Marshal(fresh.coins); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			os.WriteFile(path, data, 0o644)
+		}
+	}
+	return fresh, nil
+}
+
+func readRegistry(path string) (*coinRegistry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var coins []Coin
+	if err := json.Unmarshal(data, &coins); err != nil {
+		return nil, err
+	}
+	return &coinRegistry{coins: coins, fetched: info.ModTime()}, nil
+}
+
+func fetchRegistry(ctx context.Context) (*coinRegistry, error) {
+	resp, err := httpGet(ctx, providerURL("coingecko")+coingeckoCoinsListAPI, "coingecko")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("coin list request failed: %w", statusError("coingecko", resp))
+	}
+
+	var coins []Coin
+	if err := json.NewDecoder(resp.Body).Decode(&coins); err != nil {
+		return nil, err
+	}
+	return &coinRegistry{coins: coins, fetched: time.Now()}, nil
+}
+
+func (r *coinRegistry) lookup(id string) (Coin, bool) {
+	for _, c := range r.coins {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Coin{}, false
+}
+
+func (r *coinRegistry) bySymbol(symbol string) []Coin {
+	var matches []Coin
+	for _, c := range r.coins {
+		if strings.EqualFold(c.Symbol, symbol) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// suggest returns up to limit coin IDs close to the query, preferring
+// prefix matches and then small edit distances.
+func (r *coinRegistry) suggest(query string, limit int) []string {
+	type candidate struct {
+		id    string
+		score int
+	}
+	maxDistance := len(query)/3 + 1
+	var candidates []candidate
+	for _, c := range r.coins {
+		score := levenshtein(query, c.ID)
+		if strings.HasPrefix(c.ID, query) || strings.EqualFold(c.Symbol, query) {
+			score = 0
+		}
+		if score <= maxDistance {
+			candidates = append(candidates, candidate{c.ID, score})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return len(candidates[i].id) < len(candidates[j].id)
+	})
+
+	var ids []string
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		ids = append(ids, candidates[i].id)
+	}
+	return ids
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// validateCoin checks the coin ID against the registry before any price
+// request is made. When the registry cannot be loaded the check is skipped
+// so an offline cache never blocks a lookup.
+func validateCoin(id string) error {
+	registry, err := loadRegistry(false)
+	if err != nil {
+		warnNoRegistry(id, err)
+		return nil
+	}
+	if _, ok := registry.lookup(id); ok {
+		return nil
+	}
+	if refreshed, ok := refreshedRegistry(registry); ok {
+		registry = refreshed
+		if _, ok := registry.lookup(id); ok {
+			return nil
+		}
+	}
+	return unknownCoinError(registry, id)
+}
+
+// resolveCoin maps user input to a CoinGecko coin ID. Config aliases are
+// applied first, then exact IDs win over ticker symbols; an ambiguous
+// symbol is resolved interactively on a terminal and reported as an error
+// otherwise. A watchlist of one coin stands for that coin, and a coin
+// pinned in the coin map is taken as is. Under --mock, only the symbols in
+// mockSymbols are resolved.
+func resolveCoin(ctx context.Context, query string, exactID bool) (string, error) {
+	if strings.HasPrefix(query, "@") {
+		coins, err := watchlist(query)
+		if err != nil {
+			return "", err
+		}
+		if len(coins) != 1 {
+			return "", fmt.Errorf("%s has %d coins, but only one is accepted here", query, len(coins))
+		}
+		return coins[0], nil
+	}
+	query = strings.ToLower(resolveAlias(query))
+	if mockMode {
+		if id, ok := mockSymbols[query]; ok && !exactID {
+			return id, nil
+		}
+		return query, nil
+	}
+	if _, ok := coinMap[query]; ok {
+		return query, nil
+	}
+	if exactID {
+		return query, validateCoin(query)
+	}
+	registry, err := loadRegistry(false)
+	if err != nil {
+		warnNoRegistry(query, err)
+		return query, nil
+	}
+
+	id, found, err := registry.resolve(ctx, query)
+	if !found {
+		if refreshed, ok := refreshedRegistry(registry); ok {
+			registry = refreshed
+			id, found, err = registry.resolve(ctx, query)
+		}
+	}
+	if err != nil || found {
+		return id, err
+	}
+	if matches := registry.search(query, 5); len(matches) > 0 && allowPrompt && isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		fmt.Print(tr("NoCoinDidYouMean", "No coin %q, did you mean:\n", query))
+		return pickFrom(matches)
+	}
+	return "", unknownCoinError(registry, query)
+}
+
+// warnNoRegistry reports that the coin list could not be loaded, so query
+// is passed on as a coin ID without checking or resolving it as a symbol.
+func warnNoRegistry(query string, err error) {
+	fmt.Fprint(os.Stderr, tr("NoRegistryWarning", "Warning: could not load the coin list (%v), using %q as a coin ID\n", err, query))
+}
+
+func (r *coinRegistry) resolve(ctx context.Context, query string) (string, bool, error) {
+	if _, ok := r.lookup(query); ok {
+		return query, true, nil
+	}
+	matches := r.bySymbol(query)
+	switch len(matches) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return matches[0].ID, true, nil
+	}
+
+	ranked := rankByMarketCap(ctx, matches)
+	if allowPrompt && isTerminal(os.Stdin) {
+		id, err := pickCoin(query, ranked)
+		return id, true, err
+	}
+	var lines []string
+	for _, c := range ranked {
+		lines = append(lines, fmt.Sprintf("  %s (%s)", c.ID, c.Name))
+	}
+	return "", true, errors.New(tr("AmbiguousSymbol", "symbol %q is ambiguous, pass one of these coin IDs with --exact-id:\n%s", query, strings.Join(lines, "\n")))
+}
+
+func refreshedRegistry(registry *coinRegistry) (*coinRegistry, bool) {
+	if time.Since(registry.fetched) < time.Hour {
+		return registry, false
+	}
+	refreshed, err := loadRegistry(true)
+	if err != nil {
+		return registry, false
+	}
+	return refreshed, true
+}
+
+func unknownCoinError(registry *coinRegistry, id string) error {
+	suggestions := registry.suggest(id, 3)
+	if len(suggestions) == 0 {
+		return withExitCode(exitCoinNotFound, errors.New(tr("UnknownCoin", "unknown coin %q", id)))
+	}
+	return withExitCode(exitCoinNotFound, errors.New(tr("UnknownCoinSuggest", "unknown coin %q, did you mean %s?", id, "`"+strings.Join(suggestions, "`, `")+"`")))
+}
+
+// fetchMarkets returns CoinGecko market data in USD for the given coin IDs.
+func fetchMarkets(ctx context.Context, ids []string) ([]coinMarket, error) {
+	return fetchMarketsIn(ctx, ids, "usd")
+}
+
+// fetchMarketsIn returns CoinGecko market data for the given coin IDs with
+// prices in currency.
+func fetchMarketsIn(ctx context.Context, ids []string, currency string) ([]coinMarket, error) {
+	var markets []coinMarket
+	err := getJSON(ctx, providerURL("coingecko")+fmt.Sprintf(coingeckoMarketsAPI, currency, strings.Join(ids, ",")), "coingecko", &markets)
+	return markets, err
+}
+
+// rankByMarketCap orders coins sharing a symbol by market cap, largest
+// first. If market data is unavailable the registry order is kept.
+func rankByMarketCap(ctx context.Context, coins []Coin) []Coin {
+	ids := make([]string, len(coins))
+	for i, c := range coins {
+		ids[i] = c.ID
+	}
+	markets, err := fetchMarkets(ctx, ids)
+	if err != nil {
+		return coins
+	}
+	caps := make(map[string]float64)
+	for _, m := range markets {
+		caps[m.ID] = m.MarketCap
+	}
+
+	ranked := append([]Coin(nil), coins...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return caps[ranked[i].ID] > caps[ranked[j].ID]
+	})
+	return ranked
+}
+
+func pickCoin(symbol string, coins []Coin) (string, error) {
+	fmt.Print(tr("SeveralCoins", "Several coins use the symbol %q:\n", symbol))
+	return pickFrom(coins)
+}
+
+func pickFrom(coins []Coin) (string, error) {
+	for i, c := range coins {
+		fmt.Printf("  %d) %s (%s)\n", i+1, c.ID, c.Name)
+	}
+	fmt.Print(tr("PickCoin", "Pick a coin [1-%d, default 1]: ", len(coins)))
+
+	var answer string
+	fmt.Scanln(&answer)
+	if answer == "" {
+		return coins[0].ID, nil
+	}
+	var n int
+	if _, err := fmt.Sscanf(answer, "%d", &n); err != nil || n < 1 || n > len(coins) {
+		return "", errors.New(tr("InvalidChoice", "invalid choice %q", answer))
+	}
+	return coins[n-1].ID, nil
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// reloadMu is held for reading while serve answers a request and for
+// writing while the config is reloaded, so requests never see it half
+// applied.
+var reloadMu sync.RWMutex
+
+// reloadConfig re-reads the config file and applies it as on startup to
+// the flags not passed on the command line; settings removed from the file
+// go back to their defaults. The proxy and listen addresses keep their
+// startup values.
+func reloadConfig(cmd *cobra.Command) error {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			slice.Replace(values)
+			return
+		}
+		f.Value.Set(f.DefValue)
+	})
+	config = viper.New()
+	return configure(cmd, nil)
+}
+
+// hangups delivers SIGHUP until ctx is done.
+func hangups(ctx context.Context) <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		<-ctx.Done()
+		signal.Stop(ch)
+	}()
+	return ch
+}
+
+// logReload reloads the config and logs the outcome. A config that fails
+// to load leaves what could be applied of it in place.
+func logReload(cmd *cobra.Command) {
+	if err := reloadConfig(cmd); err != nil {
+		log.Printf("Reloading %s failed: %v", configPath(), err)
+		return
+	}
+	log.Printf("Reloaded %s", configPath())
+}
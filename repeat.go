@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const repeatBackoffBase = 15 * time.Second
+
+var (
+	repeatEvery time.Duration
+	appendPath  string
+)
+
+// quoteRow is the record appended for each coin on every round.
+type quoteRow struct {
+	Time     time.Time `json:"time"`
+	Coin     string    `json:"coin"`
+	Price    float64   `json:"price,omitempty"`
+	Currency string    `json:"currency"`
+	Source   string    `json:"source,omitempty"`
+	Duration float64   `json:"duration_ms,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+func newQuoteRow(at time.Time, q CoinQuote) quoteRow {
+	return quoteRow{
+		Time:     at.UTC(),
+		Coin:     q.Coin,
+		Price:    q.Price,
+		Currency: q.Currency,
+		Source:   q.Source,
+		Duration: float64(q.Duration.Microseconds()) / 1000,
+		Error:    q.Error,
+	}
+}
+
+// appendRows appends rows to path as CSV, or as JSON lines when the file
+// ends in .jsonl or .ndjson. A CSV header is written to new files. The file
+// is locked while writing, so runs that overlap, such as cron jobs, do not
+// interleave their rows.
+func appendRows(path string, rows []quoteRow) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		enc := json.NewEncoder(f)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := csv.NewWriter(f)
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		w.Write([]string{"time", "coin", "price", "currency", "source", "duration_ms", "error"})
+	}
+	for _, row := range rows {
+		price := ""
+		if row.Price > 0 {
+			price = strconv.FormatFloat(row.Price, 'f', -1, 64)
+		}
+		w.Write([]string{row.Time.Format(time.RFC3339), row.Coin, price, row.Currency, row.Source, strconv.FormatFloat(row.Duration, 'f', 3, 64), row.Error})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// runRepeat prices the coins every interval until interrupted. Failed
+// rounds are retried sooner with exponential backoff capped at the interval,
+// and interactive prompts are disabled so it can run headless under cron or
+// a service manager.
+func runRepeat(ctx context.Context, coins []string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	allowPrompt = false
+	noCache = true
+
+	failures := 0
+	for round := 0; ; round++ {
+		at := time.Now()
+		quotes := quoteCoins(ctx, coins, vsCurrencies)
+		if ctx.Err() != nil {
+			return nil
+		}
+		err := batchError(quotes)
+
+		if appendPath != "" {
+			rows := make([]quoteRow, len(quotes))
+			for i, q := range quotes {
+				rows[i] = newQuoteRow(at, q)
+			}
+			if werr := appendRows(appendPath, rows); werr != nil {
+				return fmt.Errorf("appending to %s: %w", appendPath, werr)
+			}
+		} else if quiet {
+			printQuiet(quotes)
+		} else if quoteTemplate != nil {
+			printFormatted(quotes)
+		} else if outputFormat == "statusbar" {
+			printStatusbar(ctx, quotes)
+		} else if outputFormat == "json" {
+			printJSON(quotes)
+		} else if outputFormat == "ndjson" {
+			printJSONLines(quotes)
+		} else if outputFormat == "csv" {
+			writeQuotesCSV(os.Stdout, quotes, round == 0)
+		} else {
+			printQuoteTable(quotes)
+		}
+
+		wait := repeatEvery
+		if err != nil {
+			failures++
+			wait = min(repeatEvery, repeatBackoffBase<<min(failures-1, 10))
+			if !quiet {
+				log.Print(tr("RetryError", "Error: %v (retrying in %s)", err, wait))
+			}
+		} else {
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
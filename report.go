@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSchedule string
+	reportNotify   []string
+)
+
+// buildReport compiles the summary text for the given coins: current
+// prices with their 24h change and the biggest movers among them.
+func buildReport(ctx context.Context, coins []string) (string, string, error) {
+	quotes := quoteCoins(ctx, coins, []string{"usd"})
+	ids := make([]string, 0, len(quotes))
+	for _, q := range quotes {
+		if q.err == nil {
+			ids = append(ids, q.Coin)
+		}
+	}
+	if len(ids) == 0 {
+		return "", "", batchError(quotes)
+	}
+	changes := make(map[string]float64)
+	if markets, err := fetchMarkets(ctx, ids); err == nil {
+		for _, m := range markets {
+			changes[m.ID] = m.PriceChange24h
+		}
+	}
+
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COIN\tPRICE\t24H")
+	for _, q := range quotes {
+		if q.err != nil {
+			fmt.Fprintf(w, "%s\t-\terror: %v\n", q.Coin, q.err)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t$%.2f\t%+.2f%%\n", q.Coin, q.Price, changes[q.Coin])
+	}
+	w.Flush()
+
+	movers := append([]string(nil), ids...)
+	sort.SliceStable(movers, func(i, j int) bool {
+		return math.Abs(changes[movers[i]]) > math.Abs(changes[movers[j]])
+	})
+	if len(movers) > 3 {
+		movers = movers[:3]
+	}
+	if len(changes) > 0 {
+		parts := make([]string, len(movers))
+		for i, id := range movers {
+			parts[i] = fmt.Sprintf("%s %+.2f%%", id, changes[id])
+		}
+		fmt.Fprintf(&b, "\nTop movers: %s\n", strings.Join(parts, ", "))
+	}
+	if p, err := loadPortfolio(); err == nil && len(p.Positions) > 0 {
+		v := valuePortfolio(ctx, p)
+		fmt.Fprintf(&b, "\nPortfolio value: %s", formatPrice(v.Value, v.Currency))
+		if v.CostBasis > 0 {
+			fmt.Fprintf(&b, ", P&L %s", formatPnL(v.PnL, v.PnLPercent, v.Currency))
+		}
+		b.WriteString("\n")
+	}
+
+	subject := "crypto-cli summary for " + time.Now().Format("2006-01-02")
+	return subject, b.String(), nil
+}
+
+func sendReport(ctx context.Context, coins []string, ns []notifier) error {
+	subject, body, err := buildReport(ctx, coins)
+	if err != nil {
+		return err
+	}
+	if len(ns) == 0 {
+		fmt.Printf("%s\n\n%s", subject, body)
+		return nil
+	}
+	if err := notifyAll(ns, message{Subject: subject, Body: body}); err != nil {
+		return err
+	}
+	names := make([]string, len(ns))
+	for i, n := range ns {
+		names[i] = n.name
+	}
+	fmt.Fprintf(os.Stderr, "Report sent via %s\n", strings.Join(names, ", "))
+	return nil
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report [coin...]",
+	Short: "Compile a price summary and deliver it through the configured notifiers",
+	Long: `Compile a summary of the given coins (or the default coins) with prices,
+24h changes, top movers and the portfolio's value. It is sent through every notifier configured in
+the config's "notifiers" section, or printed when none is configured.
+
+Run it once from cron, or pass --schedule with a cron expression to keep
+running and send a report on that schedule.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coins := args
+		if len(coins) == 0 {
+			coins = defaultCoins()
+		}
+		coins, err := expandWatchlists(coins)
+		if err != nil {
+			return err
+		}
+		if len(coins) == 0 {
+			return fmt.Errorf("no coins given and no default coins configured")
+		}
+		ns, err := selectNotifiers(reportNotify)
+		if err != nil {
+			return err
+		}
+		if reportSchedule == "" {
+			return sendReport(cmd.Context(), coins, ns)
+		}
+
+		schedule, err := cron.ParseStandard(reportSchedule)
+		if err != nil {
+			return fmt.Errorf("invalid --schedule: %w", err)
+		}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		allowPrompt = false
+		for {
+			next := schedule.Next(time.Now())
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Until(next)):
+			}
+			if err := sendReport(ctx, coins, ns); err != nil {
+				log.Print(tr("Error", "Error: %v", err))
+			}
+		}
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportSchedule, "schedule", "", `cron expression to send reports on, e.g. "0 8 * * *"`)
+	reportCmd.Flags().StringSliceVar(&reportNotify, "notify", nil, "notifiers to use: slack, telegram, email (default all configured)")
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryAfter is the longest Retry-After a request waits for; a provider
+// asking for more is treated as down for this run.
+const maxRetryAfter = time.Minute
+
+var (
+	retries      int
+	retryBackoff time.Duration
+)
+
+// retryTransport retries GET requests that fail with a network error, a
+// 429 or a 5xx response, waiting for the Retry-After the provider sent or
+// else an exponential backoff with jitter.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.retries || req.Method != http.MethodGet || req.Context().Err() != nil || !retryable(resp, err) {
+			return resp, err
+		}
+		wait := backoffDelay(t.backoff, attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp); ok {
+				if after > maxRetryAfter {
+					return resp, nil
+				}
+				wait = after
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		logger.Info("retrying request", "url", redactURL(req.URL), "attempt", attempt+1, "wait", wait.Round(time.Millisecond))
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// backoffDelay doubles the base delay with each attempt and picks a random
+// point in the upper half, so concurrent retries spread out.
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
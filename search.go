@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var searchLimit int
+
+// search ranks the coins matching query: an exact ID or symbol first, then
+// prefixes of the ID, symbol or name, then fuzzy matches and small typos.
+func (r *coinRegistry) search(query string, limit int) []Coin {
+	query = strings.ToLower(query)
+	type scored struct {
+		coin  Coin
+		score int
+	}
+	maxDistance := len(query)/3 + 1
+	var matches []scored
+	for _, c := range r.coins {
+		id, symbol, name := c.ID, strings.ToLower(c.Symbol), strings.ToLower(c.Name)
+		score := 0
+		switch {
+		case id == query || symbol == query:
+			score = 3000
+		case strings.HasPrefix(id, query) || strings.HasPrefix(symbol, query) || strings.HasPrefix(name, query):
+			score = 2000
+		default:
+			if s, ok := fuzzyScore(query, name); ok && len(query) > 2 {
+				score = 1000 + s
+			} else if s, ok := fuzzyScore(query, id); ok && len(query) > 2 {
+				score = 1000 + s
+			} else if d := levenshtein(query, id); d <= maxDistance {
+				score = 100 - d
+			}
+		}
+		if score > 0 {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].coin.ID) < len(matches[j].coin.ID)
+	})
+
+	var coins []Coin
+	for i := 0; i < len(matches) && i < limit; i++ {
+		coins = append(coins, matches[i].coin)
+	}
+	return coins
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find coins by ID, symbol or name",
+	Example: `  crypto-cli search btc
+  crypto-cli search "wrapped bitcoin" -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		registry, err := loadRegistry(false)
+		if err != nil {
+			return err
+		}
+		coins := registry.search(args[0], searchLimit)
+		switch outputFormat {
+		case "json":
+			if coins == nil {
+				coins = []Coin{}
+			}
+			return printJSON(coins)
+		case "csv":
+			w := csv.NewWriter(os.Stdout)
+			w.Write([]string{"id", "symbol", "name"})
+			for _, c := range coins {
+				w.Write([]string{c.ID, c.Symbol, c.Name})
+			}
+			w.Flush()
+			return w.Error()
+		}
+		if len(coins) == 0 {
+			return withExitCode(exitCoinNotFound, fmt.Errorf("no coins match %q", args[0]))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSYMBOL\tNAME")
+		for _, c := range coins {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.ID, strings.ToUpper(c.Symbol), c.Name)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "maximum number of coins to list")
+	rootCmd.AddCommand(searchCmd)
+}
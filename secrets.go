@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	secretsKeyFile   string
+	secretsRecipient string
+	secrets          map[string]string
+)
+
+// loadSecrets decrypts the config's age-encrypted "secrets" section, a YAML
+// map of provider name to API key, using the key file if one is configured
+// and an interactive passphrase prompt otherwise.
+func loadSecrets() error {
+	armored := config.GetString("secrets")
+	if armored == "" {
+		return nil
+	}
+	identities, err := secretIdentities()
+	if err != nil {
+		return err
+	}
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(armored)), identities...)
+	if err != nil {
+		return fmt.Errorf("decrypting config secrets: %w", err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("decrypting config secrets: %w", err)
+	}
+	return yaml.Unmarshal(data, &secrets)
+}
+
+func secretIdentities() ([]age.Identity, error) {
+	if secretsKeyFile != "" {
+		f, err := os.Open(secretsKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return age.ParseIdentities(f)
+	}
+	if !isTerminal(os.Stdin) {
+		return nil, errors.New("config secrets are encrypted, pass --secrets-key-file or run from a terminal to enter the passphrase")
+	}
+	passphrase, err := readSecret("Config passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Identity{identity}, nil
+}
+
+func secretRecipient() (age.Recipient, error) {
+	if secretsRecipient != "" {
+		return age.ParseX25519Recipient(secretsRecipient)
+	}
+	passphrase, err := readSecret("New passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	confirm, err := readSecret("Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" || passphrase != confirm {
+		return nil, errors.New("passphrases are empty or do not match")
+	}
+	return age.NewScryptRecipient(passphrase)
+}
+
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func writeConfigFile(path string, doc map[string]interface{}) error {
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+var configEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Move plaintext provider keys into an encrypted secrets section",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := configPath()
+		doc, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+
+		keys := make(map[string]string)
+		for name, key := range secrets {
+			keys[name] = key
+		}
+		providerSection, _ := doc["providers"].(map[string]interface{})
+		for name, block := range providerSection {
+			if settings, ok := block.(map[string]interface{}); ok {
+				if key, ok := settings["key"].(string); ok && key != "" {
+					keys[name] = key
+					delete(settings, "key")
+				}
+			}
+		}
+		if len(keys) == 0 {
+			return errors.New("no provider keys to encrypt")
+		}
+
+		recipient, err := secretRecipient()
+		if err != nil {
+			return err
+		}
+		plaintext, err := yaml.Marshal(keys)
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		armorWriter := armor.NewWriter(&out)
+		w, err := age.Encrypt(armorWriter, recipient)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(plaintext); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if err := armorWriter.Close(); err != nil {
+			return err
+		}
+
+		doc["secrets"] = out.String()
+		if err := writeConfigFile(path, doc); err != nil {
+			return err
+		}
+		fmt.Printf("Encrypted %d provider keys in %s\n", len(keys), path)
+		return nil
+	},
+}
+
+var configDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Write encrypted provider keys back to the config as plaintext",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(secrets) == 0 {
+			return errors.New("config has no encrypted secrets")
+		}
+		path := configPath()
+		doc, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+		providerSection, _ := doc["providers"].(map[string]interface{})
+		if providerSection == nil {
+			providerSection = make(map[string]interface{})
+			doc["providers"] = providerSection
+		}
+		for name, key := range secrets {
+			settings, _ := providerSection[name].(map[string]interface{})
+			if settings == nil {
+				settings = make(map[string]interface{})
+				providerSection[name] = settings
+			}
+			settings["key"] = key
+		}
+		delete(doc, "secrets")
+		return writeConfigFile(path, doc)
+	},
+}
+
+func init() {
+	configEncryptCmd.Flags().StringVar(&secretsRecipient, "recipient", "", "Encrypt to an age public key instead of a passphrase")
+	configCmd.AddCommand(configEncryptCmd, configDecryptCmd)
+}
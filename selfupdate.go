@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// releaseSigningKey is a base64 ed25519 public key set at build time with
+// -ldflags "-X main.releaseSigningKey=...". When present, self-update also
+// requires a valid checksums.txt.sig.
+var releaseSigningKey string
+
+var selfUpdateForce bool
+
+func releaseAssetName() string {
+	name := fmt.Sprintf("crypto-cli_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func (r githubRelease) assetURL(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.BrowserDownloadURL, true
+		}
+	}
+	return "", false
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient(5 * time.Minute).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// expectedChecksum finds the asset's SHA-256 in a sha256sum-style file.
+func expectedChecksum(checksums []byte, asset string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in checksums.txt", asset)
+}
+
+func verifySignature(ctx context.Context, release githubRelease, checksums []byte) error {
+	if releaseSigningKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(releaseSigningKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid embedded release signing key")
+	}
+	url, ok := release.assetURL("checksums.txt.sig")
+	if !ok {
+		return errors.New("release has no checksums.txt.sig")
+	}
+	sig, err := download(ctx, url)
+	if err != nil {
+		return err
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err == nil {
+		sig = decoded
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), checksums, sig) {
+		return errors.New("checksums.txt signature verification failed")
+	}
+	return nil
+}
+
+// replaceExecutable writes the new binary next to the running one and
+// renames it into place, so the swap is atomic on the same filesystem.
+func replaceExecutable(binary []byte) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".crypto-cli-update-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "windows" {
+		// A running executable can't be replaced on Windows, only renamed,
+		// so move it aside first and back if the new one can't take its
+		// place.
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return "", err
+		}
+		if err := os.Rename(tmp.Name(), exe); err != nil {
+			if restoreErr := os.Rename(old, exe); restoreErr != nil {
+				return "", fmt.Errorf("%w; restoring the previous version from %s also failed: %v", err, old, restoreErr)
+			}
+			return "", err
+		}
+		return exe, nil
+	}
+	return exe, os.Rename(tmp.Name(), exe)
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Download and install the latest release",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		current := buildVersion()
+		release, err := latestRelease(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("checking for updates: %w", err)
+		}
+		if !selfUpdateForce && (current == "dev" || compareVersions(current, release.TagName) >= 0) {
+			if current == "dev" {
+				return errors.New("refusing to replace a development build, use --force")
+			}
+			fmt.Printf("Already running the latest version (%s)\n", current)
+			return nil
+		}
+
+		asset := releaseAssetName()
+		binaryURL, ok := release.assetURL(asset)
+		if !ok {
+			return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+		}
+		checksumsURL, ok := release.assetURL("checksums.txt")
+		if !ok {
+			return fmt.Errorf("release %s has no checksums.txt", release.TagName)
+		}
+
+		checksums, err := download(cmd.Context(), checksumsURL)
+		if err != nil {
+			return err
+		}
+		if err := verifySignature(cmd.Context(), release, checksums); err != nil {
+			return err
+		}
+		if releaseSigningKey == "" {
+			fmt.Fprintln(os.Stderr, "Warning: this build has no release signing key, so the download is only checked against the release's own checksums.txt: that catches corruption but not a tampered release")
+		}
+		want, err := expectedChecksum(checksums, asset)
+		if err != nil {
+			return err
+		}
+		binary, err := download(cmd.Context(), binaryURL)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(binary)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("checksum mismatch for %s: got %s, want %s", asset, got, want)
+		}
+
+		path, err := replaceExecutable(binary)
+		if err != nil {
+			return fmt.Errorf("installing update: %w", err)
+		}
+		fmt.Printf("Updated %s from %s to %s\n", path, current, release.TagName)
+		return nil
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateForce, "force", false, "Reinstall even if already up to date or running a development build")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	alternativeBaseURL = "https://api.alternative.me"
+
+	fearGreedAPI = "/fng/?limit=%d"
+)
+
+var sentimentDays int
+
+type FearGreedPoint struct {
+	Value          int       `json:"value"`
+	Classification string    `json:"classification"`
+	Time           time.Time `json:"time"`
+}
+
+// FearGreedReport is the Crypto Fear & Greed Index, from 0 (extreme fear)
+// to 100 (extreme greed), with its history newest first.
+type FearGreedReport struct {
+	Current    FearGreedPoint   `json:"current"`
+	NextUpdate time.Time        `json:"next_update,omitempty"`
+	History    []FearGreedPoint `json:"history"`
+}
+
+func fetchFearGreed(ctx context.Context, days int) (FearGreedReport, error) {
+	var resp struct {
+		Data []struct {
+			Value          string `json:"value"`
+			Classification string `json:"value_classification"`
+			Timestamp      string `json:"timestamp"`
+			UntilUpdate    string `json:"time_until_update"`
+		} `json:"data"`
+		Metadata struct {
+			Error *string `json:"error"`
+		} `json:"metadata"`
+	}
+	var report FearGreedReport
+	if err := getJSON(ctx, providerURL("alternative")+fmt.Sprintf(fearGreedAPI, days), "alternative", &resp); err != nil {
+		return report, err
+	}
+	if resp.Metadata.Error != nil {
+		return report, fmt.Errorf("alternative: %s", *resp.Metadata.Error)
+	}
+	if len(resp.Data) == 0 {
+		return report, fmt.Errorf("alternative: no index values")
+	}
+	for _, d := range resp.Data {
+		value, _ := strconv.Atoi(d.Value)
+		ts, _ := strconv.ParseInt(d.Timestamp, 10, 64)
+		report.History = append(report.History, FearGreedPoint{value, d.Classification, time.Unix(ts, 0).UTC()})
+	}
+	report.Current = report.History[0]
+	if secs, err := strconv.Atoi(resp.Data[0].UntilUpdate); err == nil {
+		report.NextUpdate = time.Now().Add(time.Duration(secs) * time.Second).UTC().Truncate(time.Second)
+	}
+	return report, nil
+}
+
+// paintFearGreed colors fear red and greed green, leaving neutral plain.
+func paintFearGreed(s string, value int, color bool) string {
+	switch {
+	case value < 45:
+		return paint(s, "31", color)
+	case value > 55:
+		return paint(s, "32", color)
+	}
+	return s
+}
+
+// fearGreedSparkline draws the history oldest first on the index's fixed
+// 0-100 scale, so the height of a bar means the same on every run.
+func fearGreedSparkline(history []FearGreedPoint) string {
+	var b strings.Builder
+	for i := len(history) - 1; i >= 0; i-- {
+		b.WriteRune(sparkBlocks[min(history[i].Value, 100)*(len(sparkBlocks)-1)/100])
+	}
+	return b.String()
+}
+
+// fearGreedAgo returns the value closest to days before the current one.
+func fearGreedAgo(report FearGreedReport, days int) (FearGreedPoint, bool) {
+	if days >= len(report.History) {
+		return FearGreedPoint{}, false
+	}
+	return report.History[days], true
+}
+
+func printFearGreed(report FearGreedReport) {
+	color := useColor(os.Stdout)
+	label := func(p FearGreedPoint) string {
+		return paintFearGreed(fmt.Sprintf("%d (%s)", p.Value, p.Classification), p.Value, color)
+	}
+	fmt.Printf("Crypto Fear & Greed Index: %s\n", label(report.Current))
+
+	var past []string
+	for _, ago := range []struct {
+		days int
+		name string
+	}{{1, "yesterday"}, {7, "last week"}, {30, "last month"}} {
+		if p, ok := fearGreedAgo(report, ago.days); ok {
+			past = append(past, ago.name+" "+label(p))
+		}
+	}
+	if len(past) > 0 {
+		fmt.Printf("  %s\n", strings.Join(past, ", "))
+	}
+
+	if len(report.History) > 1 {
+		oldest := report.History[len(report.History)-1]
+		if accessible {
+			low, high := report.Current, report.Current
+			for _, p := range report.History {
+				if p.Value < low.Value {
+					low = p
+				}
+				if p.Value > high.Value {
+					high = p
+				}
+			}
+			fmt.Printf("  %d days: from %d to %d, low %d on %s, high %d on %s\n", len(report.History),
+				oldest.Value, report.Current.Value, low.Value, low.Time.Format(time.DateOnly), high.Value, high.Time.Format(time.DateOnly))
+		} else {
+			fmt.Printf("  %dd %s\n", len(report.History), fearGreedSparkline(report.History))
+		}
+	}
+	if !report.NextUpdate.IsZero() {
+		fmt.Println(dim(fmt.Sprintf("  Next update in %s", time.Until(report.NextUpdate).Round(time.Minute)), color))
+	}
+}
+
+var sentimentCmd = &cobra.Command{
+	Use:   "sentiment",
+	Short: "Show the Crypto Fear & Greed Index and its recent history",
+	Long: `Show the Crypto Fear & Greed Index from alternative.me: a daily market
+sentiment score from 0 (extreme fear) to 100 (extreme greed), with the value
+a day, a week and a month ago and a sparkline of the last --days days.`,
+	Example: `  crypto-cli sentiment
+  crypto-cli sentiment --days 90 -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if sentimentDays < 1 {
+			return fmt.Errorf("--days must be at least 1, got %d", sentimentDays)
+		}
+		report, err := fetchFearGreed(cmd.Context(), sentimentDays)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+		printFearGreed(report)
+		return nil
+	},
+}
+
+func init() {
+	sentimentCmd.Flags().IntVar(&sentimentDays, "days", 30, "days of history to show")
+	rootCmd.AddCommand(sentimentCmd)
+}
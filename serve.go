@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen string
+	serveGRPC   string
+)
+
+type apiError struct {
+	Error          string          `json:"error"`
+	ProviderErrors []ProviderError `json:"provider_errors,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+// httpStatus maps a quote failure to a status code by its exit code.
+func httpStatus(err error) int {
+	switch exitCode(err) {
+	case exitCoinNotFound:
+		return http.StatusNotFound
+	case exitRateLimited:
+		return http.StatusTooManyRequests
+	case exitAllProvidersFailed, exitStaleOnly:
+		return http.StatusBadGateway
+	}
+	return http.StatusBadRequest
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, httpStatus(err), apiError{err.Error(), providerErrors(err)})
+}
+
+// queryList splits a comma-separated query parameter, also accepting it
+// repeated.
+func queryList(r *http.Request, name string) []string {
+	var values []string
+	for _, v := range r.URL.Query()[name] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.ToLower(strings.TrimSpace(s)); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// queryCurrencies returns the ?vs= currencies, USD by default, answering
+// 400 when one is not a currency code.
+func queryCurrencies(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	currencies, err := parseCurrencies(queryList(r, "vs"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return nil, false
+	}
+	return currencies, true
+}
+
+func handlePrice(w http.ResponseWriter, r *http.Request) {
+	currencies, ok := queryCurrencies(w, r)
+	if !ok {
+		return
+	}
+	currency := currencies[0]
+	coin, err := resolveCoin(r.Context(), r.PathValue("coin"), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	q := quoteCoin(r.Context(), coin, currency)
+	if q.err != nil {
+		writeError(w, q.err)
+		return
+	}
+	writeJSON(w, http.StatusOK, q)
+}
+
+// handlePrices answers with every quote, failed ones carrying an error,
+// and only fails as a whole when no coins were asked for.
+func handlePrices(w http.ResponseWriter, r *http.Request) {
+	ids := queryList(r, "ids")
+	if len(ids) == 0 {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "missing ids, e.g. /prices?ids=bitcoin,ethereum&vs=usd"})
+		return
+	}
+	currencies, ok := queryCurrencies(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, quoteCoins(r.Context(), ids, currencies))
+}
+
+// readLocked keeps the config from being reloaded while a request is
+// answered.
+func readLocked(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reloadMu.RLock()
+		defer reloadMu.RUnlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func serveMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", handleDashboard)
+	mux.HandleFunc("GET /price/{coin}", handlePrice)
+	mux.HandleFunc("GET /prices", handlePrices)
+	mux.HandleFunc("GET /history/{coin}", handleHistory)
+	mux.HandleFunc("GET /health", handleHealth)
+	mux.HandleFunc("GET /metrics", handleMetrics)
+	return mux
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP or gRPC server answering price requests",
+	Long: `Run a long-lived HTTP server so other tools can share one process, its
+quote cache and its provider rate limits instead of each calling the
+public APIs. Endpoints:
+
+  GET /                              a read-only dashboard of the --dashboard
+                                     coins (or ?ids=btc,eth&vs=eur) with
+                                     sparklines and provider health
+  GET /price/{coin}?vs=usd           one quote, 404 for an unknown coin
+  GET /prices?ids=bitcoin,eth&vs=usd  a JSON array of quotes
+  GET /history/{coin}?vs=usd&hours=24
+                                     the prices crypto-cli record stored in
+                                     --db over the last hours
+  GET /health                        each provider's requests, success rate
+                                     and latency since the server started
+  GET /metrics                       Prometheus metrics: the last price from
+                                     each provider, provider latency and errors
+
+With --grpc, the cryptocli.v1.PriceService gRPC API is served as well, with
+GetPrice, GetPrices and the server-streaming StreamPrices. Its protobuf
+definitions are in api/price.proto; the server supports reflection, so
+grpcurl works without them. --listen "" serves gRPC alone.
+
+Quotes use the same providers, aggregation and --cache-ttl as the command
+line. SIGHUP reloads the config file, e.g. new provider keys, without
+dropping connections; SIGINT and SIGTERM finish the requests in flight and
+exit.`,
+	Example: `  crypto-cli serve --listen :8080
+  curl 'localhost:8080/price/btc?vs=eur'
+  crypto-cli serve --grpc :9090
+  grpcurl -plaintext -d '{"coins":["btc"],"interval":"30s"}' localhost:9090 cryptocli.v1.PriceService/StreamPrices`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serveListen == "" && serveGRPC == "" {
+			return errors.New("nothing to serve: set --listen, --grpc or both")
+		}
+		if serveDashboardRefresh < time.Second {
+			return errors.New("--dashboard-refresh must be at least 1s")
+		}
+		allowPrompt = false
+		metricsEnabled = true
+		coins, err := resolveCoinList(cmd.Context(), serveDashboardCoins)
+		if err != nil {
+			return fmt.Errorf("--dashboard: %w", err)
+		}
+		dashboardCoins = coins
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			hup := hangups(ctx)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-hup:
+					reloadMu.Lock()
+					logReload(cmd)
+					reloadMu.Unlock()
+				}
+			}
+		}()
+
+		errs := make(chan error, 2)
+		servers := 0
+		if serveListen != "" {
+			server := &http.Server{
+				Addr:              serveListen,
+				Handler:           readLocked(serveMux()),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			go func() {
+				<-ctx.Done()
+				shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(shutdown)
+			}()
+			servers++
+			go func() {
+				log.Printf("Listening on %s", serveListen)
+				if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+					errs <- err
+					return
+				}
+				errs <- nil
+			}()
+		}
+		if serveGRPC != "" {
+			lis, err := net.Listen("tcp", serveGRPC)
+			if err != nil {
+				stop()
+				return err
+			}
+			server := newGRPCServer()
+			go func() {
+				<-ctx.Done()
+				// Streams only end when their clients go away, so don't wait
+				// on them for long.
+				timer := time.AfterFunc(5*time.Second, server.Stop)
+				defer timer.Stop()
+				server.GracefulStop()
+			}()
+			servers++
+			go func() {
+				log.Printf("Serving gRPC on %s", lis.Addr())
+				errs <- server.Serve(lis)
+			}()
+		}
+
+		// The first server to fail stops the other.
+		var first error
+		for ; servers > 0; servers-- {
+			if err := <-errs; err != nil && first == nil {
+				first = err
+				stop()
+			}
+		}
+		return first
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListen, "listen", "localhost:8080", "address to listen on, e.g. :8080 for all interfaces; empty to serve gRPC only")
+	serveCmd.Flags().StringVar(&serveGRPC, "grpc", "", "address to serve the gRPC API on, e.g. :9090; off when empty")
+	serveCmd.Flags().StringSliceVar(&serveDashboardCoins, "dashboard", []string{"bitcoin", "ethereum"}, "coins or @watchlists the dashboard at / shows")
+	serveCmd.Flags().StringVar(&serveDashboardCurrency, "dashboard-currency", "usd", "currency the dashboard shows prices in")
+	serveCmd.Flags().DurationVar(&serveDashboardRefresh, "dashboard-refresh", 30*time.Second, "how often the dashboard refreshes")
+	serveCmd.Flags().StringVar(&recordDB, "db", "", "SQLite database with prices recorded by crypto-cli record, for /history (default in the data dir)")
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceMode     string
+	serviceListen   string
+	serviceInterval time.Duration
+	serviceSystem   bool
+	serviceDryRun   bool
+)
+
+// serviceName names the systemd unit, and with a prefix the launchd job,
+// after the mode, so a server and a recorder can be installed side by side.
+func serviceName() string {
+	return "crypto-cli-" + serviceMode
+}
+
+// serviceArgs is the command line the service runs: this executable in
+// --mode, with the config file and profile in use now.
+func serviceArgs(coins []string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	args := []string{exe}
+	switch serviceMode {
+	case "serve":
+		if len(coins) > 0 {
+			return nil, fmt.Errorf("serve takes no coins")
+		}
+		args = append(args, "serve", "--listen", serviceListen)
+	case "record":
+		if len(coins) == 0 {
+			return nil, fmt.Errorf("name the coins to record, e.g. crypto-cli service install --mode record bitcoin ethereum")
+		}
+		args = append(append(args, "record"), coins...)
+		args = append(args, "--interval", serviceInterval.String())
+	default:
+		return nil, fmt.Errorf("unknown --mode %q: use serve or record", serviceMode)
+	}
+	if path, err := filepath.Abs(configPath()); err == nil {
+		if _, err := os.Stat(path); err == nil {
+			args = append(args, "--config", path)
+		}
+	}
+	if profileName != "" {
+		args = append(args, "--profile", profileName)
+	}
+	return args, nil
+}
+
+// systemdQuote quotes an ExecStart argument, escaping what systemd would
+// otherwise expand.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(arg)
+	if strings.ContainsAny(arg, " \t'") || arg == "" {
+		return `"` + arg + `"`
+	}
+	return arg
+}
+
+func systemdUnit(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg)
+	}
+	target := "default.target"
+	if serviceSystem {
+		target = "multi-user.target"
+	}
+	return fmt.Sprintf(`[Unit]
+Description=crypto-cli %s
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+ExecStart=%s
+ExecReload=/bin/kill -HUP $MAINPID
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=%s
+`, serviceMode, strings.Join(quoted, " "), target)
+}
+
+func launchdPlist(label string, args []string) string {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	str := func(key, value string) {
+		fmt.Fprintf(&b, "\t<key>%s</key>\n\t<string>", key)
+		xml.EscapeText(&b, []byte(value))
+		b.WriteString("</string>\n")
+	}
+	str("Label", label)
+	b.WriteString("\t<key>ProgramArguments</key>\n\t<array>\n")
+	for _, arg := range args {
+		b.WriteString("\t\t<string>")
+		xml.EscapeText(&b, []byte(arg))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<dict>\n\t\t<key>SuccessfulExit</key>\n\t\t<false/>\n\t</dict>\n")
+	log := filepath.Join(dataDir(), serviceName()+".log")
+	str("StandardOutPath", log)
+	str("StandardErrorPath", log)
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// serviceFile returns where the unit or plist goes and the commands that
+// enable and disable it.
+func serviceFile() (path string, enable, disable [][]string, err error) {
+	switch runtime.GOOS {
+	case "linux":
+		unit := serviceName() + ".service"
+		dir := "/etc/systemd/system"
+		systemctl := []string{"systemctl"}
+		if !serviceSystem {
+			home, err := os.UserConfigDir()
+			if err != nil {
+				return "", nil, nil, err
+			}
+			dir = filepath.Join(home, "systemd", "user")
+			systemctl = append(systemctl, "--user")
+		}
+		cmd := func(args ...string) []string { return append(append([]string{}, systemctl...), args...) }
+		return filepath.Join(dir, unit),
+			[][]string{cmd("daemon-reload"), cmd("enable", "--now", unit)},
+			[][]string{cmd("disable", "--now", unit), cmd("daemon-reload")}, nil
+	case "darwin":
+		dir := "/Library/LaunchDaemons"
+		if !serviceSystem {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", nil, nil, err
+			}
+			dir = filepath.Join(home, "Library", "LaunchAgents")
+		}
+		path := filepath.Join(dir, "io."+serviceName()+".plist")
+		return path, [][]string{{"launchctl", "load", "-w", path}}, [][]string{{"launchctl", "unload", "-w", path}}, nil
+	}
+	return "", nil, nil, fmt.Errorf("installing a service is not supported on %s; run crypto-cli %s under your service manager", runtime.GOOS, serviceMode)
+}
+
+func runServiceCommands(cmds [][]string) error {
+	for _, args := range cmds {
+		if serviceDryRun {
+			fmt.Println(strings.Join(args, " "))
+			continue
+		}
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			if len(out) > 0 {
+				return fmt.Errorf("%s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+			}
+			return fmt.Errorf("%s: %v", strings.Join(args, " "), err)
+		}
+	}
+	return nil
+}
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run serve or record permanently under systemd or launchd",
+	Long: `Install crypto-cli serve or record as a service that starts at login (or
+boot with --system) and restarts if it fails: a systemd unit on Linux, a
+launchd job on macOS. It runs this executable with the config file and
+--profile in use when installing. Reloading the service sends SIGHUP,
+which rereads the config file.`,
+	Example: `  crypto-cli service install --mode serve --listen :8080
+  crypto-cli service install --mode record bitcoin ethereum --interval 5m
+  crypto-cli service uninstall --mode serve`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install [coin...]",
+	Short: "Write and enable the service",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serviceInterval < time.Second {
+			return errors.New(tr("IntervalTooShort", "--interval must be at least %s", time.Second))
+		}
+		command, err := serviceArgs(args)
+		if err != nil {
+			return err
+		}
+		path, enable, _, err := serviceFile()
+		if err != nil {
+			return err
+		}
+		content := systemdUnit(command)
+		if runtime.GOOS == "darwin" {
+			content = launchdPlist(strings.TrimSuffix(filepath.Base(path), ".plist"), command)
+		}
+		if serviceDryRun {
+			fmt.Printf("# %s\n%s\n", path, content)
+			return runServiceCommands(enable)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dataDir(), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+		if err := runServiceCommands(enable); err != nil {
+			return fmt.Errorf("wrote %s but could not enable it: %w", path, err)
+		}
+		fmt.Printf("Installed and started %s (%s)\n", serviceName(), path)
+		return nil
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the service",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if serviceMode != "serve" && serviceMode != "record" {
+			return fmt.Errorf("unknown --mode %q: use serve or record", serviceMode)
+		}
+		path, _, disable, err := serviceFile()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil && !serviceDryRun {
+			return fmt.Errorf("%s is not installed: %w", serviceName(), err)
+		}
+		if err := runServiceCommands(disable); err != nil {
+			return err
+		}
+		if serviceDryRun {
+			fmt.Printf("rm %s\n", path)
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s (%s)\n", serviceName(), path)
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{serviceInstallCmd, serviceUninstallCmd} {
+		c.Flags().StringVar(&serviceMode, "mode", "serve", "what the service runs: serve or record")
+		c.Flags().BoolVar(&serviceSystem, "system", false, "install for the whole system, started at boot (needs root)")
+		c.Flags().BoolVar(&serviceDryRun, "dry-run", false, "print the service file and commands instead of running them")
+	}
+	serviceInstallCmd.Flags().StringVar(&serviceListen, "listen", "localhost:8080", "address for serve to listen on")
+	serviceInstallCmd.Flags().DurationVar(&serviceInterval, "interval", time.Minute, "time between samples for record")
+	serviceCmd.AddCommand(serviceInstallCmd, serviceUninstallCmd)
+	rootCmd.AddCommand(serviceCmd)
+}
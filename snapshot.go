@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var snapshotAppend string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot <coin...> --append <file>",
+	Short: "Fetch prices once and append them to a CSV or JSON lines log, for cron",
+	Long: `Fetch the coins' prices once and append a timestamped row for each to a
+log file: CSV, or JSON lines when the file ends in .jsonl or .ndjson, in
+the same format as --every --append. A CSV header is written to new files.
+
+Meant for cron: nothing is printed on success, prompts are disabled, and
+the quote cache is bypassed. Coins that fail still get a row with the
+error, which is also printed to stderr, and the exit code says why (see
+crypto-cli --help). The file is locked while rows are appended, so
+overlapping runs wait for each other rather than interleave.`,
+	Example: `  crypto-cli snapshot btc eth --append ~/prices.csv
+  */5 * * * * crypto-cli snapshot bitcoin ethereum -c usd,eur --append "$HOME/prices.jsonl"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coins, err := expandWatchlists(args)
+		if err != nil {
+			return err
+		}
+		if lockTimeout < 0 {
+			return fmt.Errorf("--lock-timeout must not be negative, got %s", lockTimeout)
+		}
+		if err := normalizeCurrencies(); err != nil {
+			return err
+		}
+		if offline {
+			return errOffline
+		}
+		allowPrompt = false
+		noCache = true
+
+		at := time.Now()
+		quotes := quoteCoins(cmd.Context(), coins, vsCurrencies)
+		rows := make([]quoteRow, len(quotes))
+		for i, q := range quotes {
+			rows[i] = newQuoteRow(at, q)
+			if q.err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", q.Coin, q.err)
+			}
+		}
+		if err := appendRows(snapshotAppend, rows); err != nil {
+			return withExitCode(exitGeneric, fmt.Errorf("appending to %s: %w", snapshotAppend, err))
+		}
+		if err := batchError(quotes); err != nil {
+			return exitSilently(exitCode(err))
+		}
+		return nil
+	},
+}
+
+func init() {
+	snapshotCmd.Flags().StringVar(&snapshotAppend, "append", "", "log file to append to (.csv, or .jsonl/.ndjson for JSON lines)")
+	snapshotCmd.Flags().StringSliceVarP(&vsCurrencies, "vs-currency", "c", []string{"usd"}, "currencies to quote in, e.g. usd,eur")
+	snapshotCmd.Flags().DurationVar(&lockTimeout, "lock-timeout", lockTimeout, "how long to wait for another run to finish writing the file")
+	snapshotCmd.MarkFlagRequired("append")
+	snapshotCmd.MarkFlagFilename("append", "csv", "jsonl", "ndjson")
+	rootCmd.AddCommand(snapshotCmd)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	lunarcrushBaseURL = "https://lunarcrush.com/api4"
+	lunarcrushCoinAPI = "/public/coins/%s/v1"
+)
+
+type SocialStats struct {
+	Coin            string  `json:"coin"`
+	Price           float64 `json:"price,omitempty"`
+	PriceChange24h  float64 `json:"price_change_24h_pct"`
+	Interactions24h float64 `json:"interactions_24h"`
+	SocialVolume24h float64 `json:"social_volume_24h"`
+	SocialDominance float64 `json:"social_dominance_pct"`
+	Sentiment       float64 `json:"sentiment_pct"`
+	GalaxyScore     float64 `json:"galaxy_score"`
+	AltRank         int     `json:"alt_rank"`
+}
+
+func fetchSocial(ctx context.Context, coin string) (SocialStats, error) {
+	stats := SocialStats{Coin: coin}
+	if providerKey("lunarcrush") == "" {
+		return stats, fmt.Errorf("lunarcrush: no API key configured")
+	}
+	var resp struct {
+		Error string `json:"error"`
+		Data  struct {
+			PercentChange24h float64 `json:"percent_change_24h"`
+			Interactions24h  float64 `json:"interactions_24h"`
+			SocialVolume24h  float64 `json:"social_volume_24h"`
+			SocialDominance  float64 `json:"social_dominance"`
+			Sentiment        float64 `json:"sentiment"`
+			GalaxyScore      float64 `json:"galaxy_score"`
+			AltRank          int     `json:"alt_rank"`
+		} `json:"data"`
+	}
+	symbol := strings.ToLower(coinSymbol(coin))
+	if err := getJSON(ctx, providerURL("lunarcrush")+fmt.Sprintf(lunarcrushCoinAPI, url.PathEscape(symbol)), "lunarcrush", &resp); err != nil {
+		return stats, err
+	}
+	if resp.Error != "" {
+		return stats, fmt.Errorf("lunarcrush: %s", resp.Error)
+	}
+	d := resp.Data
+	stats.PriceChange24h = d.PercentChange24h
+	stats.Interactions24h = d.Interactions24h
+	stats.SocialVolume24h = d.SocialVolume24h
+	stats.SocialDominance = d.SocialDominance
+	stats.Sentiment = d.Sentiment
+	stats.GalaxyScore = d.GalaxyScore
+	stats.AltRank = d.AltRank
+	return stats, nil
+}
+
+var socialCmd = &cobra.Command{
+	Use:   "social <coin>",
+	Short: "Show social volume and sentiment next to the price",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		stats, err := fetchSocial(cmd.Context(), coin)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		if q := quoteCoin(cmd.Context(), coin, "usd"); q.err == nil {
+			stats.Price = q.Price
+		}
+		if outputFormat == "json" {
+			return printJSON(stats)
+		}
+
+		if stats.Price > 0 {
+			fmt.Printf("Price:            $%.2f (%+.2f%% 24h)\n", stats.Price, stats.PriceChange24h)
+		}
+		fmt.Printf("Social volume:    %.0f posts (24h)\n", stats.SocialVolume24h)
+		fmt.Printf("Interactions:     %.0f (24h)\n", stats.Interactions24h)
+		fmt.Printf("Social dominance: %.2f%%\n", stats.SocialDominance)
+		fmt.Printf("Sentiment:        %.0f%% positive\n", stats.Sentiment)
+		fmt.Printf("Galaxy score:     %.1f (AltRank %d)\n", stats.GalaxyScore, stats.AltRank)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(socialCmd)
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	spreadCurrency  string
+	spreadThreshold float64
+)
+
+type spreadSource struct {
+	Source    string  `json:"source"`
+	Price     float64 `json:"price,omitempty"`
+	Deviation float64 `json:"deviation_pct,omitempty"`
+	Duration  float64 `json:"duration_ms"`
+	Divergent bool    `json:"divergent,omitempty"`
+	Stale     bool    `json:"stale,omitempty"`
+	Error     string  `json:"error,omitempty"`
+
+	duration time.Duration
+}
+
+// SpreadReport compares every provider's price for one coin. Spread is the
+// gap between the highest and lowest price as a percentage of the lowest,
+// which is also the gross margin of buying at one source and selling at
+// the other.
+type SpreadReport struct {
+	Coin      string         `json:"coin"`
+	Currency  string         `json:"currency"`
+	Min       float64        `json:"min,omitempty"`
+	MinSource string         `json:"min_source,omitempty"`
+	Max       float64        `json:"max,omitempty"`
+	MaxSource string         `json:"max_source,omitempty"`
+	Median    float64        `json:"median,omitempty"`
+	Spread    float64        `json:"spread_pct"`
+	Threshold float64        `json:"threshold_pct"`
+	Divergent bool           `json:"divergent"`
+	Sources   []spreadSource `json:"sources"`
+}
+
+func spreadReport(coin, currency string, results []pricefeed.Result) SpreadReport {
+	report := SpreadReport{Coin: coin, Currency: currency, Threshold: spreadThreshold}
+	var prices []float64
+	for _, r := range results {
+		if !r.Usable() {
+			continue
+		}
+		prices = append(prices, r.Price)
+		if report.Min == 0 || r.Price < report.Min {
+			report.Min, report.MinSource = r.Price, r.Source
+		}
+		if r.Price > report.Max {
+			report.Max, report.MaxSource = r.Price, r.Source
+		}
+	}
+	if len(prices) > 0 {
+		sort.Float64s(prices)
+		report.Median = prices[len(prices)/2]
+		if len(prices)%2 == 0 {
+			report.Median = (prices[len(prices)/2-1] + prices[len(prices)/2]) / 2
+		}
+		report.Spread = (report.Max - report.Min) / report.Min * 100
+		report.Divergent = report.Spread > spreadThreshold
+	}
+
+	for _, r := range results {
+		s := spreadSource{Source: r.Source, Duration: milliseconds(r.Duration), Stale: r.Stale, Error: r.Error, duration: r.Duration}
+		if r.Price > 0 {
+			s.Price = r.Price
+		}
+		if r.Usable() {
+			s.Deviation = (r.Price - report.Median) / report.Median * 100
+			s.Divergent = len(prices) > 1 && pricefeed.Deviation(r.Price, report.Median) > spreadThreshold
+		}
+		report.Sources = append(report.Sources, s)
+	}
+	sort.SliceStable(report.Sources, func(i, j int) bool { return report.Sources[i].Price > report.Sources[j].Price })
+	return report
+}
+
+func printSpreadReport(report SpreadReport) {
+	color := useColor(os.Stdout)
+	fmt.Printf("%s (%s)\n", report.Coin, strings.ToUpper(report.Currency))
+	if report.Max == 0 {
+		fmt.Println("  No usable prices.")
+	} else {
+		spread := fmt.Sprintf("%.2f%%", report.Spread)
+		if report.Divergent {
+			spread = paint(spread+" above the "+fmt.Sprintf("%.2f%%", report.Threshold)+" threshold", "31", color)
+		}
+		fmt.Printf("  Min %s (%s), max %s (%s), median %s, spread %s\n",
+			formatPrice(report.Min, report.Currency), report.MinSource,
+			formatPrice(report.Max, report.Currency), report.MaxSource,
+			formatPrice(report.Median, report.Currency), spread)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, paintHeader("  SOURCE\tPRICE\tVS MEDIAN\tDURATION\tSTATUS", color, 2, 3))
+	for _, s := range report.Sources {
+		price, diff := "-", paint("-", "39", color)
+		if s.Price > 0 {
+			price = formatPrice(s.Price, report.Currency)
+		}
+		status := paint("ok", "32", color)
+		switch {
+		case s.Error != "":
+			status = paint(s.Error, "31", color)
+		case s.Stale:
+			status = paint("stale", "33", color)
+		default:
+			diff = colored(fmt.Sprintf("%+.2f%%", s.Deviation), s.Deviation >= 0, color)
+			if s.Divergent {
+				status = paint("divergent", "33", color)
+			}
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", s.Source, price, diff, dim(s.duration.Round(time.Microsecond).String(), color), status)
+	}
+	w.Flush()
+}
+
+var spreadCmd = &cobra.Command{
+	Use:   "spread <coin...>",
+	Short: "Compare a coin's price across every provider and report the spread",
+	Long: `Query every configured provider at once and report the lowest and highest
+price, the spread between them and each source's deviation from the median.
+Sources further from the median than --threshold are flagged as divergent,
+which usually means a stale feed or an arbitrage gap.`,
+	Example: `  crypto-cli spread bitcoin
+  crypto-cli spread btc eth -c eur --threshold 0.5 -o json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		args, err := expandWatchlists(args)
+		if err != nil {
+			return err
+		}
+		currency := strings.ToLower(spreadCurrency)
+		var reports []SpreadReport
+		usable := 0
+		for _, arg := range args {
+			id, err := resolveCoin(cmd.Context(), arg, exactID)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			if requestTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+				defer cancel()
+			}
+			report := spreadReport(id, currency, priceClient(id).All(ctx, id, currency))
+			if report.Max > 0 {
+				usable++
+			}
+			reports = append(reports, report)
+		}
+
+		if outputFormat == "json" {
+			if err := printJSON(reports); err != nil {
+				return err
+			}
+		} else {
+			for i, report := range reports {
+				if i > 0 {
+					fmt.Println()
+				}
+				printSpreadReport(report)
+			}
+		}
+		if usable < len(reports) {
+			return withExitCode(exitAllProvidersFailed, fmt.Errorf("no provider returned a usable price for %d of %d coins", len(reports)-usable, len(reports)))
+		}
+		return nil
+	},
+}
+
+func init() {
+	spreadCmd.Flags().StringVarP(&spreadCurrency, "vs-currency", "c", "usd", "currency to compare prices in")
+	spreadCmd.Flags().Float64Var(&spreadThreshold, "threshold", 2, "flag sources more than this percentage from the median, and spreads above it")
+	spreadCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat arguments as CoinGecko coin IDs and skip symbol resolution")
+	rootCmd.AddCommand(spreadCmd)
+}
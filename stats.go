@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+const statsRetention = 90 * 24 * time.Hour
+
+type dayStats struct {
+	Successes int           `json:"successes"`
+	Failures  int           `json:"failures"`
+	Latency   time.Duration `json:"latency_ns"`
+}
+
+func (d *dayStats) add(o dayStats) {
+	d.Successes += o.Successes
+	d.Failures += o.Failures
+	d.Latency += o.Latency
+}
+
+type providerStats struct {
+	Days        map[string]*dayStats `json:"days"`
+	LastError   string               `json:"last_error,omitempty"`
+	LastErrorAt time.Time            `json:"last_error_at,omitempty"`
+}
+
+var (
+	statsMu      sync.Mutex
+	pendingStats = make(map[string]*providerStats)
+
+	statsDays   int
+	demoteBelow float64
+)
+
+func statsPath() string {
+	return filepath.Join(dataDir(), "provider-stats.json")
+}
+
+func providerName(label string) string {
+	for _, p := range providers {
+		if p.label == label {
+			return p.name
+		}
+	}
+	return label
+}
+
+// recordProviderResult counts a provider response towards today's stats.
+// A response with a price counts as a success even if it turns out stale.
+func recordProviderResult(r pricefeed.Result) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	name := providerName(r.Source)
+	ps := pendingStats[name]
+	if ps == nil {
+		ps = &providerStats{Days: make(map[string]*dayStats)}
+		pendingStats[name] = ps
+	}
+	day := time.Now().UTC().Format(time.DateOnly)
+	d := ps.Days[day]
+	if d == nil {
+		d = &dayStats{}
+		ps.Days[day] = d
+	}
+	if r.Price > 0 {
+		d.Successes++
+		d.Latency += r.Duration
+		return
+	}
+	d.Failures++
+	ps.LastError, ps.LastErrorAt = r.Error, time.Now()
+}
+
+func loadProviderStats() (map[string]*providerStats, error) {
+	stats := make(map[string]*providerStats)
+	data, err := os.ReadFile(statsPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stats, json.Unmarshal(data, &stats)
+}
+
+// saveProviderStats merges the stats recorded by this run into the stats
+// file, dropping days older than the retention period.
+func saveProviderStats() error {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if len(pendingStats) == 0 {
+		return nil
+	}
+	stats, err := loadProviderStats()
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", statsPath(), err)
+	}
+	cutoff := time.Now().UTC().Add(-statsRetention).Format(time.DateOnly)
+	for name, pending := range pendingStats {
+		ps := stats[name]
+		if ps == nil || ps.Days == nil {
+			ps = &providerStats{Days: make(map[string]*dayStats)}
+			stats[name] = ps
+		}
+		for day, d := range pending.Days {
+			if ps.Days[day] == nil {
+				ps.Days[day] = &dayStats{}
+			}
+			ps.Days[day].add(*d)
+		}
+		if pending.LastErrorAt.After(ps.LastErrorAt) {
+			ps.LastError, ps.LastErrorAt = pending.LastError, pending.LastErrorAt
+		}
+		for day := range ps.Days {
+			if day < cutoff {
+				delete(ps.Days, day)
+			}
+		}
+	}
+	pendingStats = make(map[string]*providerStats)
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dataDir(), 0o700); err != nil {
+		return err
+	}
+	tmp := statsPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, statsPath())
+}
+
+// summary totals the last days of a provider's stats.
+func (ps *providerStats) summary(days int) dayStats {
+	var total dayStats
+	cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(time.DateOnly)
+	for day, d := range ps.Days {
+		if day > cutoff {
+			total.add(*d)
+		}
+	}
+	return total
+}
+
+func (d dayStats) successRate() float64 {
+	if d.Successes+d.Failures == 0 {
+		return 0
+	}
+	return float64(d.Successes) / float64(d.Successes+d.Failures) * 100
+}
+
+func (d dayStats) avgLatency() time.Duration {
+	if d.Successes == 0 {
+		return 0
+	}
+	return d.Latency / time.Duration(d.Successes)
+}
+
+// demoteUnreliable moves providers whose success rate over the past week
+// is below demoteBelow percent to the end of the priority order. Providers
+// with fewer than 20 recorded requests are left alone.
+func demoteUnreliable() {
+	if demoteBelow <= 0 {
+		return
+	}
+	stats, err := loadProviderStats()
+	if err != nil {
+		return
+	}
+	unreliable := func(name string) bool {
+		ps := stats[name]
+		if ps == nil {
+			return false
+		}
+		s := ps.summary(7)
+		return s.Successes+s.Failures >= 20 && s.successRate() < demoteBelow
+	}
+	order := append([]string(nil), priorityOrder...)
+	sort.SliceStable(order, func(i, j int) bool {
+		return !unreliable(order[i]) && unreliable(order[j])
+	})
+	priorityOrder = order
+}
+
+type ProviderStatsRow struct {
+	Provider    string        `json:"provider"`
+	Requests    int           `json:"requests"`
+	SuccessRate float64       `json:"success_rate"`
+	AvgLatency  time.Duration `json:"avg_latency_ns"`
+	LastError   string        `json:"last_error,omitempty"`
+	LastErrorAt time.Time     `json:"last_error_at,omitempty"`
+}
+
+var providersStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show recorded provider success rates and latency",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := loadProviderStats()
+		if err != nil {
+			return err
+		}
+		var rows []ProviderStatsRow
+		for _, p := range providers {
+			ps := stats[p.name]
+			if ps == nil {
+				continue
+			}
+			s := ps.summary(statsDays)
+			rows = append(rows, ProviderStatsRow{
+				Provider:    p.label,
+				Requests:    s.Successes + s.Failures,
+				SuccessRate: s.successRate(),
+				AvgLatency:  s.avgLatency(),
+				LastError:   ps.LastError,
+				LastErrorAt: ps.LastErrorAt,
+			})
+		}
+		if outputFormat == "json" {
+			return printJSON(rows)
+		}
+		if len(rows) == 0 {
+			fmt.Println("No provider statistics recorded yet")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "PROVIDER\tREQUESTS (%dd)\tSUCCESS\tAVG LATENCY\tLAST ERROR\n", statsDays)
+		for _, r := range rows {
+			lastError := "-"
+			if r.LastError != "" {
+				lastError = fmt.Sprintf("%s (%s)", r.LastError, r.LastErrorAt.Format("2006-01-02 15:04"))
+			}
+			fmt.Fprintf(w, "%s\t%d\t%.1f%%\t%s\t%s\n", r.Provider, r.Requests, r.SuccessRate, r.AvgLatency.Round(time.Millisecond), lastError)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	providersStatsCmd.Flags().IntVar(&statsDays, "days", 30, "number of days to summarize")
+	providersCmd.AddCommand(providersStatsCmd)
+}
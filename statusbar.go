@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var statusbarMarkup string
+
+// Up and down colors for pango and polybar markup.
+const (
+	statusbarUp   = "#50fa7b"
+	statusbarDown = "#ff5555"
+)
+
+// compactPrice shortens a price for a status bar: $67.2k, $3.51k, $0.1234.
+func compactPrice(price float64, currency string) string {
+	var s string
+	switch abs := math.Abs(price); {
+	case abs >= 1e9:
+		s = strconv.FormatFloat(price/1e9, 'f', 1, 64) + "B"
+	case abs >= 1e6:
+		s = strconv.FormatFloat(price/1e6, 'f', 1, 64) + "M"
+	case abs >= 1e5:
+		s = strconv.FormatFloat(price/1e3, 'f', 0, 64) + "k"
+	case abs >= 1e4:
+		s = strconv.FormatFloat(price/1e3, 'f', 1, 64) + "k"
+	case abs >= 1e3:
+		s = strconv.FormatFloat(price/1e3, 'f', 2, 64) + "k"
+	case abs >= 1:
+		s = strconv.FormatFloat(price, 'f', 2, 64)
+	default:
+		s = strconv.FormatFloat(price, 'f', priceDecimals(price), 64)
+	}
+	if symbol, ok := currencySymbols[currency]; ok {
+		return symbol + s
+	}
+	return s + " " + strings.ToUpper(currency)
+}
+
+// statusbarMarkupFor resolves "auto" to ANSI colors on a terminal and to
+// plain text otherwise, as bars read from a pipe.
+func statusbarMarkupFor() string {
+	if statusbarMarkup == "auto" {
+		if useColor(os.Stdout) {
+			return "ansi"
+		}
+		return "none"
+	}
+	return statusbarMarkup
+}
+
+func colorChangeMarkup(s string, up bool, markup string) string {
+	color := statusbarDown
+	if up {
+		color = statusbarUp
+	}
+	switch markup {
+	case "ansi":
+		return colored(s, up, true)
+	case "pango":
+		return fmt.Sprintf(`<span color="%s">%s</span>`, color, html.EscapeString(s))
+	case "polybar":
+		return "%{F" + color + "}" + s + "%{F-}"
+	}
+	return s
+}
+
+// statusbarLine renders the quotes as one line such as
+// "BTC $67.2k ▲1.4%  ETH $3.51k ▼0.3%". Failed quotes show as "BTC ?".
+func statusbarLine(quotes []CoinQuote, changes map[string]float64) string {
+	markup := statusbarMarkupFor()
+	parts := make([]string, 0, len(quotes))
+	for _, q := range quotes {
+		label := strings.ToUpper(coinSymbol(q.Coin))
+		if markup == "pango" {
+			label = html.EscapeString(label)
+		}
+		if q.err != nil {
+			parts = append(parts, label+" ?")
+			continue
+		}
+		part := label + " " + compactPrice(q.Price, q.Currency)
+		if markup == "pango" {
+			part = label + " " + html.EscapeString(compactPrice(q.Price, q.Currency))
+		}
+		if change, ok := changes[q.Coin+"/"+q.Currency]; ok {
+			arrow := "▲"
+			if change < 0 {
+				arrow = "▼"
+			}
+			part += " " + colorChangeMarkup(fmt.Sprintf("%s%.1f%%", arrow, math.Abs(change)), change >= 0, markup)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// quoteChanges looks up the 24h change of each quoted coin, keyed
+// "coin/currency", unless --change already fetched it. Coins without market
+// data are left out.
+func quoteChanges(ctx context.Context, quotes []CoinQuote) map[string]float64 {
+	changes := make(map[string]float64)
+	byCurrency := make(map[string][]string)
+	for _, q := range quotes {
+		if v, ok := q.Changes["24h"]; ok {
+			changes[q.Coin+"/"+q.Currency] = v
+		} else if q.err == nil {
+			byCurrency[q.Currency] = append(byCurrency[q.Currency], q.Coin)
+		}
+	}
+	for currency, ids := range byCurrency {
+		markets, err := fetchMarketsIn(ctx, ids, currency)
+		if err != nil {
+			continue
+		}
+		for _, m := range markets {
+			changes[m.ID+"/"+currency] = m.PriceChange24h
+		}
+	}
+	return changes
+}
+
+func printStatusbar(ctx context.Context, quotes []CoinQuote) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	fmt.Println(statusbarLine(quotes, quoteChanges(ctx, quotes)))
+	return batchError(quotes)
+}
+
+func checkStatusbarMarkup() error {
+	switch statusbarMarkup {
+	case "auto", "none", "ansi", "pango", "polybar":
+		return nil
+	}
+	return fmt.Errorf("unknown --markup %q (expected auto, none, ansi, pango or polybar)", statusbarMarkup)
+}
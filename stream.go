@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+const maxStreamBackoff = 30 * time.Second
+
+var (
+	streamProvider string
+	streamCurrency string
+)
+
+func streamer(name string) (pricefeed.Streamer, error) {
+	url := settingsFor(name).StreamURL
+	switch name {
+	case "binance":
+		return &pricefeed.BinanceStream{URL: strings.TrimRight(url, "/"), Symbol: coinSymbol, HTTPClient: newHTTPClient(0)}, nil
+	case "coinbase":
+		return &pricefeed.CoinbaseStream{URL: url, Symbol: coinSymbol, HTTPClient: newHTTPClient(0)}, nil
+	}
+	return nil, fmt.Errorf("unknown stream provider %q: use binance or coinbase", name)
+}
+
+// streamTicks keeps s connected until ctx is done, reconnecting with
+// exponential backoff. The backoff starts over once a connection has
+// delivered ticks.
+func streamTicks(ctx context.Context, s pricefeed.Streamer, coins []string, currency string, out chan<- pricefeed.Tick) {
+	defer close(out)
+	for attempt := 0; ; attempt++ {
+		ticks := make(chan pricefeed.Tick)
+		done := make(chan error, 1)
+		go func() {
+			done <- s.Stream(ctx, coins, currency, ticks)
+		}()
+		var err error
+	forward:
+		for {
+			select {
+			case t := <-ticks:
+				attempt = 0
+				out <- t
+			case err = <-done:
+				break forward
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		delay := min(backoffDelay(time.Second, attempt), maxStreamBackoff)
+		fmt.Fprintf(os.Stderr, "%s stream lost (%v), reconnecting in %s\n", s.Name(), err, delay.Round(100*time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+func runStream(ctx context.Context, args []string) error {
+	if offline {
+		return errOffline
+	}
+	s, err := streamer(strings.ToLower(streamProvider))
+	if err != nil {
+		return err
+	}
+	coins, err := resolveCoinList(ctx, args)
+	if err != nil {
+		return err
+	}
+	currency := strings.ToLower(streamCurrency)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticks := make(chan pricefeed.Tick)
+	go streamTicks(ctx, s, coins, currency, ticks)
+
+	// Line-delimited JSON, one tick per line, so the output can be piped.
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	color := useColor(os.Stdout)
+	prev := make(map[string]float64)
+	for t := range ticks {
+		if outputFormat == "json" || outputFormat == "ndjson" {
+			if err := enc.Encode(t); err != nil {
+				return err
+			}
+			continue
+		}
+		change := ""
+		if last := prev[t.Coin]; last > 0 && t.Price != last {
+			change = colorChange(fmt.Sprintf("%+.4f%%", (t.Price-last)/last*100), t.Price-last, color)
+		}
+		prev[t.Coin] = t.Price
+		fmt.Printf("%s  %-6s %s  %s\n", t.Time.Local().Format("15:04:05.000"), strings.ToUpper(coinSymbol(t.Coin)), formatPrice(t.Price, t.Currency), change)
+	}
+	return nil
+}
+
+var streamCmd = &cobra.Command{
+	Use:   "stream <coin...>",
+	Short: "Print real-time price ticks from an exchange WebSocket feed",
+	Long: `Print every price update pushed by the Binance or Coinbase WebSocket feed,
+instead of polling REST endpoints like watch does. A dropped connection is
+retried with exponential backoff. With --output ndjson (or json) each tick
+is printed as one JSON object per line, written as soon as it arrives.`,
+	Example: `  crypto-cli stream btc eth
+  crypto-cli stream btc --provider coinbase -c eur
+  crypto-cli stream btc -o ndjson | jq .price`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStream(cmd.Context(), args)
+	},
+}
+
+func init() {
+	streamCmd.Flags().StringVar(&streamProvider, "provider", "binance", "feed to stream from: binance or coinbase")
+	streamCmd.Flags().StringVarP(&streamCurrency, "vs-currency", "c", "usd", "currency to quote in (usd streams the USDT market on Binance)")
+	streamCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat arguments as CoinGecko coin IDs and skip symbol resolution")
+	rootCmd.AddCommand(streamCmd)
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// crossLookback is how many extra days of history ta fetches to date the
+// last moving average cross.
+const crossLookback = 180
+
+var (
+	taIndicators []string
+	taCurrency   string
+)
+
+type indicatorSpec struct {
+	Kind   string // sma, ema or rsi
+	Period int
+}
+
+func (s indicatorSpec) String() string { return strings.ToUpper(s.Kind) + strconv.Itoa(s.Period) }
+
+type IndicatorValue struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Signal string  `json:"signal"`
+}
+
+// MACross compares the shortest and longest requested moving averages:
+// golden while the short one is above, death while it is below. Since is
+// when the current state began, or zero if it held for the whole history.
+type MACross struct {
+	Short string    `json:"short"`
+	Long  string    `json:"long"`
+	State string    `json:"state"`
+	Since time.Time `json:"since,omitempty"`
+}
+
+type TAReport struct {
+	Coin       string           `json:"coin"`
+	Currency   string           `json:"currency"`
+	Time       time.Time        `json:"time"`
+	Close      float64          `json:"close"`
+	Indicators []IndicatorValue `json:"indicators"`
+	Cross      *MACross         `json:"cross,omitempty"`
+}
+
+func parseIndicators(names []string) ([]indicatorSpec, error) {
+	var specs []indicatorSpec
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		kind := strings.TrimRight(name, "0123456789")
+		period, err := strconv.Atoi(name[len(kind):])
+		if (kind != "sma" && kind != "ema" && kind != "rsi") || err != nil || period < 2 {
+			return nil, errors.New(tr("TAUnknownIndicator", "unknown indicator %q (expected sma, ema or rsi followed by a period of at least 2, e.g. sma50)", name))
+		}
+		specs = append(specs, indicatorSpec{kind, period})
+	}
+	return specs, nil
+}
+
+// smaSeries returns the simple moving average at every point, NaN until
+// there are period values.
+func smaSeries(closes []float64, period int) []float64 {
+	out := make([]float64, len(closes))
+	sum := 0.0
+	for i, c := range closes {
+		sum += c
+		if i >= period {
+			sum -= closes[i-period]
+		}
+		out[i] = math.NaN()
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// emaSeries seeds the exponential moving average with the SMA of the first
+// period values.
+func emaSeries(closes []float64, period int) []float64 {
+	out := smaSeries(closes, period)
+	k := 2 / float64(period+1)
+	for i := period; i < len(closes); i++ {
+		out[i] = closes[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// rsiSeries is Wilder's relative strength index.
+func rsiSeries(closes []float64, period int) []float64 {
+	out := make([]float64, len(closes))
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	if len(closes) <= period {
+		return out
+	}
+	var gain, loss float64
+	for i := 1; i < len(closes); i++ {
+		delta := closes[i] - closes[i-1]
+		up, down := math.Max(delta, 0), math.Max(-delta, 0)
+		if i <= period {
+			gain, loss = gain+up/float64(period), loss+down/float64(period)
+			if i < period {
+				continue
+			}
+		} else {
+			gain = (gain*float64(period-1) + up) / float64(period)
+			loss = (loss*float64(period-1) + down) / float64(period)
+		}
+		out[i] = 100
+		if loss > 0 {
+			out[i] = 100 - 100/(1+gain/loss)
+		}
+	}
+	return out
+}
+
+func indicatorSeries(spec indicatorSpec, closes []float64) []float64 {
+	switch spec.Kind {
+	case "ema":
+		return emaSeries(closes, spec.Period)
+	case "rsi":
+		return rsiSeries(closes, spec.Period)
+	}
+	return smaSeries(closes, spec.Period)
+}
+
+func indicatorSignal(spec indicatorSpec, value, close float64) string {
+	if spec.Kind == "rsi" {
+		switch {
+		case value >= 70:
+			return tr("TAOverbought", "overbought")
+		case value <= 30:
+			return tr("TAOversold", "oversold")
+		}
+		return tr("TANeutral", "neutral")
+	}
+	change := percentChange(value, close)
+	if change >= 0 {
+		return tr("TAPriceAbove", "price %.2f%% above", change)
+	}
+	return tr("TAPriceBelow", "price %.2f%% below", -change)
+}
+
+// findCross dates the current state of short against long by walking back
+// to the last day it was the other way round.
+func findCross(points []PricePoint, short, long []float64) (string, time.Time) {
+	last := len(points) - 1
+	golden := short[last] > long[last]
+	state := "death"
+	if golden {
+		state = "golden"
+	}
+	for i := last; i > 0; i-- {
+		if math.IsNaN(long[i-1]) {
+			break
+		}
+		if short[i-1] > long[i-1] != golden {
+			return state, points[i].Time
+		}
+	}
+	return state, time.Time{}
+}
+
+func analyze(coin, currency string, points []PricePoint, specs []indicatorSpec) (TAReport, error) {
+	closes := make([]float64, len(points))
+	for i, p := range points {
+		closes[i] = p.Price
+	}
+	last := len(points) - 1
+	report := TAReport{Coin: coin, Currency: currency, Time: points[last].Time, Close: closes[last]}
+
+	var short, long *indicatorSpec
+	series := make(map[indicatorSpec][]float64)
+	for i, spec := range specs {
+		s := indicatorSeries(spec, closes)
+		if math.IsNaN(s[last]) {
+			return report, errors.New(tr("TANotEnoughHistory", "%s needs %d days of history, only %d available", spec, spec.Period+1, len(points)))
+		}
+		series[spec] = s
+		report.Indicators = append(report.Indicators, IndicatorValue{spec.String(), s[last], indicatorSignal(spec, s[last], closes[last])})
+		if spec.Kind == "rsi" {
+			continue
+		}
+		if short == nil || spec.Period < short.Period {
+			short = &specs[i]
+		}
+		if long == nil || spec.Period > long.Period {
+			long = &specs[i]
+		}
+	}
+	if short != nil && short.Period < long.Period {
+		state, since := findCross(points, series[*short], series[*long])
+		report.Cross = &MACross{Short: short.String(), Long: long.String(), State: state, Since: since}
+	}
+	return report, nil
+}
+
+func printTAReport(report TAReport) error {
+	color := useColor(os.Stdout)
+	fmt.Print(tr("TAHeading", "%s (%s), daily close %s on %s\n", report.Coin, strings.ToUpper(report.Currency),
+		formatPrice(report.Close, report.Currency), report.Time.Format(time.DateOnly)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("TATableHeader", "INDICATOR\tVALUE\tSIGNAL"))
+	for _, ind := range report.Indicators {
+		value := formatPrice(ind.Value, report.Currency)
+		if strings.HasPrefix(ind.Name, "RSI") {
+			value = strconv.FormatFloat(ind.Value, 'f', 1, 64)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", ind.Name, value, ind.Signal)
+	}
+	w.Flush()
+
+	if c := report.Cross; c != nil {
+		line := tr("TADeathCross", "Death cross: %s below %s", c.Short, c.Long)
+		if c.State == "golden" {
+			line = tr("TAGoldenCross", "Golden cross: %s above %s", c.Short, c.Long)
+		}
+		if c.Since.IsZero() {
+			line += tr("TACrossWholeHistory", " for the whole history fetched")
+		} else {
+			line += tr("TACrossSince", " since %s (%d days)", c.Since.Format(time.DateOnly), int(report.Time.Sub(c.Since).Hours()/24))
+		}
+		fmt.Println(colored(line, c.State == "golden", color))
+	}
+	return nil
+}
+
+var taCmd = &cobra.Command{
+	Use:   "ta <coin>",
+	Short: "Compute moving averages and RSI from a coin's daily history",
+	Long: `Compute technical indicators locally from a coin's daily closing prices:
+smaN (simple moving average), emaN (exponential moving average) and rsiN
+(Wilder's relative strength index) over N days.
+
+With two or more moving averages, ta also reports the golden or death cross
+state of the shortest against the longest and when it last crossed.`,
+	Example: `  crypto-cli ta bitcoin
+  crypto-cli ta ethereum --indicators ema12,ema26,rsi14
+  crypto-cli ta solana --indicators sma20,sma50 -o json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeFirstCoin,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		specs, err := parseIndicators(taIndicators)
+		if err != nil {
+			return err
+		}
+		if len(specs) == 0 {
+			return errors.New(tr("TANoIndicators", "--indicators must name at least one indicator"))
+		}
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		currency := strings.ToLower(taCurrency)
+
+		longest := 0
+		for _, s := range specs {
+			longest = max(longest, s.Period)
+		}
+		// More than 90 days makes CoinGecko return daily points.
+		days := max(longest+crossLookback, 91)
+		to := time.Now()
+		points, err := fetchHistory(cmd.Context(), coin, currency, to.AddDate(0, 0, -days), to)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		points = dailyPoints(points)
+		if len(points) == 0 {
+			return withExitCode(exitAllProvidersFailed, errors.New(tr("NoPriceHistory", "no price history for %s", coin)))
+		}
+		report, err := analyze(coin, currency, points, specs)
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+		return printTAReport(report)
+	},
+}
+
+func init() {
+	taCmd.Flags().StringSliceVar(&taIndicators, "indicators", []string{"sma50", "sma200", "rsi14"}, "indicators to compute, e.g. sma50,ema21,rsi14")
+	taCmd.Flags().StringVarP(&taCurrency, "vs-currency", "c", "usd", "currency to compute in")
+	taCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the argument as a CoinGecko coin ID and skip symbol resolution")
+	rootCmd.AddCommand(taCmd)
+}
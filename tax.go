@@ -0,0 +1,471 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taxTransactions string
+	taxMethod       string
+	taxYear         int
+	taxCurrency     string
+)
+
+// taxTx is one row of the transactions file. Price is per coin and Fee is
+// the total fee, both in the report currency.
+type taxTx struct {
+	Time   time.Time
+	Kind   string
+	Coin   string
+	Amount pricefeed.Decimal
+	Price  pricefeed.Decimal
+	Fee    pricefeed.Decimal
+	line   int
+}
+
+// taxLot is what is left of a buy. Cost is per coin and includes the
+// buy's fee.
+type taxLot struct {
+	Acquired time.Time
+	Amount   pricefeed.Decimal
+	Cost     pricefeed.Decimal
+}
+
+// Disposal is the part of a sell matched against one lot. The unexported
+// fields hold the exact values the report sums.
+type Disposal struct {
+	Coin      string    `json:"coin"`
+	Acquired  time.Time `json:"acquired"`
+	Sold      time.Time `json:"sold"`
+	Amount    float64   `json:"amount"`
+	Proceeds  float64   `json:"proceeds"`
+	CostBasis float64   `json:"cost_basis"`
+	Gain      float64   `json:"gain"`
+	LongTerm  bool      `json:"long_term"`
+
+	amount, proceeds, costBasis pricefeed.Decimal
+}
+
+type AssetGains struct {
+	Coin       string  `json:"coin"`
+	Sold       float64 `json:"sold"`
+	Proceeds   float64 `json:"proceeds"`
+	CostBasis  float64 `json:"cost_basis"`
+	Realized   float64 `json:"realized"`
+	Held       float64 `json:"held"`
+	HeldCost   float64 `json:"held_cost_basis"`
+	Price      float64 `json:"price,omitempty"`
+	Value      float64 `json:"value,omitempty"`
+	Unrealized float64 `json:"unrealized,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type TaxReport struct {
+	Method     string       `json:"method"`
+	Currency   string       `json:"currency"`
+	Year       int          `json:"year,omitempty"`
+	Assets     []AssetGains `json:"assets"`
+	Disposals  []Disposal   `json:"disposals"`
+	Realized   float64      `json:"realized"`
+	Unrealized float64      `json:"unrealized"`
+}
+
+// readTransactions reads a CSV file with a header row naming at least the
+// date, type, coin and amount columns, and optionally price (per coin),
+// total (instead of price) and fee. Types are buy or sell.
+func readTransactions(r io.Reader) ([]taxTx, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	header, err := reader.Read()
+	if err != nil {
+		return nil, trError("TaxReadingHeader", "reading header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"date", "type", "coin", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.New(tr("TaxMissingColumn", "missing %q column (need date, type, coin and amount; price, total and fee are optional)", name))
+		}
+	}
+
+	var txs []taxTx
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		number := func(name string) (pricefeed.Decimal, error) {
+			s := field(name)
+			if s == "" {
+				return pricefeed.Decimal{}, nil
+			}
+			v, err := pricefeed.ParseDecimal(s)
+			if err != nil || v.Sign() < 0 {
+				return pricefeed.Decimal{}, errors.New(tr("TaxInvalidNumber", "line %d: invalid %s %q", line, name, s))
+			}
+			return v, nil
+		}
+
+		tx := taxTx{Kind: strings.ToLower(field("type")), Coin: field("coin"), line: line}
+		if tx.Kind != "buy" && tx.Kind != "sell" {
+			return nil, errors.New(tr("TaxUnknownType", "line %d: unknown type %q (expected buy or sell)", line, field("type")))
+		}
+		if tx.Time, err = parseAt(field("date")); err != nil {
+			return nil, trError("LineError", "line %d: %w", line, err)
+		}
+		var total pricefeed.Decimal
+		if tx.Amount, err = number("amount"); err == nil {
+			if tx.Price, err = number("price"); err == nil {
+				if total, err = number("total"); err == nil {
+					tx.Fee, err = number("fee")
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if tx.Amount.Sign() == 0 {
+			return nil, errors.New(tr("TaxAmountNotPositive", "line %d: amount must be positive", line))
+		}
+		if tx.Price.Sign() == 0 && total.Sign() > 0 {
+			tx.Price = total.Quo(tx.Amount)
+		}
+		txs = append(txs, tx)
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Time.Before(txs[j].Time) })
+	return txs, nil
+}
+
+// priceTransactions resolves each coin and fills in missing prices with
+// the historical price nearest to the transaction.
+func priceTransactions(ctx context.Context, txs []taxTx, currency string) error {
+	for i := range txs {
+		id, err := resolveCoin(ctx, txs[i].Coin, exactID)
+		if err != nil {
+			return trError("LineError", "line %d: %w", txs[i].line, err)
+		}
+		txs[i].Coin = id
+		if txs[i].Price.Sign() > 0 {
+			continue
+		}
+		q := quoteCoinAt(ctx, id, currency, txs[i].Time)
+		if q.err != nil {
+			return trError("TaxNoPrice", "line %d: no price given and %w", txs[i].line, q.err)
+		}
+		txs[i].Price = pricefeed.NewDecimal(q.Price)
+	}
+	return nil
+}
+
+// matchLots sells amount from the coin's lots, oldest first for fifo and
+// newest first for lifo, and returns the disposals and the lots left.
+func matchLots(lots []taxLot, tx taxTx, method string) ([]Disposal, []taxLot, error) {
+	var held pricefeed.Decimal
+	for _, l := range lots {
+		held = held.Add(l.Amount)
+	}
+	// Allow for rounding in amounts copied from exchange exports.
+	if tx.Amount.Cmp(held.Mul(pricefeed.NewDecimal(1+1e-9))) > 0 {
+		return nil, nil, errors.New(tr("TaxOversold", "line %d: selling %s %s on %s but only %s held", tx.line,
+			formatAmount(tx.Amount, false), tx.Coin, tx.Time.Format(time.DateOnly), formatAmount(held, false)))
+	}
+	// Proceeds per coin after the sell's fee.
+	proceeds := tx.Price.Sub(tx.Fee.Quo(tx.Amount))
+	var disposals []Disposal
+	for remaining := tx.Amount; remaining.Sign() > 0 && len(lots) > 0; {
+		i := 0
+		if method == "lifo" {
+			i = len(lots) - 1
+		}
+		lot := &lots[i]
+		amount := remaining
+		if lot.Amount.Cmp(amount) < 0 {
+			amount = lot.Amount
+		}
+		sold, cost := amount.Mul(proceeds), amount.Mul(lot.Cost)
+		disposals = append(disposals, Disposal{
+			Coin:      tx.Coin,
+			Acquired:  lot.Acquired,
+			Sold:      tx.Time,
+			Amount:    amount.Float64(),
+			Proceeds:  sold.Float64(),
+			CostBasis: cost.Float64(),
+			Gain:      sold.Sub(cost).Float64(),
+			LongTerm:  tx.Time.After(lot.Acquired.AddDate(1, 0, 0)),
+			amount:    amount,
+			proceeds:  sold,
+			costBasis: cost,
+		})
+		remaining = remaining.Sub(amount)
+		lot.Amount = lot.Amount.Sub(amount)
+		if lot.Amount.Sign() <= 0 {
+			lots = append(lots[:i], lots[i+1:]...)
+		}
+	}
+	return disposals, lots, nil
+}
+
+func computeGains(txs []taxTx, method string, year int) (map[string][]taxLot, []Disposal, error) {
+	lots := make(map[string][]taxLot)
+	var disposals []Disposal
+	for _, tx := range txs {
+		if tx.Kind == "buy" {
+			lots[tx.Coin] = append(lots[tx.Coin], taxLot{tx.Time, tx.Amount, tx.Price.Add(tx.Fee.Quo(tx.Amount))})
+			continue
+		}
+		sold, left, err := matchLots(lots[tx.Coin], tx, method)
+		if err != nil {
+			return nil, nil, err
+		}
+		lots[tx.Coin] = left
+		if year == 0 || tx.Time.Year() == year {
+			disposals = append(disposals, sold...)
+		}
+	}
+	return lots, disposals, nil
+}
+
+func buildTaxReport(cmd *cobra.Command, txs []taxTx, currency string) (TaxReport, error) {
+	report := TaxReport{Method: taxMethod, Currency: currency, Year: taxYear}
+	lots, disposals, err := computeGains(txs, taxMethod, taxYear)
+	if err != nil {
+		return report, err
+	}
+	report.Disposals = disposals
+	if report.Disposals == nil {
+		report.Disposals = []Disposal{}
+	}
+
+	var coins []string
+	assets := make(map[string]*AssetGains)
+	asset := func(coin string) *AssetGains {
+		if a, ok := assets[coin]; ok {
+			return a
+		}
+		coins = append(coins, coin)
+		assets[coin] = &AssetGains{Coin: coin}
+		return assets[coin]
+	}
+	for _, tx := range txs {
+		asset(tx.Coin)
+	}
+	type sums struct{ sold, proceeds, costBasis, realized, held, heldCost pricefeed.Decimal }
+	totals := make(map[string]*sums)
+	for _, coin := range coins {
+		totals[coin] = &sums{}
+	}
+	var realized, unrealized pricefeed.Decimal
+	for _, d := range disposals {
+		t := totals[d.Coin]
+		gain := d.proceeds.Sub(d.costBasis)
+		t.sold = t.sold.Add(d.amount)
+		t.proceeds = t.proceeds.Add(d.proceeds)
+		t.costBasis = t.costBasis.Add(d.costBasis)
+		t.realized = t.realized.Add(gain)
+		realized = realized.Add(gain)
+	}
+	var held []string
+	for _, coin := range coins {
+		a, t := assets[coin], totals[coin]
+		for _, l := range lots[coin] {
+			t.held = t.held.Add(l.Amount)
+			t.heldCost = t.heldCost.Add(l.Amount.Mul(l.Cost))
+		}
+		a.Sold, a.Proceeds, a.CostBasis, a.Realized = t.sold.Float64(), t.proceeds.Float64(), t.costBasis.Float64(), t.realized.Float64()
+		a.Held, a.HeldCost = t.held.Float64(), t.heldCost.Float64()
+		if t.held.Sign() > 0 {
+			held = append(held, coin)
+		}
+	}
+	report.Realized = realized.Float64()
+
+	// Unrealized gains are always at today's price, whatever --year says.
+	if len(held) > 0 {
+		for _, q := range quoteCoins(cmd.Context(), held, []string{currency}) {
+			a := assets[q.Coin]
+			if q.err != nil {
+				a.Error = q.err.Error()
+				continue
+			}
+			t := totals[q.Coin]
+			value := t.held.Mul(pricefeed.NewDecimal(q.Price))
+			a.Price, a.Value = q.Price, value.Float64()
+			a.Unrealized = value.Sub(t.heldCost).Float64()
+			unrealized = unrealized.Add(value.Sub(t.heldCost))
+		}
+	}
+	report.Unrealized = unrealized.Float64()
+	for _, coin := range coins {
+		report.Assets = append(report.Assets, *assets[coin])
+	}
+	return report, nil
+}
+
+func printTaxTable(report TaxReport) error {
+	color := useColor(os.Stdout)
+	period := tr("TaxAllYears", "all years")
+	if report.Year != 0 {
+		period = strconv.Itoa(report.Year)
+	}
+	fmt.Print(tr("TaxHeading", "Gains in %s, %s lot matching, realized in %s\n", strings.ToUpper(report.Currency), strings.ToUpper(report.Method), period))
+	gain := func(v float64) string {
+		return colorChange(signedPrice(v, report.Currency), v, color)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, paintHeader(tr("TaxTableHeader", "COIN\tSOLD\tPROCEEDS\tCOST BASIS\tREALIZED\tHELD\tVALUE\tUNREALIZED"), color, 4, 7))
+	for _, a := range report.Assets {
+		value, unrealized := "-", paint("-", "39", color)
+		switch {
+		case a.Error != "":
+			value = tr("TableError", "error: %v", a.Error)
+		case a.Held > 0:
+			value, unrealized = formatPrice(a.Value, report.Currency), gain(a.Unrealized)
+		}
+		realized := paint("-", "39", color)
+		if a.Sold > 0 {
+			realized = gain(a.Realized)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.Coin, strconv.FormatFloat(a.Sold, 'f', -1, 64),
+			formatPrice(a.Proceeds, report.Currency), formatPrice(a.CostBasis, report.Currency), realized,
+			strconv.FormatFloat(a.Held, 'f', -1, 64), value, unrealized)
+	}
+	fmt.Fprintf(w, "%s\t\t\t\t%s\t\t\t%s\n", tr("TableTotal", "TOTAL"), gain(report.Realized), gain(report.Unrealized))
+	w.Flush()
+
+	var short, long pricefeed.Decimal
+	for _, d := range report.Disposals {
+		if d.LongTerm {
+			long = long.Add(d.proceeds.Sub(d.costBasis))
+		} else {
+			short = short.Add(d.proceeds.Sub(d.costBasis))
+		}
+	}
+	if len(report.Disposals) > 0 {
+		fmt.Println(dim(tr("TaxTermSplit", "Realized: %s short term (held a year or less), %s long term",
+			signedPrice(short.Float64(), report.Currency), signedPrice(long.Float64(), report.Currency)), color))
+	}
+	return nil
+}
+
+// printDisposalsCSV writes one row per lot sold, the level of detail an
+// accountant needs to fill in a capital gains schedule.
+func printDisposalsCSV(report TaxReport) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"coin", "amount", "acquired", "sold", "proceeds", "cost_basis", "gain", "term", "currency"})
+	for _, d := range report.Disposals {
+		term := "short"
+		if d.LongTerm {
+			term = "long"
+		}
+		w.Write([]string{
+			d.Coin,
+			strconv.FormatFloat(d.Amount, 'f', -1, 64),
+			d.Acquired.Format(time.DateOnly),
+			d.Sold.Format(time.DateOnly),
+			strconv.FormatFloat(d.Proceeds, 'f', 2, 64),
+			strconv.FormatFloat(d.CostBasis, 'f', 2, 64),
+			strconv.FormatFloat(d.Gain, 'f', 2, 64),
+			term,
+			report.Currency,
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+var taxCmd = &cobra.Command{
+	Use:   "tax",
+	Short: "Report realized and unrealized gains from a transactions CSV",
+	Long: `Read buys and sells from a CSV file, match each sell against earlier buys
+with FIFO or LIFO lot accounting, and report realized gains per asset, plus
+unrealized gains on what is still held at today's price.
+
+The file needs a header row with date, type (buy or sell), coin and amount
+columns. price is the price per coin and total the whole amount paid or
+received, both in --vs-currency; rows with neither are priced from
+CoinGecko's history at the transaction time. fee, in --vs-currency, is added
+to the cost of a buy and taken off the proceeds of a sell.
+
+  date,type,coin,amount,price,fee
+  2023-01-15,buy,bitcoin,0.5,21000,10
+  2024-03-01 14:00,sell,btc,0.2,,5
+
+-o csv lists every lot sold with its acquisition date, proceeds, cost basis
+and whether it was held for more than a year. This is arithmetic, not tax
+advice: check the rules that apply to you.`,
+	Example: `  crypto-cli tax --transactions trades.csv
+  crypto-cli tax --transactions trades.csv --method lifo --year 2024
+  crypto-cli tax --transactions trades.csv --year 2024 -o csv > gains-2024.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taxMethod = strings.ToLower(taxMethod)
+		if taxMethod != "fifo" && taxMethod != "lifo" {
+			return errors.New(tr("TaxUnknownMethod", "unknown --method %q (expected fifo or lifo)", taxMethod))
+		}
+		f, err := os.Open(taxTransactions)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		txs, err := readTransactions(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", taxTransactions, err)
+		}
+		if len(txs) == 0 {
+			return errors.New(tr("TaxNoTransactions", "%s: no transactions", taxTransactions))
+		}
+		currency := strings.ToLower(taxCurrency)
+		if err := priceTransactions(cmd.Context(), txs, currency); err != nil {
+			return fmt.Errorf("%s: %w", taxTransactions, err)
+		}
+		report, err := buildTaxReport(cmd, txs, currency)
+		if err != nil {
+			return fmt.Errorf("%s: %w", taxTransactions, err)
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(report)
+		case "csv":
+			return printDisposalsCSV(report)
+		}
+		return printTaxTable(report)
+	},
+}
+
+func init() {
+	taxCmd.Flags().StringVar(&taxTransactions, "transactions", "", "CSV file of buys and sells")
+	taxCmd.Flags().StringVar(&taxMethod, "method", "fifo", "which lots a sell uses up first: fifo (oldest) or lifo (newest)")
+	taxCmd.Flags().IntVar(&taxYear, "year", 0, "only count sells in this year as realized (lots are still matched from the start)")
+	taxCmd.Flags().StringVarP(&taxCurrency, "vs-currency", "c", "usd", "currency of the prices and fees in the file, and of the report")
+	taxCmd.Flags().BoolVar(&exactID, "exact-id", false, "treat coins as CoinGecko coin IDs and skip symbol resolution")
+	taxCmd.MarkFlagRequired("transactions")
+	rootCmd.AddCommand(taxCmd)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"cli-crypto-price/pricefeed"
+)
+
+func TestComputeGains(t *testing.T) {
+	d := pricefeed.NewDecimal
+	day := func(s string) time.Time {
+		t, _ := time.Parse(time.DateOnly, s)
+		return t
+	}
+	buy := func(date string, amount, price, fee float64) taxTx {
+		return taxTx{Time: day(date), Kind: "buy", Coin: "bitcoin", Amount: d(amount), Price: d(price), Fee: d(fee)}
+	}
+	sell := func(date string, amount, price, fee float64) taxTx {
+		return taxTx{Time: day(date), Kind: "sell", Coin: "bitcoin", Amount: d(amount), Price: d(price), Fee: d(fee)}
+	}
+	lots := []taxTx{buy("2023-01-01", 1, 100, 0), buy("2024-01-01", 1, 200, 0)}
+	tests := []struct {
+		name     string
+		txs      []taxTx
+		method   string
+		year     int
+		gains    []float64
+		longTerm []bool
+		held     float64
+		heldCost float64
+		err      string
+	}{
+		{
+			name:   "fifo sells the oldest lot first",
+			txs:    append(lots, sell("2024-06-01", 1.5, 300, 0)),
+			method: "fifo",
+			gains:  []float64{200, 50}, longTerm: []bool{true, false},
+			held: 0.5, heldCost: 100,
+		},
+		{
+			name:   "lifo sells the newest lot first",
+			txs:    append(lots, sell("2024-06-01", 1.5, 300, 0)),
+			method: "lifo",
+			gains:  []float64{100, 100}, longTerm: []bool{false, true},
+			held: 0.5, heldCost: 50,
+		},
+		{
+			name:   "fees raise the cost and cut the proceeds",
+			txs:    []taxTx{buy("2024-01-01", 2, 100, 10), sell("2024-02-01", 1, 150, 5)},
+			method: "fifo",
+			gains:  []float64{40}, longTerm: []bool{false},
+			held: 1, heldCost: 105,
+		},
+		{
+			name:   "amounts split without float error",
+			txs:    []taxTx{buy("2024-01-01", 1, 10, 0), sell("2024-02-01", 0.9, 10, 0)},
+			method: "fifo",
+			gains:  []float64{0}, longTerm: []bool{false},
+			held: 0.1, heldCost: 1,
+		},
+		{
+			name:   "year filter keeps matching earlier sells",
+			txs:    append(lots, sell("2023-06-01", 0.5, 300, 0), sell("2024-06-01", 1, 300, 0)),
+			method: "fifo",
+			year:   2024,
+			gains:  []float64{100, 50}, longTerm: []bool{true, false},
+			held: 0.5, heldCost: 100,
+		},
+		{
+			name:   "selling more than held",
+			txs:    []taxTx{buy("2024-01-01", 1, 100, 0), sell("2024-02-01", 2, 100, 0)},
+			method: "fifo",
+			err:    "selling 2 bitcoin on 2024-02-01 but only 1 held",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, disposals, err := computeGains(tt.txs, tt.method, tt.year)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(disposals) != len(tt.gains) {
+				t.Fatalf("%d disposals, want %d: %+v", len(disposals), len(tt.gains), disposals)
+			}
+			for i, dsp := range disposals {
+				if math.Abs(dsp.Gain-tt.gains[i]) > 1e-9 || dsp.LongTerm != tt.longTerm[i] {
+					t.Errorf("disposal %d: gain %v, long term %v; want %v, %v", i, dsp.Gain, dsp.LongTerm, tt.gains[i], tt.longTerm[i])
+				}
+			}
+			var held, cost pricefeed.Decimal
+			for _, l := range left["bitcoin"] {
+				held = held.Add(l.Amount)
+				cost = cost.Add(l.Amount.Mul(l.Cost))
+			}
+			if held.Cmp(d(tt.held)) != 0 || cost.Cmp(d(tt.heldCost)) != 0 {
+				t.Errorf("held %s at a cost of %s, want %v at %v", held, cost, tt.held, tt.heldCost)
+			}
+		})
+	}
+}
+
+func TestReadTransactions(t *testing.T) {
+	tests := []struct {
+		name  string
+		csv   string
+		price string
+		err   string
+	}{
+		{"price column", "date,type,coin,amount,price\n2024-01-01,buy,btc,0.5,40000\n", "40000", ""},
+		{"total instead of price", "date,type,coin,amount,total\n2024-01-01,buy,btc,0.5,20000\n", "40000", ""},
+		{"no price", "Date,Type,Coin,Amount\n2024-01-01,SELL,btc,1\n", "0", ""},
+		{"missing column", "date,type,amount\n", "", `missing "coin" column`},
+		{"unknown type", "date,type,coin,amount\n2024-01-01,swap,btc,1\n", "", `line 2: unknown type "swap"`},
+		{"negative amount", "date,type,coin,amount\n2024-01-01,buy,btc,-1\n", "", `line 2: invalid amount "-1"`},
+		{"zero amount", "date,type,coin,amount\n2024-01-01,buy,btc,0\n", "", "line 2: amount must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, err := readTransactions(strings.NewReader(tt.csv))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(txs) != 1 || txs[0].Price.String() != tt.price {
+				t.Errorf("transactions = %+v, want one priced at %s", txs, tt.price)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	tokenomistBaseURL   = "https://api.tokenomist.ai"
+	tokenomistUnlockAPI = "/v2/unlock/events?tokenId=%s&start=%s"
+)
+
+type UnlockEvent struct {
+	Date        time.Time `json:"date"`
+	Amount      float64   `json:"amount"`
+	Value       float64   `json:"value_usd,omitempty"`
+	SupplyPct   float64   `json:"circulating_supply_pct,omitempty"`
+	Allocations []string  `json:"allocations,omitempty"`
+}
+
+type unlockResponse struct {
+	Data []struct {
+		UnlockDate   time.Time `json:"unlockDate"`
+		CliffUnlocks struct {
+			CliffAmount         float64 `json:"cliffAmount"`
+			CliffValue          float64 `json:"cliffValue"`
+			AllocationBreakdown []struct {
+				AllocationName string `json:"allocationName"`
+			} `json:"allocationBreakdown"`
+		} `json:"cliffUnlocks"`
+	} `json:"data"`
+}
+
+func fetchUnlocks(ctx context.Context, coin string) ([]UnlockEvent, error) {
+	if providerKey("tokenomist") == "" {
+		return nil, fmt.Errorf("tokenomist: no API key configured")
+	}
+	start := time.Now().UTC().Format("2006-01-02")
+	var resp unlockResponse
+	if err := getJSON(ctx, providerURL("tokenomist")+fmt.Sprintf(tokenomistUnlockAPI, url.QueryEscape(coin), start), "tokenomist", &resp); err != nil {
+		return nil, err
+	}
+
+	var events []UnlockEvent
+	for _, d := range resp.Data {
+		if d.CliffUnlocks.CliffAmount == 0 {
+			continue
+		}
+		e := UnlockEvent{Date: d.UnlockDate, Amount: d.CliffUnlocks.CliffAmount, Value: d.CliffUnlocks.CliffValue}
+		for _, a := range d.CliffUnlocks.AllocationBreakdown {
+			e.Allocations = append(e.Allocations, a.AllocationName)
+		}
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return events, nil
+}
+
+var unlocksCmd = &cobra.Command{
+	Use:   "unlocks <coin>",
+	Short: "List upcoming token unlocks relative to circulating supply",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coin, err := resolveCoin(cmd.Context(), args[0], exactID)
+		if err != nil {
+			return err
+		}
+		events, err := fetchUnlocks(cmd.Context(), coin)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+
+		var supply float64
+		if markets, err := fetchMarkets(cmd.Context(), []string{coin}); err == nil && len(markets) > 0 {
+			supply = markets[0].CirculatingSupply
+		}
+		for i := range events {
+			if supply > 0 {
+				events[i].SupplyPct = events[i].Amount / supply * 100
+			}
+		}
+		if outputFormat == "json" {
+			return printJSON(events)
+		}
+		if len(events) == 0 {
+			fmt.Printf("No upcoming unlocks for %s\n", coin)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "DATE\tAMOUNT\tVALUE\tSUPPLY\tALLOCATION\n")
+		for _, e := range events {
+			pct := "-"
+			if e.SupplyPct > 0 {
+				pct = fmt.Sprintf("%.2f%%", e.SupplyPct)
+			}
+			fmt.Fprintf(w, "%s\t%.0f\t$%.0f\t%s\t%s\n", e.Date.Format("2006-01-02"), e.Amount, e.Value, pct, joinOrDash(e.Allocations))
+		}
+		return w.Flush()
+	},
+}
+
+func joinOrDash(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	return strings.Join(items, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(unlocksCmd)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	ensideasBaseURL  = "https://api.ensideas.com"
+	ethplorerBaseURL = "https://api.ethplorer.io"
+
+	ensResolveAPI        = "/ens/resolve/%s"
+	ethplorerAddressAPI  = "/getAddressInfo/%s?apiKey=%s"
+	coingeckoContractAPI = "/coins/ethereum/contract/%s"
+)
+
+type Holding struct {
+	Coin     string  `json:"coin,omitempty"`
+	Symbol   string  `json:"symbol"`
+	Contract string  `json:"contract,omitempty"`
+	Amount   float64 `json:"amount"`
+	Price    float64 `json:"price,omitempty"`
+	Value    float64 `json:"value,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type Valuation struct {
+	Address  string    `json:"address"`
+	Name     string    `json:"name,omitempty"`
+	Total    float64   `json:"total"`
+	Holdings []Holding `json:"holdings"`
+}
+
+func resolveENS(ctx context.Context, name string) (string, error) {
+	var resp struct {
+		Address string `json:"address"`
+		Error   string `json:"error"`
+	}
+	if err := getJSON(ctx, providerURL("ensideas")+fmt.Sprintf(ensResolveAPI, name), "ensideas", &resp); err != nil {
+		return "", err
+	}
+	if resp.Address == "" {
+		return "", fmt.Errorf("ens: %s does not resolve to an address", name)
+	}
+	return resp.Address, nil
+}
+
+// ethplorerKey is the configured key, or Ethplorer's shared free one.
+func ethplorerKey() string {
+	if key := providerKey("ethplorer"); key != "" {
+		return key
+	}
+	return "freekey"
+}
+
+// fetchHoldings lists the ETH balance and every token Ethplorer knows a
+// price for; unpriced tokens are mostly spam airdrops and are skipped.
+func fetchHoldings(ctx context.Context, address string) ([]Holding, error) {
+	var resp struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		ETH struct {
+			Balance float64 `json:"balance"`
+		} `json:"ETH"`
+		Tokens []struct {
+			Balance   float64 `json:"balance"`
+			TokenInfo struct {
+				Address  string      `json:"address"`
+				Symbol   string      `json:"symbol"`
+				Decimals string      `json:"decimals"`
+				Price    interface{} `json:"price"`
+			} `json:"tokenInfo"`
+		} `json:"tokens"`
+	}
+	if err := getJSON(ctx, providerURL("ethplorer")+fmt.Sprintf(ethplorerAddressAPI, address, ethplorerKey()), "ethplorer", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("ethplorer: %s", resp.Error.Message)
+	}
+
+	var holdings []Holding
+	if resp.ETH.Balance > 0 {
+		holdings = append(holdings, Holding{Coin: "ethereum", Symbol: "ETH", Amount: resp.ETH.Balance})
+	}
+	for _, t := range resp.Tokens {
+		if _, priced := t.TokenInfo.Price.(map[string]interface{}); !priced {
+			continue
+		}
+		decimals, _ := strconv.Atoi(t.TokenInfo.Decimals)
+		holdings = append(holdings, Holding{
+			Symbol:   t.TokenInfo.Symbol,
+			Contract: t.TokenInfo.Address,
+			Amount:   t.Balance / math.Pow10(decimals),
+		})
+	}
+	return holdings, nil
+}
+
+// contractCoin maps an ERC-20 contract address to its CoinGecko coin ID.
+func contractCoin(ctx context.Context, contract string) (string, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := getJSON(ctx, providerURL("coingecko")+fmt.Sprintf(coingeckoContractAPI, contract), "coingecko", &resp); err != nil {
+		return "", err
+	}
+	if resp.ID == "" {
+		return "", fmt.Errorf("coingecko: unknown contract %s", contract)
+	}
+	return resp.ID, nil
+}
+
+func valueHoldings(ctx context.Context, holdings []Holding) {
+	var wg sync.WaitGroup
+	for i := range holdings {
+		wg.Add(1)
+		go func(h *Holding) {
+			defer wg.Done()
+			if h.Coin == "" {
+				id, err := contractCoin(ctx, h.Contract)
+				if err != nil {
+					h.Error = err.Error()
+					return
+				}
+				h.Coin = id
+			}
+			q := quoteCoin(ctx, h.Coin, "usd")
+			if q.err != nil {
+				h.Error = q.err.Error()
+				return
+			}
+			h.Price = q.Price
+			h.Value = h.Amount * q.Price
+		}(&holdings[i])
+	}
+	wg.Wait()
+}
+
+var valueCmd = &cobra.Command{
+	Use:   "value <address|ens-name>",
+	Short: "Value the token holdings of an Ethereum address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		v := Valuation{Address: args[0]}
+		if strings.HasSuffix(strings.ToLower(v.Address), ".eth") {
+			address, err := resolveENS(cmd.Context(), v.Address)
+			if err != nil {
+				return err
+			}
+			v.Name, v.Address = v.Address, address
+		}
+		if !strings.HasPrefix(v.Address, "0x") || len(v.Address) != 42 {
+			return errors.New(tr("InvalidAddress", "invalid address %q", v.Address))
+		}
+
+		holdings, err := fetchHoldings(cmd.Context(), v.Address)
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+		valueHoldings(cmd.Context(), holdings)
+		sort.SliceStable(holdings, func(i, j int) bool { return holdings[i].Value > holdings[j].Value })
+		for _, h := range holdings {
+			v.Total += h.Value
+		}
+		v.Holdings = holdings
+		if outputFormat == "json" {
+			return printJSON(v)
+		}
+
+		if v.Name != "" {
+			fmt.Printf("%s (%s)\n", v.Name, v.Address)
+		} else {
+			fmt.Println(v.Address)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, tr("ValueTableHeader", "ASSET\tAMOUNT\tPRICE\tVALUE\tSHARE"))
+		for _, h := range holdings {
+			if h.Error != "" {
+				fmt.Fprintf(w, "%s\t%.6g\t-\t-\t%s\n", h.Symbol, h.Amount, tr("TableError", "error: %v", h.Error))
+				continue
+			}
+			share := 0.0
+			if v.Total > 0 {
+				share = h.Value / v.Total * 100
+			}
+			fmt.Fprintf(w, "%s\t%.6g\t%s\t$%.2f\t%.1f%%\n", h.Symbol, h.Amount, formatPrice(h.Price, "usd"), h.Value, share)
+		}
+		fmt.Fprintf(w, "%s\t\t\t$%.2f\t\n", tr("ValueTotal", "Total"), v.Total)
+		return w.Flush()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(valueCmd)
+}
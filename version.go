@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const latestReleaseAPI = "https://api.github.com/repos/evgeniykapelko/cli-crypto-price/releases/latest"
+
+// Set at build time with
+// -ldflags "-X main.version=v1.2.3 -X main.commit=abc123 -X main.date=2024-01-01".
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+var checkUpdate bool
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func buildVersion() string {
+	if version == "dev" {
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			return info.Main.Version
+		}
+	}
+	return version
+}
+
+// buildSetting returns the value set by ldflags, or else the VCS stamp Go
+// embeds in builds from a checkout, such as vcs.revision or vcs.time.
+func buildSetting(value, key string) string {
+	if value != "none" && value != "unknown" {
+		return value
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == key && s.Value != "" {
+				return s.Value
+			}
+		}
+	}
+	return value
+}
+
+func latestRelease(ctx context.Context) (githubRelease, error) {
+	var release githubRelease
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, latestReleaseAPI, nil)
+	if err != nil {
+		return release, err
+	}
+	resp, err := newHTTPClient(10 * time.Second).Do(req)
+	if err != nil {
+		return release, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return release, fmt.Errorf("release check failed: %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&release)
+	return release, err
+}
+
+// compareVersions compares two vMAJOR.MINOR.PATCH strings, ignoring any
+// pre-release suffix; it returns -1, 0 or 1.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range pa {
+		if pa[i] != pb[i] {
+			if pa[i] < pb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(v string) [3]int {
+	var parts [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	for i, field := range strings.SplitN(v, ".", 3) {
+		parts[i], _ = strconv.Atoi(field)
+	}
+	return parts
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		current := buildVersion()
+		info := struct {
+			Version   string `json:"version"`
+			Commit    string `json:"commit"`
+			Date      string `json:"date"`
+			GoVersion string `json:"go_version"`
+			Platform  string `json:"platform"`
+			Latest    string `json:"latest,omitempty"`
+			UpdateURL string `json:"update_url,omitempty"`
+		}{current, buildSetting(commit, "vcs.revision"), buildSetting(date, "vcs.time"), runtime.Version(), runtime.GOOS + "/" + runtime.GOARCH, "", ""}
+
+		var release githubRelease
+		if checkUpdate {
+			var err error
+			if release, err = latestRelease(cmd.Context()); err != nil {
+				return fmt.Errorf("checking for updates: %w", err)
+			}
+			info.Latest = release.TagName
+			if current == "dev" || compareVersions(current, release.TagName) < 0 {
+				info.UpdateURL = release.HTMLURL
+			}
+		}
+		if outputFormat == "json" {
+			return printJSON(info)
+		}
+
+		fmt.Printf("crypto-cli %s (commit %s, built %s, %s %s)\n", info.Version, info.Commit, info.Date, info.GoVersion, info.Platform)
+		if !checkUpdate {
+			return nil
+		}
+		if info.UpdateURL != "" {
+			fmt.Printf("A newer version is available: %s (%s)\n", release.TagName, release.HTMLURL)
+		} else {
+			fmt.Println("You are running the latest version")
+		}
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&checkUpdate, "check-update", false, "Check GitHub releases for a newer version")
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.Version = buildVersion()
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const minWatchInterval = time.Second
+
+var (
+	watchInterval     time.Duration
+	watchNotify       bool
+	watchNotifyChange float64
+)
+
+// reportMoves finds the coins that have moved by --notify-change percent
+// since they were last reported, or first seen, shows a desktop
+// notification for each with --notify and sounds the alarm for --bell or
+// --sound. After a failed notification it warns once and stops trying,
+// rather than breaking up the table on every refresh.
+func reportMoves(quotes []CoinQuote, baselines map[string]priceBaseline) {
+	moved := false
+	for _, q := range quotes {
+		key := q.Coin + "/" + q.Currency
+		if q.err != nil || q.Price <= 0 {
+			continue
+		}
+		base, ok := baselines[key]
+		if !ok {
+			baselines[key] = priceBaseline{q.Price, time.Now()}
+			continue
+		}
+		change := percentChange(base.price, q.Price)
+		if math.Abs(change) < watchNotifyChange {
+			continue
+		}
+		baselines[key] = priceBaseline{q.Price, time.Now()}
+		moved = true
+		if !watchNotify {
+			continue
+		}
+		body := fmt.Sprintf("%s %s (%+.2f%% since %s)", q.Coin, formatPrice(q.Price, q.Currency), change, base.time.Format("15:04"))
+		if err := desktopNotify("crypto-cli: "+q.Coin, body); err != nil {
+			fmt.Fprint(os.Stderr, tr("DesktopNotifyDisabled", "Warning: desktop notification failed, no more will be sent: %v\n", err))
+			watchNotify = false
+		}
+	}
+	if moved {
+		alarm(false)
+	}
+}
+
+// watchTick renders one refresh of the watch table. prev holds the last
+// good price per coin and currency so each row can show the move since
+// the previous refresh.
+func watchTick(quotes []CoinQuote, prev map[string]float64, color bool) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, tr("WatchTableHeader", "COIN\tPRICE\tCHANGE\tSOURCE"))
+	for _, q := range quotes {
+		key := q.Coin + "/" + q.Currency
+		if q.err != nil {
+			fmt.Fprintf(w, "%s\t-\t\t%s\n", q.Coin, tr("TableError", "error: %v", q.err))
+			continue
+		}
+		change := ""
+		if last, ok := prev[key]; ok && last > 0 {
+			change = colorChange(fmt.Sprintf("%+.2f%%", (q.Price-last)/last*100), q.Price-last, color)
+		}
+		prev[key] = q.Price
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", q.Coin, formatPrice(q.Price, q.Currency), change, q.Source)
+	}
+	w.Flush()
+	fmt.Fprint(&b, tr("WatchFooter", "Updated %s, every %s (Ctrl-C to quit)\n", time.Now().Format("15:04:05"), watchInterval))
+	return b.String()
+}
+
+func colorChange(label string, delta float64, color bool) string {
+	switch {
+	case !color || delta == 0:
+		return label
+	case delta > 0:
+		return "\033[32m" + label + "\033[0m"
+	}
+	return "\033[31m" + label + "\033[0m"
+}
+
+// runWatch refreshes the quotes every interval until interrupted, and at
+// once after SIGHUP reloads the config. On a
+// terminal the table is redrawn in place; otherwise, or with --accessible,
+// each refresh is printed below the previous one. With --output ndjson
+// each quote is printed as a JSON line instead.
+func runWatch(ctx context.Context, cmd *cobra.Command, coins []string) error {
+	ids, err := resolveCoinList(ctx, coins)
+	if err != nil {
+		return err
+	}
+	allowPrompt = false
+	noCache = true
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := hangups(ctx)
+
+	lines := outputFormat == "ndjson"
+	inPlace := !lines && useColor(os.Stdout)
+	if inPlace {
+		fmt.Print("\033[?25l")
+		defer fmt.Print("\033[?25h")
+	}
+
+	prev := make(map[string]float64)
+	baselines := make(map[string]priceBaseline)
+	drawn := 0
+	for {
+		quotes := quoteCoins(ctx, ids, vsCurrencies)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if watchNotify || bell || soundFile != "" {
+			reportMoves(quotes, baselines)
+		}
+		if lines {
+			if err := printJSONLines(quotes); err != nil {
+				return err
+			}
+		} else {
+			out := watchTick(quotes, prev, inPlace)
+			if inPlace && drawn > 0 {
+				fmt.Printf("\033[%dA\033[J", drawn)
+			} else if drawn > 0 {
+				fmt.Println()
+			}
+			fmt.Print(out)
+			drawn = strings.Count(out, "\n")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			logReload(cmd)
+		case <-time.After(watchInterval):
+		}
+	}
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <coin...>",
+	Short: "Keep refreshing prices in place until interrupted",
+	Example: `  crypto-cli watch btc eth -i 30s
+  crypto-cli watch btc --notify --notify-change 2   # desktop notification on every 2% move
+  crypto-cli watch btc eth --bell --notify-change 3  # ring the terminal bell on every 3% move
+  crypto-cli watch btc eth -o ndjson | jq -c '{coin, price}'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if watchInterval < minWatchInterval {
+			return errors.New(tr("IntervalTooShort", "--interval must be at least %s", minWatchInterval))
+		}
+		if watchNotifyChange <= 0 {
+			return fmt.Errorf("--notify-change must be positive")
+		}
+		if err := checkSound(); err != nil {
+			return err
+		}
+		return runWatch(cmd.Context(), cmd, args)
+	},
+}
+
+func init() {
+	watchCmd.Flags().DurationVarP(&watchInterval, "interval", "i", 10*time.Second, "time between refreshes")
+	watchCmd.Flags().BoolVar(&watchNotify, "notify", false, "show a desktop notification when a coin moves by --notify-change (notify-send on Linux, osascript on macOS, a toast on Windows)")
+	watchCmd.Flags().Float64Var(&watchNotifyChange, "notify-change", 1, "percentage move since the last --notify, --bell or --sound that triggers the next one")
+	watchCmd.Flags().BoolVar(&bell, "bell", false, "ring the terminal bell when a coin moves by --notify-change")
+	watchCmd.Flags().StringVar(&soundFile, "sound", "", "play this sound file when a coin moves by --notify-change (afplay on macOS, paplay, pw-play, aplay or ffplay on Linux, WAV on Windows)")
+	rootCmd.AddCommand(watchCmd)
+}
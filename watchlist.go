@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var watchlistForce bool
+
+var watchlistNameRE = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// watchlistsPath is watchlists.yaml in the config dir. Watchlists are shared
+// by all profiles.
+func watchlistsPath() string {
+	return filepath.Join(configDir(), "watchlists.yaml")
+}
+
+// loadWatchlists reads the watchlists file, mapping each name to its coin
+// IDs. A missing file means no watchlists.
+func loadWatchlists() (map[string][]string, error) {
+	lists := make(map[string][]string)
+	data, err := os.ReadFile(watchlistsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return lists, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &lists); err != nil {
+		return nil, trError("ReadingFile", "reading %s: %w", watchlistsPath(), err)
+	}
+	return lists, nil
+}
+
+// allWatchlists is the watchlists file overlaid with any lists under
+// "watchlists" in the config, where the active profile's lists replace
+// those of the same name. Lists from the config are read-only.
+func allWatchlists() (map[string][]string, error) {
+	lists, err := loadWatchlists()
+	if err != nil {
+		return nil, err
+	}
+	for name, coins := range config.GetStringMapStringSlice("watchlists") {
+		lists[strings.ToLower(name)] = coins
+	}
+	return lists, nil
+}
+
+func saveWatchlists(lists map[string][]string) error {
+	data, err := yaml.Marshal(lists)
+	if err != nil {
+		return err
+	}
+	path := watchlistsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func watchlistName(arg string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(arg, "@"))
+	if !watchlistNameRE.MatchString(name) {
+		return "", errors.New(tr("WatchlistInvalidName", "invalid watchlist name %q: use letters, digits, - and _", arg))
+	}
+	return name, nil
+}
+
+// watchlist returns the coins of the watchlist named by an @name argument.
+func watchlist(arg string) ([]string, error) {
+	name, err := watchlistName(arg)
+	if err != nil {
+		return nil, err
+	}
+	lists, err := allWatchlists()
+	if err != nil {
+		return nil, err
+	}
+	coins, ok := lists[name]
+	if !ok {
+		return nil, withExitCode(exitCoinNotFound, errors.New(tr("WatchlistUnknownSee", "no watchlist %q (see %q)", name, "crypto-cli watchlist list")))
+	}
+	return coins, nil
+}
+
+// expandWatchlists replaces each @name argument with the coins of that
+// watchlist, leaving other arguments as they are.
+func expandWatchlists(args []string) ([]string, error) {
+	var out []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "@") {
+			out = append(out, arg)
+			continue
+		}
+		coins, err := watchlist(arg)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, coins...)
+	}
+	return out, nil
+}
+
+// resolveCoinList expands watchlists and resolves every coin, keeping the
+// first of any duplicates.
+func resolveCoinList(ctx context.Context, args []string) ([]string, error) {
+	expanded, err := expandWatchlists(args)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(expanded))
+	for _, arg := range expanded {
+		id, err := resolveCoin(ctx, arg, exactID)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+var watchlistCmd = &cobra.Command{
+	Use:     "watchlist",
+	Aliases: []string{"watchlists"},
+	Short:   "Manage named lists of coins, used as @name",
+	Long: `Save named lists of coins and use them as @name wherever coins are
+accepted, e.g. "crypto-cli @defi" or "crypto-cli watch @defi @l1". Commands
+that take a single coin accept a watchlist of one coin.
+
+Watchlists are kept in watchlists.yaml in the config dir, shared by all
+profiles. Coins are resolved to CoinGecko IDs when they are added. Lists
+can also be written under "watchlists" in the config file, including in a
+profile, where they take precedence; those are edited in the config file
+rather than with these commands.`,
+	Example: `  crypto-cli watchlist create defi aave uni comp
+  crypto-cli watchlist show defi
+  crypto-cli @defi -c eur`,
+}
+
+var watchlistCreateCmd = &cobra.Command{
+	Use:   "create <name> <coin...>",
+	Short: "Create a watchlist",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := watchlistName(args[0])
+		if err != nil {
+			return err
+		}
+		lists, err := loadWatchlists()
+		if err != nil {
+			return err
+		}
+		if _, ok := lists[name]; ok && !watchlistForce {
+			return errors.New(tr("WatchlistExists", "watchlist %q already exists (use --force to replace it)", name))
+		}
+		coins, err := resolveCoinList(cmd.Context(), args[1:])
+		if err != nil {
+			return err
+		}
+		lists[name] = coins
+		if err := saveWatchlists(lists); err != nil {
+			return err
+		}
+		fmt.Print(tr("WatchlistCreated", "Created @%s: %s\n", name, strings.Join(coins, ", ")))
+		return nil
+	},
+}
+
+var watchlistAddCmd = &cobra.Command{
+	Use:   "add <name> <coin...>",
+	Short: "Add coins to a watchlist, creating it if needed",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := watchlistName(args[0])
+		if err != nil {
+			return err
+		}
+		lists, err := loadWatchlists()
+		if err != nil {
+			return err
+		}
+		coins, err := resolveCoinList(cmd.Context(), args[1:])
+		if err != nil {
+			return err
+		}
+		for _, coin := range coins {
+			if !slices.Contains(lists[name], coin) {
+				lists[name] = append(lists[name], coin)
+			}
+		}
+		if err := saveWatchlists(lists); err != nil {
+			return err
+		}
+		fmt.Printf("@%s: %s\n", name, strings.Join(lists[name], ", "))
+		return nil
+	},
+}
+
+var watchlistRemoveCmd = &cobra.Command{
+	Use:   "remove <name> <coin...>",
+	Short: "Remove coins from a watchlist",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := watchlistName(args[0])
+		if err != nil {
+			return err
+		}
+		lists, err := loadWatchlists()
+		if err != nil {
+			return err
+		}
+		coins, ok := lists[name]
+		if !ok {
+			return errors.New(tr("WatchlistUnknown", "no watchlist %q", name))
+		}
+		for _, arg := range args[1:] {
+			// Match the stored ID first, so coins that have since been
+			// delisted can still be removed.
+			id := strings.ToLower(resolveAlias(arg))
+			if !slices.Contains(coins, id) {
+				if id, err = resolveCoin(cmd.Context(), arg, exactID); err != nil {
+					return err
+				}
+			}
+			i := slices.Index(coins, id)
+			if i < 0 {
+				return errors.New(tr("WatchlistMissingCoin", "%s is not in @%s", arg, name))
+			}
+			coins = slices.Delete(coins, i, i+1)
+		}
+		lists[name] = coins
+		if err := saveWatchlists(lists); err != nil {
+			return err
+		}
+		fmt.Printf("@%s: %s\n", name, strings.Join(coins, ", "))
+		return nil
+	},
+}
+
+var watchlistDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a watchlist",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, err := watchlistName(args[0])
+		if err != nil {
+			return err
+		}
+		lists, err := loadWatchlists()
+		if err != nil {
+			return err
+		}
+		if _, ok := lists[name]; !ok {
+			return errors.New(tr("WatchlistUnknown", "no watchlist %q", name))
+		}
+		delete(lists, name)
+		return saveWatchlists(lists)
+	},
+}
+
+var watchlistShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print the coins of a watchlist, one per line",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coins, err := watchlist(args[0])
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			return printJSON(coins)
+		}
+		for _, coin := range coins {
+			fmt.Println(coin)
+		}
+		return nil
+	},
+}
+
+var watchlistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the watchlists",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lists, err := allWatchlists()
+		if err != nil {
+			return err
+		}
+		if outputFormat == "json" {
+			return printJSON(lists)
+		}
+		names := make([]string, 0, len(lists))
+		for name := range lists {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("@%s: %s\n", name, strings.Join(lists[name], ", "))
+		}
+		return nil
+	},
+}
+
+// completeWatchlists suggests watchlist names for the watchlist
+// subcommands' first argument, and coins after it.
+func completeWatchlists(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		if cmd.Args == nil || cmd.Args(cmd, append(args, "")) != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeCoins(cmd, args, toComplete)
+	}
+	lists, _ := allWatchlists()
+	var names []string
+	for name, coins := range lists {
+		if strings.HasPrefix(name, strings.ToLower(toComplete)) {
+			names = append(names, name+"\t"+strings.Join(coins, ", "))
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	watchlistCreateCmd.Flags().BoolVar(&watchlistForce, "force", false, "replace an existing watchlist")
+	for _, cmd := range []*cobra.Command{watchlistCreateCmd, watchlistAddCmd, watchlistRemoveCmd} {
+		cmd.Flags().BoolVar(&exactID, "exact-id", false, "treat the coins as CoinGecko coin IDs and skip symbol resolution")
+	}
+	for _, cmd := range []*cobra.Command{watchlistCreateCmd, watchlistAddCmd, watchlistRemoveCmd, watchlistDeleteCmd, watchlistShowCmd} {
+		cmd.ValidArgsFunction = completeWatchlists
+		watchlistCmd.AddCommand(cmd)
+	}
+	watchlistCmd.AddCommand(watchlistListCmd)
+	rootCmd.AddCommand(watchlistCmd)
+}
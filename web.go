@@ -0,0 +1,138 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed web/dashboard.html
+var webFS embed.FS
+
+var dashboardPage = template.Must(template.ParseFS(webFS, "web/dashboard.html"))
+
+var (
+	serveDashboardCoins    []string
+	serveDashboardCurrency string
+	serveDashboardRefresh  time.Duration
+
+	// dashboardCoins is --dashboard resolved to coin IDs when serve starts.
+	dashboardCoins []string
+)
+
+// handleDashboard serves the dashboard page, which polls /prices, /history
+// and /health. ?ids= and ?vs= in its URL override the coins and currency
+// given to serve.
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := dashboardPage.Execute(w, struct {
+		Coins     []string `json:"coins"`
+		Currency  string   `json:"currency"`
+		RefreshMS int64    `json:"refresh_ms"`
+	}{dashboardCoins, strings.ToLower(serveDashboardCurrency), serveDashboardRefresh.Milliseconds()})
+	if err != nil {
+		log.Printf("Rendering the dashboard: %v", err)
+	}
+}
+
+var (
+	historyMu   sync.Mutex
+	historyConn *sql.DB
+)
+
+// historyDB opens the recorded prices read-only on first use, once record
+// has created the database, and keeps them open for later requests.
+func historyDB() (*sql.DB, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if historyConn != nil {
+		return historyConn, nil
+	}
+	if _, err := os.Stat(recordPath()); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+recordPath()+"?mode=ro&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	historyConn = db
+	return db, nil
+}
+
+// handleHistory answers with the coin's prices recorded over the last
+// ?hours= (24 by default), oldest first. The database is only read, and
+// no recordings at all means an empty list.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	currencies, ok := queryCurrencies(w, r)
+	if !ok {
+		return
+	}
+	currency := currencies[0]
+	hours := 24
+	if s := r.URL.Query().Get("hours"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			writeJSON(w, http.StatusBadRequest, apiError{Error: "hours must be a positive number"})
+			return
+		}
+		hours = n
+	}
+	coin, err := resolveCoin(r.Context(), r.PathValue("coin"), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	db, err := historyDB()
+	if errors.Is(err, os.ErrNotExist) {
+		writeJSON(w, http.StatusOK, []PricePoint{})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	now := time.Now()
+	records, err := queryRecords(db, []string{coin}, now.Add(-time.Duration(hours)*time.Hour), now)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: err.Error()})
+		return
+	}
+	points := []PricePoint{}
+	for _, rec := range records {
+		if rec.Currency == currency {
+			points = append(points, PricePoint{Time: rec.Time, Price: rec.Price})
+		}
+	}
+	writeJSON(w, http.StatusOK, points)
+}
+
+// handleHealth answers with each active provider's requests, success rate
+// and latency since the server started.
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	rows := []ProviderStatsRow{}
+	for _, p := range activeProviders() {
+		row := ProviderStatsRow{Provider: p.label}
+		if ps := pendingStats[p.name]; ps != nil {
+			var total dayStats
+			for _, d := range ps.Days {
+				total.add(*d)
+			}
+			row.Requests = total.Successes + total.Failures
+			row.SuccessRate = total.successRate()
+			row.AvgLatency = total.avgLatency()
+			row.LastError, row.LastErrorAt = ps.LastError, ps.LastErrorAt
+		}
+		rows = append(rows, row)
+	}
+	writeJSON(w, http.StatusOK, rows)
+}
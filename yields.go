@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	defillamaYieldsBaseURL = "https://yields.llama.fi"
+
+	defillamaPoolsAPI = "/pools"
+)
+
+var yieldsCurrency string
+
+// stakingPool is a liquid staking token as DefiLlama lists it: the project
+// slug and the token's symbol.
+type stakingPool struct {
+	project string
+	symbol  string
+}
+
+// stakingPools are the liquid staking tokens whose APY stands for staking
+// each coin. Their yield tracks native staking less the protocol's fee.
+var stakingPools = map[string][]stakingPool{
+	"ethereum": {
+		{"lido", "STETH"},
+		{"rocket-pool", "RETH"},
+		{"coinbase-wrapped-staked-eth", "CBETH"},
+		{"frax-ether", "SFRXETH"},
+	},
+	"solana": {
+		{"jito-liquid-staking", "JITOSOL"},
+		{"marinade-liquid-staking", "MSOL"},
+		{"binance-staked-sol", "BNSOL"},
+	},
+	"cosmos":             {{"stride", "STATOM"}},
+	"osmosis":            {{"stride", "STOSMO"}},
+	"celestia":           {{"stride", "STTIA"}},
+	"polkadot":           {{"bifrost-liquid-staking", "VDOT"}},
+	"avalanche-2":        {{"benqi-staked-avax", "SAVAX"}},
+	"binancecoin":        {{"binance-staked-bnb", "WBETH"}},
+	"matic-network":      {{"lido", "STMATIC"}},
+	"near":               {{"linear-protocol", "LINEAR"}},
+	"aptos":              {{"amnis-finance", "STAPT"}},
+	"sui":                {{"haedal-protocol", "HASUI"}},
+	"injective-protocol": {{"stride", "STINJ"}},
+}
+
+// StakingYield is one liquid staking token's current APY.
+type StakingYield struct {
+	Project string  `json:"project"`
+	Token   string  `json:"token"`
+	APY     float64 `json:"apy"`
+	TVL     float64 `json:"tvl_usd"`
+}
+
+// CoinYields is a coin's price with the staking yields available for it,
+// highest TVL first.
+type CoinYields struct {
+	Coin     string         `json:"coin"`
+	Symbol   string         `json:"symbol"`
+	Price    float64        `json:"price,omitempty"`
+	Currency string         `json:"currency"`
+	Yields   []StakingYield `json:"yields"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// fetchStakingYields reads DefiLlama's pool list once and picks out the
+// known staking token pools, keyed by coin. Tokens bridged to other chains
+// appear more than once; the largest pool is kept.
+func fetchStakingYields(ctx context.Context) (map[string][]StakingYield, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Data   []struct {
+			Project string  `json:"project"`
+			Symbol  string  `json:"symbol"`
+			APY     float64 `json:"apy"`
+			TVL     float64 `json:"tvlUsd"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, providerURL("defillama-yields")+defillamaPoolsAPI, "defillama-yields", &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("defillama: status %q", resp.Status)
+	}
+
+	coins := make(map[stakingPool]string)
+	for coin, pools := range stakingPools {
+		for _, pool := range pools {
+			coins[pool] = coin
+		}
+	}
+	best := make(map[stakingPool]StakingYield)
+	for _, d := range resp.Data {
+		pool := stakingPool{d.Project, strings.ToUpper(d.Symbol)}
+		if _, ok := coins[pool]; !ok || d.TVL <= best[pool].TVL {
+			continue
+		}
+		best[pool] = StakingYield{Project: d.Project, Token: pool.symbol, APY: d.APY, TVL: d.TVL}
+	}
+	yields := make(map[string][]StakingYield)
+	for pool, y := range best {
+		yields[coins[pool]] = append(yields[coins[pool]], y)
+	}
+	for _, ys := range yields {
+		sort.Slice(ys, func(i, j int) bool { return ys[i].TVL > ys[j].TVL })
+	}
+	return yields, nil
+}
+
+func printCoinYields(results []CoinYields) {
+	color := useColor(os.Stdout)
+	for i, r := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		if r.Error != "" {
+			fmt.Printf("%s: %s\n", r.Symbol, paint(r.Error, "31", color))
+			continue
+		}
+		fmt.Printf("%s %s\n", r.Symbol, formatPrice(r.Price, r.Currency))
+		if len(r.Yields) == 0 {
+			fmt.Println(dim("  No staking yields found", color))
+			continue
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  PROJECT\tTOKEN\tAPY\tTVL")
+		for _, y := range r.Yields {
+			fmt.Fprintf(w, "  %s\t%s\t%.2f%%\t%s\n", y.Project, y.Token, y.APY, formatMarketValue(y.TVL, "usd"))
+		}
+		w.Flush()
+	}
+}
+
+var yieldsCmd = &cobra.Command{
+	Use:   "yields <coin...>",
+	Short: "Compare staking APYs for coins alongside their price",
+	Long: `Show the current staking yield for each coin, estimated from the APY of
+its liquid staking tokens on DefiLlama (e.g. Lido's stETH for Ethereum),
+alongside the coin's price. Liquid staking APYs are native staking
+rewards less the protocol's fee.`,
+	Example: `  crypto-cli yields eth sol atom
+  crypto-cli yields ethereum -o json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		coins, err := resolveCoinList(cmd.Context(), args)
+		if err != nil {
+			return err
+		}
+		if offline {
+			return errOffline
+		}
+		yields, err := fetchStakingYields(cmd.Context())
+		if err != nil {
+			return withExitCode(exitAllProvidersFailed, err)
+		}
+
+		currency := strings.ToLower(yieldsCurrency)
+		quotes := quoteCoins(cmd.Context(), coins, []string{currency})
+		results := make([]CoinYields, 0, len(quotes))
+		for _, q := range quotes {
+			r := CoinYields{Coin: q.Coin, Symbol: coinSymbol(q.Coin), Price: q.Price, Currency: currency, Yields: yields[q.Coin], Error: q.Error}
+			if r.Yields == nil {
+				r.Yields = []StakingYield{}
+			}
+			results = append(results, r)
+		}
+		if outputFormat == "json" {
+			if err := printJSON(results); err != nil {
+				return err
+			}
+		} else {
+			printCoinYields(results)
+		}
+		return batchError(quotes)
+	},
+}
+
+func init() {
+	yieldsCmd.Flags().StringVarP(&yieldsCurrency, "vs-currency", "c", "usd", "currency to show prices in")
+	rootCmd.AddCommand(yieldsCmd)
+}